@@ -0,0 +1,292 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// axNodeID is the part of an accessibility node needed to locate its DOM
+// node.
+type axNodeID struct {
+	Ignored          bool `json:"ignored"`
+	BackendDOMNodeID int  `json:"backendDOMNodeId"`
+}
+
+// domElements converts DOM nodes identified by their backend node ids into
+// elements. Each element remembers an absolute xpath of its node so that it
+// can be relocated.
+func (s *Session) domElements(backendIDs []int) ([]*Element, error) {
+	const collect = "__webdriverNodes"
+	for _, id := range backendIDs {
+		obj := struct {
+			Object struct {
+				ObjectID string `json:"objectId"`
+			} `json:"object"`
+		}{}
+		if err := s.cdp("DOM.resolveNode", map[string]interface{}{"backendNodeId": id}, &obj); err != nil {
+			return nil, err
+		}
+		if err := s.cdp("Runtime.callFunctionOn", map[string]interface{}{
+			"objectId":            obj.Object.ObjectID,
+			"functionDeclaration": "function() { (window." + collect + " = window." + collect + " || []).push(this); }",
+		}, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := s.ExecuteScriptRaw(`var nodes = window.`+collect+` || [];
+delete window.`+collect+`;
+`+xpathFunc+`
+return nodes.filter(function(n) {
+	return n.nodeType === Node.ELEMENT_NODE;
+}).map(function(n) {
+	return [n, xpath(n)];
+});`, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.decodeElementPaths(data)
+}
+
+// xpathFunc is a script function computing an absolute xpath of a node.
+const xpathFunc = `function xpath(n) {
+	var parts = [];
+	for (; n && n.nodeType === Node.ELEMENT_NODE; n = n.parentNode) {
+		var i = 1;
+		for (var sib = n.previousElementSibling; sib; sib = sib.previousElementSibling) {
+			if (sib.tagName === n.tagName) {
+				i++;
+			}
+		}
+		parts.unshift("*[name()='" + n.tagName.toLowerCase() + "'][" + i + "]");
+	}
+	return "/" + parts.join("/");
+}`
+
+// decodeElementPaths decodes a script result of [element, xpath] pairs into
+// elements that can be relocated by their xpath.
+func (s *Session) decodeElementPaths(data []byte) ([]*Element, error) {
+	reply := struct {
+		Value [][2]json.RawMessage
+	}{}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return nil, err
+	}
+	elems := make([]*Element, 0, len(reply.Value))
+	for _, pair := range reply.Value {
+		we, err := s.DecodeElement(rawValue(pair[0]))
+		if err != nil {
+			return nil, err
+		}
+		var xpath string
+		if err := json.Unmarshal(pair[1], &xpath); err != nil {
+			return nil, err
+		}
+		elems = append(elems, &Element{s: s, WebElement: we, xpath: xpath})
+	}
+	return elems, nil
+}
+
+// FindByRole returns the elements with the ARIA role, e.g. "button" or
+// "textbox", and accessible name, as computed by the browser's accessibility
+// tree. An empty name matches any name. Unlike class names, roles and names
+// survive the restyling of component libraries.
+func (s *Session) FindByRole(role, name string) ([]*Element, error) {
+	doc := struct {
+		Root struct {
+			NodeID int `json:"nodeId"`
+		} `json:"root"`
+	}{}
+	if err := s.cdp("DOM.getDocument", map[string]interface{}{"depth": 0}, &doc); err != nil {
+		return nil, err
+	}
+
+	params := map[string]interface{}{
+		"nodeId": doc.Root.NodeID,
+		"role":   role,
+	}
+	if name != "" {
+		params["accessibleName"] = name
+	}
+	reply := struct {
+		Nodes []axNodeID `json:"nodes"`
+	}{}
+	if err := s.cdp("Accessibility.queryAXTree", params, &reply); err != nil {
+		return nil, fmt.Errorf("error querying accessibility tree: %v", err)
+	}
+
+	var ids []int
+	for _, n := range reply.Nodes {
+		if !n.Ignored && n.BackendDOMNodeID != 0 {
+			ids = append(ids, n.BackendDOMNodeID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, ErrNotFound
+	}
+	return s.domElements(ids)
+}
+
+// GetByRole waits for an element with the ARIA role and accessible name, see
+// FindByRole, and returns the first one in document order.
+func (s *Session) GetByRole(role, name string) (*Element, error) {
+	var ret *Element
+	err := s.waitOn(func() (bool, error) {
+		elems, err := s.FindByRole(role, name)
+		if err == ErrNotFound || IsRetryable(err) {
+			return false, retryWith(err)
+		} else if err != nil {
+			return true, err
+		} else if len(elems) == 0 {
+			return false, nil
+		}
+
+		ret = elems[0]
+		return true, nil
+	}, s.timeout)
+
+	return ret, s.fail("GetByRole", role+" "+name, err)
+}
+
+// AXNode is a node of the accessibility tree.
+type AXNode struct {
+	Role        string
+	Name        string
+	Value       string
+	Description string
+	// Ignored nodes are not exposed to assistive technologies, e.g.
+	// generic containers. Their children are.
+	Ignored bool
+	// Properties holds the states and properties of the node, e.g.
+	// "focusable", "checked" or "level".
+	Properties map[string]interface{}
+	Children   []*AXNode
+
+	s         *Session
+	backendID int
+}
+
+type axValue struct {
+	Value interface{} `json:"value"`
+}
+
+func (v *axValue) String() string {
+	if v == nil || v.Value == nil {
+		return ""
+	}
+	return fmt.Sprint(v.Value)
+}
+
+// AccessibilityTree returns the accessibility tree of the page as computed by
+// the browser, rooted at the document.
+func (s *Session) AccessibilityTree() (*AXNode, error) {
+	reply := struct {
+		Nodes []struct {
+			NodeID           string   `json:"nodeId"`
+			ParentID         string   `json:"parentId"`
+			ChildIDs         []string `json:"childIds"`
+			Ignored          bool     `json:"ignored"`
+			Role             *axValue `json:"role"`
+			Name             *axValue `json:"name"`
+			Value            *axValue `json:"value"`
+			Description      *axValue `json:"description"`
+			BackendDOMNodeID int      `json:"backendDOMNodeId"`
+			Properties       []struct {
+				Name  string  `json:"name"`
+				Value axValue `json:"value"`
+			} `json:"properties"`
+		} `json:"nodes"`
+	}{}
+	if err := s.cdp("Accessibility.getFullAXTree", nil, &reply); err != nil {
+		return nil, err
+	}
+	if len(reply.Nodes) == 0 {
+		return nil, fmt.Errorf("empty accessibility tree")
+	}
+
+	nodes := map[string]*AXNode{}
+	for _, n := range reply.Nodes {
+		node := &AXNode{
+			Role:        n.Role.String(),
+			Name:        n.Name.String(),
+			Value:       n.Value.String(),
+			Description: n.Description.String(),
+			Ignored:     n.Ignored,
+			s:           s,
+			backendID:   n.BackendDOMNodeID,
+		}
+		if len(n.Properties) > 0 {
+			node.Properties = map[string]interface{}{}
+			for _, p := range n.Properties {
+				node.Properties[p.Name] = p.Value.Value
+			}
+		}
+		nodes[n.NodeID] = node
+	}
+
+	var root *AXNode
+	for _, n := range reply.Nodes {
+		node := nodes[n.NodeID]
+		for _, id := range n.ChildIDs {
+			if child := nodes[id]; child != nil {
+				node.Children = append(node.Children, child)
+			}
+		}
+		if root == nil && (n.ParentID == "" || nodes[n.ParentID] == nil) {
+			root = node
+		}
+	}
+	return root, nil
+}
+
+// Walk calls fn for the node and its descendants in depth-first order, and
+// stops descending into a node when fn returns false.
+func (n *AXNode) Walk(fn func(n *AXNode) bool) {
+	if !fn(n) {
+		return
+	}
+	for _, c := range n.Children {
+		c.Walk(fn)
+	}
+}
+
+// Element returns the element the node was computed from.
+func (n *AXNode) Element() (*Element, error) {
+	if n.backendID == 0 {
+		return nil, ErrNotFound
+	}
+	elems, err := n.s.domElements([]int{n.backendID})
+	if err != nil {
+		return nil, err
+	}
+	if len(elems) == 0 {
+		return nil, ErrNotFound
+	}
+	return elems[0], nil
+}
+
+// String formats the tree rooted at the node, one node per line, leaving out
+// the ignored nodes.
+func (n *AXNode) String() string {
+	var b strings.Builder
+	n.format(&b, 0)
+	return b.String()
+}
+
+func (n *AXNode) format(b *strings.Builder, depth int) {
+	if !n.Ignored {
+		fmt.Fprintf(b, "%s%s", strings.Repeat("  ", depth), n.Role)
+		if n.Name != "" {
+			fmt.Fprintf(b, " %q", n.Name)
+		}
+		if n.Value != "" {
+			fmt.Fprintf(b, " value=%q", n.Value)
+		}
+		b.WriteString("\n")
+		depth++
+	}
+	for _, c := range n.Children {
+		c.format(b, depth)
+	}
+}
@@ -0,0 +1,26 @@
+package webdriver
+
+import "testing"
+
+func TestAXNodeString(t *testing.T) {
+	root := &AXNode{Role: "RootWebArea", Name: "Home", Children: []*AXNode{
+		{Role: "generic", Ignored: true, Children: []*AXNode{
+			{Role: "button", Name: "Sign in"},
+			{Role: "textbox", Name: "Email", Value: "a@b.c"},
+		}},
+	}}
+
+	want := "RootWebArea \"Home\"\n  button \"Sign in\"\n  textbox \"Email\" value=\"a@b.c\"\n"
+	if got := root.String(); got != want {
+		t.Errorf("String() =\n%s\nwant\n%s", got, want)
+	}
+
+	var roles []string
+	root.Walk(func(n *AXNode) bool {
+		roles = append(roles, n.Role)
+		return true
+	})
+	if len(roles) != 4 {
+		t.Errorf("Walk() visited %v, want 4 nodes", roles)
+	}
+}
@@ -0,0 +1,159 @@
+package webdriver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AppiumOptions selects the device of an Appium session, see WithAppium.
+type AppiumOptions struct {
+	// DeviceName is the name of the device, e.g. "Pixel 7" or "emulator-5554".
+	// It defaults to "Android".
+	DeviceName string
+	// UDID is the serial of the device as listed by `adb devices`. It is
+	// required when several devices are connected.
+	UDID string
+	// PlatformVersion is the Android version of the device, e.g. "14".
+	PlatformVersion string
+	// ChromedriverExecutable is the chromedriver matching the version of
+	// Chrome on the device, if Appium should not download one.
+	ChromedriverExecutable string
+	// Capabilities holds additional capabilities, e.g.
+	// "appium:newCommandTimeout". Keys without a vendor prefix are prefixed
+	// with "appium:".
+	Capabilities map[string]interface{}
+}
+
+// WithAppium configures the device of a session created by an AppiumDriver
+// server. Without it the session runs Chrome on the only connected Android
+// device.
+func WithAppium(opts AppiumOptions) Option {
+	return func(c *sessionConfig) {
+		c.appium = &opts
+	}
+}
+
+// appiumCapabilities returns the capabilities of a mobile Chrome session.
+func appiumCapabilities(cfg *sessionConfig) (Capabilities, error) {
+	if cfg.headless {
+		return nil, fmt.Errorf("appium does not support headless mode")
+	}
+	if cfg.profile != "" {
+		return nil, fmt.Errorf("appium does not support custom profiles")
+	}
+	if cfg.chromeBinary != "" {
+		return nil, fmt.Errorf("appium does not support a chrome binary")
+	}
+
+	opts := cfg.appium
+	if opts == nil {
+		opts = &AppiumOptions{}
+	}
+	deviceName := opts.DeviceName
+	if deviceName == "" {
+		deviceName = "Android"
+	}
+
+	caps := Capabilities{
+		"platformName":          "Android",
+		"browserName":           "Chrome",
+		"appium:automationName": "UiAutomator2",
+		"appium:deviceName":     deviceName,
+	}
+	if opts.UDID != "" {
+		caps["appium:udid"] = opts.UDID
+	}
+	if opts.PlatformVersion != "" {
+		caps["appium:platformVersion"] = opts.PlatformVersion
+	}
+	if opts.ChromedriverExecutable != "" {
+		caps["appium:chromedriverExecutable"] = opts.ChromedriverExecutable
+	}
+
+	args, err := mergeChromeArgs(nil, cfg.extraChromeArgs(), cfg.excludedSwitches)
+	if err != nil {
+		return nil, err
+	}
+	chromeCfg := chromeCapabilities{Args: args}
+	for _, sw := range cfg.excludedSwitches {
+		name, _ := splitFlag(sw)
+		chromeCfg.ExcludeSwitches = append(chromeCfg.ExcludeSwitches, name)
+	}
+	caps["appium:chromeOptions"] = chromeCfg
+
+	for k, v := range opts.Capabilities {
+		if !strings.Contains(k, ":") && !w3cCapability[k] {
+			k = "appium:" + k
+		}
+		caps[k] = v
+	}
+	return caps, nil
+}
+
+// w3cCapability holds the standard capabilities that must not be prefixed.
+var w3cCapability = map[string]bool{
+	"browserName":         true,
+	"browserVersion":      true,
+	"platformName":        true,
+	"acceptInsecureCerts": true,
+	"pageLoadStrategy":    true,
+	"proxy":               true,
+	"timeouts":            true,
+}
+
+// Contexts returns the contexts of an Appium session, e.g. "NATIVE_APP" and
+// "CHROMIUM".
+func (s *Session) Contexts() ([]string, error) {
+	wd, ok := s.WebDriver.(*remoteWD)
+	if !ok {
+		return nil, fmt.Errorf("contexts are not supported by %T", s.WebDriver)
+	}
+	return wd.stringsCommand("/session/%s/contexts")
+}
+
+// Context returns the current context of an Appium session.
+func (s *Session) Context() (string, error) {
+	wd, ok := s.WebDriver.(*remoteWD)
+	if !ok {
+		return "", fmt.Errorf("contexts are not supported by %T", s.WebDriver)
+	}
+	return wd.stringCommand("/session/%s/context")
+}
+
+// SwitchContext switches an Appium session to the named context, e.g. to
+// "NATIVE_APP" to dismiss a system dialog and back to "CHROMIUM".
+func (s *Session) SwitchContext(name string) error {
+	wd, ok := s.WebDriver.(*remoteWD)
+	if !ok {
+		return fmt.Errorf("contexts are not supported by %T", s.WebDriver)
+	}
+	return wd.voidCommand("/session/%s/context", map[string]string{"name": name})
+}
+
+// Orientation is the screen orientation of a mobile device.
+type Orientation string
+
+const (
+	Portrait  Orientation = "PORTRAIT"
+	Landscape Orientation = "LANDSCAPE"
+)
+
+// Orientation returns the screen orientation of the device of an Appium
+// session.
+func (s *Session) Orientation() (Orientation, error) {
+	wd, ok := s.WebDriver.(*remoteWD)
+	if !ok {
+		return "", fmt.Errorf("orientation is not supported by %T", s.WebDriver)
+	}
+	o, err := wd.stringCommand("/session/%s/orientation")
+	return Orientation(o), err
+}
+
+// SetOrientation rotates the device of an Appium session.
+func (s *Session) SetOrientation(o Orientation) error {
+	wd, ok := s.WebDriver.(*remoteWD)
+	if !ok {
+		return fmt.Errorf("orientation is not supported by %T", s.WebDriver)
+	}
+	return wd.voidCommand("/session/%s/orientation", map[string]Orientation{"orientation": o})
+}
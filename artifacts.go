@@ -0,0 +1,114 @@
+package webdriver
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// WithFailureArtifacts makes the high-level operations of the session (Get,
+// GetDOM, GetDOMs, ClickDOM and Wait) capture the state of the browser when
+// they fail: a screenshot, the page source, the current URL and the browser
+// console logs are written to a new timestamped directory below dir, and the
+// returned error is an *ArtifactsError pointing to it.
+func WithFailureArtifacts(dir string) Option {
+	return func(c *sessionConfig) {
+		c.artifactsDir = dir
+	}
+}
+
+// ArtifactsError is a failed operation annotated with the directory holding
+// the state of the browser at the time of the failure. Dir is the location of
+// the artifacts in the SnapshotSink of the session if it has no local
+// artifacts directory.
+type ArtifactsError struct {
+	Err error
+	Dir string
+}
+
+func (e *ArtifactsError) Error() string {
+	return fmt.Sprintf("%v (artifacts in %s)", e.Err, e.Dir)
+}
+
+// Cause returns the underlying error, for errors.Cause.
+func (e *ArtifactsError) Cause() error {
+	return e.Err
+}
+
+// Unwrap returns the underlying error, for errors.Is and errors.As.
+func (e *ArtifactsError) Unwrap() error {
+	return e.Err
+}
+
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// fail captures the failure artifacts of the operation op on target if err
+// is not nil and the session was created with WithFailureArtifacts or
+// WithSnapshotSink.
+func (s *Session) fail(op, target string, err error) error {
+	if te, ok := err.(*TimeoutError); ok && te.Op == "" {
+		te.Op, te.Selector = op, target
+	}
+	if err == nil || s.artifactsDir == "" && s.sink == nil {
+		return err
+	}
+	if _, ok := err.(*ArtifactsError); ok {
+		return err
+	}
+
+	name := unsafePathChars.ReplaceAllString(time.Now().Format("20060102-150405.000")+"-"+op, "_")
+	var dir string
+	if s.artifactsDir != "" {
+		dir = filepath.Join(s.artifactsDir, name)
+		if mkErr := os.MkdirAll(dir, 0755); mkErr != nil {
+			debugLog("error creating artifacts directory %v: %v", dir, mkErr)
+			return err
+		}
+	}
+
+	// stored is the location of the artifacts in the sink, if stored there.
+	var stored string
+	// Capturing is best effort: the browser may be the reason of the failure.
+	write := func(file string, data []byte) {
+		if dir != "" {
+			if wErr := ioutil.WriteFile(filepath.Join(dir, file), data, 0644); wErr != nil {
+				debugLog("error writing artifact %v: %v", file, wErr)
+			}
+		}
+		if s.sink != nil {
+			loc, pErr := s.sink.Put("artifacts/"+name+"/"+file, data)
+			if pErr != nil {
+				debugLog("error storing artifact %v: %v", file, pErr)
+			} else if stored == "" {
+				// The location of the file, with either separator.
+				stored = strings.TrimRight(strings.TrimSuffix(loc, file), `/\`)
+			}
+		}
+	}
+	write("error.txt", []byte(fmt.Sprintf("%s %s\n%v\n", op, target, err)))
+	if u, uErr := s.CurrentURL(); uErr == nil {
+		write("url.txt", []byte(u+"\n"))
+	}
+	if img, sErr := s.Screenshot(); sErr == nil {
+		write("screenshot.png", img)
+	}
+	if src, pErr := s.PageSource(); pErr == nil {
+		write("source.html", []byte(src))
+	}
+	if msgs, lErr := s.Log(Browser); lErr == nil {
+		var b strings.Builder
+		for _, m := range msgs {
+			fmt.Fprintf(&b, "%s %s %s\n", m.Timestamp.Format(time.RFC3339Nano), m.Level, m.Message)
+		}
+		write("browser.log", []byte(b.String()))
+	}
+
+	if dir == "" {
+		dir = stored
+	}
+	return &ArtifactsError{Err: err, Dir: dir}
+}
@@ -0,0 +1,42 @@
+package webdriver
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// artifactsWD is a WebDriver answering the commands capturing artifacts.
+type artifactsWD struct {
+	WebDriver
+}
+
+func (artifactsWD) CurrentURL() (string, error)        { return "https://example.com/", nil }
+func (artifactsWD) Screenshot() ([]byte, error)        { return nil, errors.New("no screenshot") }
+func (artifactsWD) PageSource() (string, error)        { return "<html></html>", nil }
+func (artifactsWD) Log(typ LogType) ([]Message, error) { return nil, errors.New("no logs") }
+
+// bucketSink stores the artifacts in memory, at bucket locations.
+type bucketSink map[string][]byte
+
+func (b bucketSink) Put(name string, data []byte) (string, error) {
+	b[name] = data
+	return "s3://bucket/" + name, nil
+}
+
+func TestFailArtifactsSink(t *testing.T) {
+	sink := bucketSink{}
+	s := &Session{WebDriver: artifactsWD{}, sink: sink}
+
+	err := s.fail("Get", "https://example.com/", errors.New("boom"))
+	ae, ok := err.(*ArtifactsError)
+	if !ok {
+		t.Fatalf("fail() = %v, want *ArtifactsError", err)
+	}
+	if !strings.HasPrefix(ae.Dir, "s3://bucket/artifacts/") || strings.HasSuffix(ae.Dir, "/") || strings.HasSuffix(ae.Dir, ".txt") {
+		t.Errorf("Dir = %q, want the artifacts location in the bucket", ae.Dir)
+	}
+	if len(sink) != 3 {
+		t.Errorf("stored %v artifacts, want 3", len(sink))
+	}
+}
@@ -0,0 +1,51 @@
+package webdriver
+
+import "encoding/json"
+
+// Attributes returns the values of the named attributes of the element in a
+// single round trip to the driver. Attributes the element does not have are
+// omitted from the result.
+func (e *Element) Attributes(names ...string) (map[string]string, error) {
+	if len(names) == 0 {
+		return map[string]string{}, nil
+	}
+	return e.attributes(`var el = arguments[0], names = arguments[1], ret = {};
+for (var i = 0; i < names.length; i++) {
+	var v = el.getAttribute(names[i]);
+	if (v !== null) {
+		ret[names[i]] = v;
+	}
+}
+return ret;`, names)
+}
+
+// AllAttributes returns all the attributes of the element in a single round
+// trip to the driver.
+func (e *Element) AllAttributes() (map[string]string, error) {
+	return e.attributes(`var attrs = arguments[0].attributes, ret = {};
+for (var i = 0; i < attrs.length; i++) {
+	ret[attrs[i].name] = attrs[i].value;
+}
+return ret;`, nil)
+}
+
+func (e *Element) attributes(script string, names []string) (map[string]string, error) {
+	var data []byte
+	err := e.inFrame(func() (err error) {
+		data, err = e.s.ExecuteScriptRaw(script, []interface{}{e.WebElement, names})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	reply := struct {
+		Value map[string]string
+	}{}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return nil, err
+	}
+	if reply.Value == nil {
+		reply.Value = map[string]string{}
+	}
+	return reply.Value, nil
+}
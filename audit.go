@@ -0,0 +1,148 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// AuditReport is the parsed result of a Lighthouse run.
+type AuditReport struct {
+	// URL is the audited URL after redirects.
+	URL string
+	// Scores maps the audited categories, e.g. "performance", to their
+	// score between 0 and 100.
+	Scores map[string]float64
+	// Metrics maps the key performance metrics, e.g.
+	// "largest-contentful-paint", to their value in milliseconds, or
+	// unitless for "cumulative-layout-shift".
+	Metrics map[string]float64
+	// Raw is the full Lighthouse JSON report.
+	Raw json.RawMessage
+}
+
+// auditMetrics are the Lighthouse audits reported in AuditReport.Metrics.
+var auditMetrics = []string{
+	"first-contentful-paint",
+	"largest-contentful-paint",
+	"total-blocking-time",
+	"cumulative-layout-shift",
+	"speed-index",
+	"interactive",
+	"server-response-time",
+}
+
+// debuggerAddress returns the host:port of the DevTools endpoint of the
+// session's browser.
+func (s *Session) debuggerAddress() (string, error) {
+	if wd, ok := s.WebDriver.(*cdpWD); ok {
+		return wd.debuggerAddress, nil
+	}
+
+	caps, err := s.Capabilities()
+	if err != nil {
+		return "", err
+	}
+	for _, key := range []string{"goog:chromeOptions", EdgeCapabilitiesKey} {
+		if opts, ok := caps[key].(map[string]interface{}); ok {
+			if addr, ok := opts["debuggerAddress"].(string); ok && addr != "" {
+				return addr, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("the browser does not expose a debugging port")
+}
+
+// Audit runs Lighthouse against the current URL of the session, reusing its
+// browser through the debugging port, and returns the scores of the given
+// categories, e.g. "performance" or "accessibility", or of all categories if
+// none is given. Lighthouse opens the URL in a new tab, so the session's page
+// is left alone.
+//
+// The lighthouse binary is located through the LIGHTHOUSE environment
+// variable, defaulting to lighthouse in PATH. The debugging port must be
+// reachable from this host, which excludes Docker and cloud browsers.
+func (s *Session) Audit(categories ...string) (*AuditReport, error) {
+	bin := strings.TrimSpace(os.Getenv("LIGHTHOUSE"))
+	if bin == "" {
+		var err error
+		if bin, err = exec.LookPath("lighthouse"); err != nil {
+			return nil, fmt.Errorf("lighthouse not found, set LIGHTHOUSE: %v", err)
+		}
+	}
+
+	addr, err := s.debuggerAddress()
+	if err != nil {
+		return nil, err
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid debugger address %q: %v", addr, err)
+	}
+
+	u, err := s.CurrentURL()
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{u, "--hostname=" + host, "--port=" + port, "--output=json", "--output-path=stdout", "--quiet"}
+	if len(categories) > 0 {
+		args = append(args, "--only-categories="+strings.Join(categories, ","))
+	}
+	cmd := exec.Command(bin, args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("lighthouse failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return parseAuditReport(out)
+}
+
+func parseAuditReport(data []byte) (*AuditReport, error) {
+	lhr := struct {
+		FinalURL     string `json:"finalUrl"`
+		FinalDisplay string `json:"finalDisplayedUrl"`
+		RuntimeError *struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"runtimeError"`
+		Categories map[string]struct {
+			Score *float64 `json:"score"`
+		} `json:"categories"`
+		Audits map[string]struct {
+			NumericValue *float64 `json:"numericValue"`
+		} `json:"audits"`
+	}{}
+	if err := json.Unmarshal(data, &lhr); err != nil {
+		return nil, fmt.Errorf("error parsing lighthouse report: %v", err)
+	}
+	if lhr.RuntimeError != nil && lhr.RuntimeError.Code != "" && lhr.RuntimeError.Code != "NO_ERROR" {
+		return nil, fmt.Errorf("lighthouse error %s: %s", lhr.RuntimeError.Code, lhr.RuntimeError.Message)
+	}
+
+	report := &AuditReport{
+		URL:     lhr.FinalURL,
+		Scores:  map[string]float64{},
+		Metrics: map[string]float64{},
+		Raw:     json.RawMessage(data),
+	}
+	if report.URL == "" {
+		report.URL = lhr.FinalDisplay
+	}
+	for id, c := range lhr.Categories {
+		if c.Score != nil {
+			report.Scores[id] = *c.Score * 100
+		}
+	}
+	for _, id := range auditMetrics {
+		if a, ok := lhr.Audits[id]; ok && a.NumericValue != nil {
+			report.Metrics[id] = *a.NumericValue
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,37 @@
+package webdriver
+
+import "testing"
+
+func TestParseAuditReport(t *testing.T) {
+	report, err := parseAuditReport([]byte(`{
+		"finalUrl": "https://example.com/",
+		"categories": {"performance": {"score": 0.93}, "seo": {"score": null}},
+		"audits": {
+			"largest-contentful-paint": {"numericValue": 1234.5},
+			"cumulative-layout-shift": {"numericValue": 0.02},
+			"uses-http2": {"numericValue": 1}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("parseAuditReport() returned error: %v", err)
+	}
+	if report.URL != "https://example.com/" {
+		t.Errorf("URL = %q", report.URL)
+	}
+	if got := report.Scores["performance"]; got < 92.9 || got > 93.1 {
+		t.Errorf("performance score = %v, want 93", got)
+	}
+	if _, ok := report.Scores["seo"]; ok {
+		t.Error("null score reported")
+	}
+	if got := report.Metrics["largest-contentful-paint"]; got != 1234.5 {
+		t.Errorf("LCP = %v, want 1234.5", got)
+	}
+	if _, ok := report.Metrics["uses-http2"]; ok {
+		t.Error("non key metric reported")
+	}
+
+	if _, err := parseAuditReport([]byte(`{"runtimeError": {"code": "NO_FCP", "message": "no paint"}}`)); err == nil {
+		t.Error("parseAuditReport() with a runtime error returned no error")
+	}
+}
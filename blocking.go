@@ -0,0 +1,181 @@
+package webdriver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithFilterList makes the session block the requests matched by list, e.g.
+// EasyList and EasyPrivacy read into one list, see SetFilterList.
+func WithFilterList(list *FilterList) Option {
+	return func(c *sessionConfig) {
+		c.filterList = list
+	}
+}
+
+// SetFilterList makes the current window fail the requests matched by list,
+// e.g. to ads and trackers, as if blocked by an extension, which saves their
+// bandwidth and spares waits from timing out on slow ad networks. A nil list
+// stops blocking. Requests are intercepted through a DevTools connection to
+// the window, which requires a local Chromium-based browser; mocks of
+// MockResponse apply to the requests not blocked.
+func (s *Session) SetFilterList(list *FilterList) error {
+	conn, err := s.pageConn()
+	if err != nil {
+		return err
+	}
+
+	mainFrame := ""
+	if list != nil {
+		tree := struct {
+			FrameTree struct {
+				Frame struct {
+					ID string `json:"id"`
+				} `json:"frame"`
+			} `json:"frameTree"`
+		}{}
+		if err := s.cdp("Page.getFrameTree", nil, &tree); err != nil {
+			return err
+		}
+		mainFrame = tree.FrameTree.Frame.ID
+	}
+
+	m := s.interceptor(conn)
+	m.mu.Lock()
+	m.block, m.mainFrame = list, mainFrame
+	patterns := m.patterns()
+	m.mu.Unlock()
+
+	return m.enable(patterns)
+}
+
+// resourceTypes are the resource types of DevTools requests.
+var resourceTypes = []string{
+	"Document", "Stylesheet", "Image", "Media", "Font", "Script", "TextTrack",
+	"XHR", "Fetch", "Prefetch", "EventSource", "WebSocket", "Manifest",
+	"SignedExchange", "Ping", "CSPViolationReport", "Preflight", "Other",
+}
+
+// WithBlockedResourceTypes makes the session block the requests for the
+// resource types, see SetBlockedResourceTypes.
+func WithBlockedResourceTypes(types ...string) Option {
+	return func(c *sessionConfig) {
+		c.blockedTypes = append(c.blockedTypes, types...)
+	}
+}
+
+// SetBlockedResourceTypes makes the current window fail the requests for the
+// resource types, e.g. "image", "font" and "media", which text scraping can
+// do without, to speed up page loads. The types are those of DevTools,
+// matched regardless of case: document, stylesheet, image, media, font,
+// script, texttrack, xhr, fetch, prefetch, eventsource, websocket, manifest,
+// signedexchange, ping, cspviolationreport, preflight and other. No types
+// stop blocking. Like SetFilterList, this requires a local Chromium-based
+// browser.
+func (s *Session) SetBlockedResourceTypes(types ...string) error {
+	canonical, err := canonicalResourceTypes(types)
+	if err != nil {
+		return err
+	}
+	conn, err := s.pageConn()
+	if err != nil {
+		return err
+	}
+
+	m := s.interceptor(conn)
+	m.mu.Lock()
+	m.blockTypes = canonical
+	patterns := m.patterns()
+	m.mu.Unlock()
+
+	return m.enable(patterns)
+}
+
+// BlockedResourceTypes returns the resource types blocked in the current
+// window.
+func (s *Session) BlockedResourceTypes() ([]string, error) {
+	conn, err := s.pageConn()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	m := s.mocks[conn]
+	s.mu.Unlock()
+	if m == nil {
+		return nil, nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.blockTypes...), nil
+}
+
+// GetBlocking is Get blocking the requests for the resource types while the
+// page loads, instead of those set by SetBlockedResourceTypes, which are
+// blocked again afterwards.
+func (s *Session) GetBlocking(url string, types ...string) error {
+	prev, err := s.BlockedResourceTypes()
+	if err != nil {
+		return err
+	}
+	if err := s.SetBlockedResourceTypes(types...); err != nil {
+		return err
+	}
+	err = s.Get(url)
+	if rerr := s.SetBlockedResourceTypes(prev...); err == nil {
+		err = rerr
+	}
+	return err
+}
+
+func canonicalResourceTypes(types []string) ([]string, error) {
+	var ret []string
+	for _, typ := range types {
+		found := ""
+		for _, rt := range resourceTypes {
+			if strings.EqualFold(typ, rt) {
+				found = rt
+			}
+		}
+		if found == "" {
+			return nil, fmt.Errorf("unknown resource type %q", typ)
+		}
+		ret = append(ret, found)
+	}
+	return ret, nil
+}
+
+// BlockedRequests returns the number of requests of the current window
+// blocked by its filter list or resource types.
+func (s *Session) BlockedRequests() (int, error) {
+	conn, err := s.pageConn()
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	m := s.mocks[conn]
+	s.mu.Unlock()
+	if m == nil {
+		return 0, nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.blocked, nil
+}
+
+// requestSource returns the URL of the page issuing a request, as far as the
+// request headers tell.
+func requestSource(headers map[string]string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, "Referer") {
+			return v
+		}
+	}
+	for k, v := range headers {
+		if strings.EqualFold(k, "Origin") {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCanonicalResourceTypes(t *testing.T) {
+	got, err := canonicalResourceTypes([]string{"image", "FONT", "xhr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Image", "Font", "XHR"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("canonicalResourceTypes = %q, want %q", got, want)
+	}
+	if _, err := canonicalResourceTypes([]string{"images"}); err == nil {
+		t.Errorf("unknown resource type accepted")
+	}
+}
+
+func TestInterceptPatterns(t *testing.T) {
+	m := &mocker{rules: []*mockRule{{pattern: "*/api/*"}}, blockTypes: []string{"Image"}}
+	want := []map[string]interface{}{
+		{"urlPattern": "*/api/*"},
+		{"urlPattern": "*", "resourceType": "Image"},
+	}
+	if got := m.patterns(); !reflect.DeepEqual(got, want) {
+		t.Errorf("patterns = %v, want %v", got, want)
+	}
+
+	m.block = &FilterList{}
+	if got := m.patterns(); len(got) != 1 || got[0]["urlPattern"] != "*" || got[0]["resourceType"] != nil {
+		t.Errorf("patterns with filter list = %v, want all requests", got)
+	}
+}
+
+func TestBlockedRequestsConcurrent(t *testing.T) {
+	srv := fakeDevTools(t)
+	defer srv.Close()
+	ws, err := dialWebSocket("ws"+strings.TrimPrefix(srv.URL, "http")+"/devtools/page/1", 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newCDPConn(ws)
+	defer c.Close()
+
+	m := &mocker{conn: c, blockTypes: []string{"Image"}}
+	params := json.RawMessage(`{"requestId": "1", "request": {"url": "https://example.com/a.png"}, "resourceType": "Image"}`)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			m.handle(params)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		m.mu.Lock()
+		m.blockTypes = []string{"Image", "Font"}[:1+i%2]
+		m.mu.Unlock()
+	}
+	<-done
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.blocked != 100 {
+		t.Errorf("blocked = %d, want 100", m.blocked)
+	}
+}
@@ -0,0 +1,121 @@
+package webdriver
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// canvasPNGScript returns the data URL of the canvas in arguments[0].
+const canvasPNGScript = `var c = arguments[0];
+if (!(c instanceof HTMLCanvasElement)) {
+	throw new Error("not a canvas element: " + c.tagName);
+}
+return c.toDataURL("image/png");`
+
+// CanvasPNG returns the content of a canvas element as a PNG image, through
+// toDataURL. It fails for canvases tainted by cross-origin images.
+func (e *Element) CanvasPNG() ([]byte, error) {
+	var ret interface{}
+	err := e.inFrame(func() (err error) {
+		ret, err = e.s.ExecuteScript(canvasPNGScript, []interface{}{e.WebElement})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	url, _ := ret.(string)
+	const prefix = "data:image/png;base64,"
+	if !strings.HasPrefix(url, prefix) {
+		return nil, fmt.Errorf("unexpected canvas data URL %.40q", url)
+	}
+	return base64.StdEncoding.DecodeString(url[len(prefix):])
+}
+
+// ChartDataset is a series of a chart.
+type ChartDataset struct {
+	Label string
+	// Data are the values as configured in the chart, e.g. numbers, [x, y]
+	// pairs or {x, y} objects.
+	Data []interface{}
+}
+
+// ChartData is the data behind a chart rendered in a canvas.
+type ChartData struct {
+	// Library is "chart.js" or "echarts".
+	Library string
+	// Labels are the labels of the category axis, if any.
+	Labels   []interface{}
+	Datasets []ChartDataset
+}
+
+// chartDataScript returns the library, labels and datasets of the chart drawn
+// by the element in arguments[0], or null.
+const chartDataScript = `var el = arguments[0];
+var canvas = el.tagName === "CANVAS" ? el : el.querySelector("canvas");
+if (window.Chart && canvas) {
+	var chart = null;
+	if (Chart.getChart) {
+		chart = Chart.getChart(canvas);
+	} else if (Chart.instances) {
+		for (var id in Chart.instances) {
+			var c = Chart.instances[id];
+			if ((c.canvas || c.chart.canvas) === canvas) {
+				chart = c;
+			}
+		}
+	}
+	if (chart) {
+		var d = chart.data || chart.config.data;
+		return {library: "chart.js", labels: d.labels || [], datasets: (d.datasets || []).map(function(s) {
+			return {label: s.label || "", data: s.data || []};
+		})};
+	}
+}
+if (window.echarts) {
+	var node = el.closest("[_echarts_instance_]") || el.querySelector("[_echarts_instance_]");
+	var inst = node && echarts.getInstanceByDom(node);
+	if (inst) {
+		var opt = inst.getOption();
+		var axis = [].concat(opt.xAxis || [], opt.yAxis || []).filter(function(a) { return a.type === "category" || a.data; })[0];
+		return {library: "echarts", labels: axis && axis.data || [], datasets: (opt.series || []).map(function(s) {
+			return {label: s.name || "", data: s.data || []};
+		})};
+	}
+}
+return null;`
+
+// ChartData reads the data of the Chart.js or ECharts chart drawn by the
+// element, which is the canvas or one of its containers, from the chart
+// instance in the page, so that charts can be scraped without pixel
+// analysis.
+func (e *Element) ChartData() (*ChartData, error) {
+	var data []byte
+	err := e.inFrame(func() (err error) {
+		data, err = e.s.ExecuteScriptRaw(chartDataScript, []interface{}{e.WebElement})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reply := struct {
+		Value *struct {
+			Library  string
+			Labels   []interface{}
+			Datasets []ChartDataset
+		}
+	}{}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return nil, err
+	}
+	if reply.Value == nil {
+		return nil, fmt.Errorf("no Chart.js or ECharts chart found")
+	}
+	return &ChartData{
+		Library:  reply.Value.Library,
+		Labels:   reply.Value.Labels,
+		Datasets: reply.Value.Datasets,
+	}, nil
+}
@@ -22,6 +22,10 @@ const CapabilitiesKey = "goog:chromeOptions"
 // DeprecatedCapabilitiesKey is the legacy version of CapabilitiesKey.
 const DeprecatedCapabilitiesKey = "chromeOptions"
 
+// EdgeCapabilitiesKey is the key in the top-level Capabilities map under which
+// msedgedriver expects the Edge-specific options to be set.
+const EdgeCapabilitiesKey = "ms:edgeOptions"
+
 // Capabilities defines the Chrome-specific desired capabilities when using
 // ChromeDriver. An instance of this struct can be stored in the Capabilities
 // map with a key of CapabilitiesKey ("goog:chromeOptions").  See
@@ -0,0 +1,169 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// cdpConn is a Chrome DevTools Protocol connection to a single target.
+type cdpConn struct {
+	ws *wsConn
+
+	mu       sync.Mutex
+	nextID   int64
+	pending  map[int64]chan cdpReply
+	handlers map[string][]func(params json.RawMessage)
+	err      error
+	closed   chan struct{}
+}
+
+type cdpReply struct {
+	Result json.RawMessage
+	Err    error
+}
+
+// cdpError is an error reply to a DevTools command.
+type cdpError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data"`
+}
+
+func (e *cdpError) Error() string {
+	if e.Data != "" {
+		return fmt.Sprintf("cdp error %d: %s: %s", e.Code, e.Message, e.Data)
+	}
+	return fmt.Sprintf("cdp error %d: %s", e.Code, e.Message)
+}
+
+func newCDPConn(ws *wsConn) *cdpConn {
+	c := &cdpConn{
+		ws:       ws,
+		pending:  map[int64]chan cdpReply{},
+		handlers: map[string][]func(json.RawMessage){},
+		closed:   make(chan struct{}),
+	}
+	go c.readLoop()
+	return c
+}
+
+func (c *cdpConn) readLoop() {
+	for {
+		data, err := c.ws.ReadMessage()
+		if err != nil {
+			c.shutdown(err)
+			return
+		}
+
+		msg := struct {
+			ID     int64           `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+			Result json.RawMessage `json:"result"`
+			Error  *cdpError       `json:"error"`
+		}{}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			debugLog("error decoding cdp message: %v", err)
+			continue
+		}
+
+		if msg.Method != "" {
+			c.mu.Lock()
+			handlers := c.handlers[msg.Method]
+			c.mu.Unlock()
+			for _, h := range handlers {
+				h(msg.Params)
+			}
+			continue
+		}
+
+		c.mu.Lock()
+		ch := c.pending[msg.ID]
+		delete(c.pending, msg.ID)
+		c.mu.Unlock()
+		if ch == nil {
+			continue
+		}
+		if msg.Error != nil {
+			ch <- cdpReply{Err: msg.Error}
+		} else {
+			ch <- cdpReply{Result: msg.Result}
+		}
+	}
+}
+
+func (c *cdpConn) shutdown(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+	if isWebSocketClosed(err) {
+		err = fmt.Errorf("devtools connection closed")
+	}
+	c.err = err
+	for id, ch := range c.pending {
+		ch <- cdpReply{Err: err}
+		delete(c.pending, id)
+	}
+	close(c.closed)
+}
+
+// on registers a handler for an event. Handlers run on the read loop and
+// must not issue commands.
+func (c *cdpConn) on(event string, h func(params json.RawMessage)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers[event] = append(c.handlers[event], h)
+}
+
+// send issues a command and returns the channel its reply is delivered to.
+func (c *cdpConn) send(method string, params interface{}) (<-chan cdpReply, error) {
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return nil, c.err
+	}
+	c.nextID++
+	id := c.nextID
+	ch := make(chan cdpReply, 1)
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	data, err := json.Marshal(map[string]interface{}{
+		"id":     id,
+		"method": method,
+		"params": params,
+	})
+	if err == nil {
+		err = c.ws.WriteMessage(data)
+	}
+	if err != nil {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return nil, err
+	}
+	return ch, nil
+}
+
+// call issues a command and waits for its result.
+func (c *cdpConn) call(method string, params interface{}) (json.RawMessage, error) {
+	ch, err := c.send(method, params)
+	if err != nil {
+		return nil, err
+	}
+	reply := <-ch
+	return reply.Result, reply.Err
+}
+
+func (c *cdpConn) Close() error {
+	err := c.ws.Close()
+	c.shutdown(fmt.Errorf("devtools connection closed"))
+	return err
+}
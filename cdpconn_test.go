@@ -0,0 +1,158 @@
+package webdriver
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDevTools answers every command with its params as result and emits an
+// event before each reply.
+func fakeDevTools(t *testing.T) *httptest.Server {
+	return httptest.NewServer(fakeDevToolsHandler(t))
+}
+
+// fakeDevToolsHandler is the handler of fakeDevTools.
+func fakeDevToolsHandler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + wsGUID))
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+			"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")
+		rw.Flush()
+
+		// The server end does not mask its frames, which the client accepts.
+		server := &wsConn{conn: conn, br: bufio.NewReader(rw)}
+		for {
+			data, err := server.ReadMessage()
+			if err != nil {
+				return
+			}
+			msg := struct {
+				ID     int64
+				Method string
+				Params json.RawMessage
+			}{}
+			json.Unmarshal(data, &msg)
+
+			event, _ := json.Marshal(map[string]interface{}{"method": "Test.event", "params": map[string]string{"method": msg.Method}})
+			writeServerFrame(conn, event)
+			if msg.Method == "Test.fail" {
+				reply, _ := json.Marshal(map[string]interface{}{"id": msg.ID, "error": map[string]interface{}{"code": -32000, "message": "failed"}})
+				writeServerFrame(conn, reply)
+				continue
+			}
+			reply, _ := json.Marshal(map[string]interface{}{"id": msg.ID, "result": msg.Params})
+			writeServerFrame(conn, reply)
+		}
+	})
+}
+
+// fakeBrowser serves the /json/version endpoint of a browser pointing to a
+// fakeDevTools browser target, calling onVersion first if it is set. targets
+// returns the paths of the DevTools connections made.
+func fakeBrowser(t *testing.T, onVersion func()) (srv *httptest.Server, targets func() []string) {
+	var mu sync.Mutex
+	var paths []string
+	devtools := fakeDevToolsHandler(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/json/version", func(w http.ResponseWriter, r *http.Request) {
+		if onVersion != nil {
+			onVersion()
+		}
+		fmt.Fprintf(w, `{"webSocketDebuggerUrl": "ws://%s/devtools/browser/b1"}`, r.Host)
+	})
+	mux.HandleFunc("/devtools/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		devtools.ServeHTTP(w, r)
+	})
+	return httptest.NewServer(mux), func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), paths...)
+	}
+}
+
+func writeServerFrame(w io.Writer, payload []byte) {
+	header := []byte{0x80 | wsOpText}
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, byte(n))
+	case n <= 0xffff:
+		header = append(header, 126, byte(n>>8), byte(n))
+	default:
+		header = append(header, 127, 0, 0, 0, 0, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+	w.Write(append(header, payload...))
+}
+
+func TestCDPConn(t *testing.T) {
+	srv := fakeDevTools(t)
+	defer srv.Close()
+
+	ws, err := dialWebSocket("ws"+strings.TrimPrefix(srv.URL, "http")+"/devtools/page/1", 5*time.Second)
+	if err != nil {
+		t.Fatalf("dialWebSocket() returned error: %v", err)
+	}
+	c := newCDPConn(ws)
+	defer c.Close()
+
+	events := make(chan string, 10)
+	c.on("Test.event", func(params json.RawMessage) {
+		ev := struct{ Method string }{}
+		json.Unmarshal(params, &ev)
+		events <- ev.Method
+	})
+
+	long := strings.Repeat("x", 70000)
+	res, err := c.call("Test.echo", map[string]string{"s": long})
+	if err != nil {
+		t.Fatalf("call() returned error: %v", err)
+	}
+	got := struct{ S string }{}
+	if err := json.Unmarshal(res, &got); err != nil || got.S != long {
+		t.Errorf("call() = %.40s..., want the params echoed", res)
+	}
+	if ev := <-events; ev != "Test.echo" {
+		t.Errorf("event for %q, want Test.echo", ev)
+	}
+
+	if _, err := c.call("Test.fail", nil); err == nil || !strings.Contains(err.Error(), "failed") {
+		t.Errorf("call(Test.fail) returned error %v, want failed", err)
+	}
+}
+
+func TestScriptError(t *testing.T) {
+	for _, tc := range []struct {
+		desc, code string
+	}{
+		{"Error: stale element reference: element is not attached\n    at revive", "stale element reference"},
+		{"Error: no such element: nothing", "no such element"},
+		{"TypeError: x is undefined", "javascript error"},
+	} {
+		err := scriptError(tc.desc)
+		if e, ok := err.(*Error); !ok || e.Err != tc.code {
+			t.Errorf("scriptError(%q) = %v, want code %q", tc.desc, err, tc.code)
+		}
+	}
+	if !StaleElement(scriptError("Error: stale element reference: gone")) {
+		t.Error("StaleElement() = false for a stale script error")
+	}
+}
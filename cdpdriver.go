@@ -0,0 +1,1625 @@
+package webdriver
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CDPDriver launches Chrome directly and drives it over the DevTools protocol,
+// without a WebDriver server. It needs no driver binary, so it never falls
+// out of sync with the installed Chrome version. Chrome is located through
+// WithChromeBinary, the CHROME_BIN environment variable or the usual install
+// locations.
+//
+// The Session and Element API is supported in full. Of the lower level
+// WebDriver methods, frames, multiple windows and logs other than Browser are
+// not supported.
+const CDPDriver DriverKind = "cdp"
+
+// cdpPrelude defines the page-side helpers of the CDP driver. Elements are
+// exchanged with Go in the WebDriver JSON format, their references indexing a
+// registry of the document; a reference from another document does not
+// resolve and reports a stale element.
+const cdpPrelude = `
+var KEY = "` + webElementIdentifier + `";
+var reg = window.__webdriverRegistry;
+if (!reg) {
+	reg = {prefix: Math.random().toString(36).slice(2), n: 0, nodes: {}, ids: new WeakMap()};
+	Object.defineProperty(window, "__webdriverRegistry", {value: reg});
+}
+function fail(code, msg) {
+	throw new Error(code + ": " + msg);
+}
+function revive(v) {
+	if (v === null || typeof v !== "object") {
+		return v;
+	}
+	if (typeof v[KEY] === "string") {
+		var node = reg.nodes[v[KEY]];
+		if (!node || !node.isConnected) {
+			fail("stale element reference", "element is not attached to the page document");
+		}
+		return node;
+	}
+	if (Array.isArray(v)) {
+		return v.map(revive);
+	}
+	var o = {};
+	for (var k in v) {
+		o[k] = revive(v[k]);
+	}
+	return o;
+}
+function wrap(v, seen) {
+	if (v === undefined || v === null || typeof v === "function") {
+		return null;
+	}
+	if (typeof v !== "object") {
+		return v;
+	}
+	if (v instanceof Node) {
+		var id = reg.ids.get(v);
+		if (!id) {
+			id = reg.prefix + "-" + (++reg.n);
+			reg.nodes[id] = v;
+			reg.ids.set(v, id);
+		}
+		var ref = {};
+		ref[KEY] = id;
+		return ref;
+	}
+	seen = seen || [];
+	if (seen.indexOf(v) >= 0) {
+		fail("javascript error", "cyclic object value");
+	}
+	seen.push(v);
+	var out;
+	if (Array.isArray(v) || v instanceof NodeList || v instanceof HTMLCollection) {
+		out = Array.prototype.map.call(v, function(e) { return wrap(e, seen); });
+	} else if (typeof v.toJSON === "function") {
+		out = v.toJSON();
+	} else {
+		out = {};
+		for (var k in v) {
+			out[k] = wrap(v[k], seen);
+		}
+	}
+	seen.pop();
+	return out;
+}
+function find(root, by, value) {
+	root = root || document;
+	var nodes = [];
+	switch (by) {
+	case "xpath":
+		var res;
+		try {
+			res = document.evaluate(value, root, null, XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null);
+		} catch (e) {
+			fail("invalid selector", e.message);
+		}
+		for (var i = 0; i < res.snapshotLength; i++) {
+			nodes.push(res.snapshotItem(i));
+		}
+		return nodes;
+	case "css selector":
+		try {
+			return Array.prototype.slice.call(root.querySelectorAll(value));
+		} catch (e) {
+			fail("invalid selector", e.message);
+		}
+	case "id":
+		return find(root, "css selector", "#" + CSS.escape(value));
+	case "name":
+		return find(root, "css selector", "[name=\"" + CSS.escape(value) + "\"]");
+	case "class name":
+		return find(root, "css selector", "." + CSS.escape(value));
+	case "tag name":
+		return Array.prototype.slice.call(root.getElementsByTagName(value));
+	case "link text":
+	case "partial link text":
+		return Array.prototype.filter.call(root.querySelectorAll("a"), function(a) {
+			var t = a.innerText.trim();
+			return by === "link text" ? t === value : t.indexOf(value) >= 0;
+		});
+	}
+	fail("invalid argument", "unknown locator strategy " + by);
+}
+`
+
+// cdpWD implements WebDriver on top of a Chrome process driven through the
+// DevTools protocol.
+type cdpWD struct {
+	// lastActive is the time of the last call in Unix nanoseconds. It is
+	// accessed atomically and kept first for 64-bit alignment.
+	lastActive int64
+
+	conn     *cdpConn
+	cmd      *exec.Cmd
+	exited   chan struct{}
+	targetID string
+	browser  string
+	// debuggerAddress is the host:port of the DevTools endpoint.
+	debuggerAddress string
+
+	mu              sync.Mutex
+	pageLoadTimeout time.Duration
+	scriptTimeout   time.Duration
+	dialog          *cdpDialog
+	dialogOpened    chan struct{}
+	promptText      *string
+	logs            []Message
+	mouseX, mouseY  float64
+	modifiers       int
+	// styleSheets maps the ids of the stylesheets reported once the CSS
+	// domain is enabled to their URL.
+	styleSheets map[string]string
+
+	// hookSet holds the command hooks added with Session.Use.
+	hookSet
+}
+
+type cdpDialog struct {
+	Message       string `json:"message"`
+	Type          string `json:"type"`
+	DefaultPrompt string `json:"defaultPrompt"`
+}
+
+// errDialogOpened is returned by calls interrupted by a JavaScript dialog.
+var errDialogOpened = &Error{Err: "unexpected alert open", Message: "a JavaScript dialog is open"}
+
+// chromeBinary returns the path of the Chrome binary to launch.
+func chromeBinary(cfg *sessionConfig) (string, error) {
+	if cfg.chromeBinary != "" {
+		return cfg.chromeBinary, nil
+	}
+	if path := strings.TrimSpace(os.Getenv("CHROME_BIN")); path != "" {
+		return path, nil
+	}
+
+	var candidates []string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = []string{"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome"}
+	case "windows":
+		for _, env := range []string{"ProgramFiles", "ProgramFiles(x86)", "LocalAppData"} {
+			candidates = append(candidates, filepath.Join(os.Getenv(env), `Google\Chrome\Application\chrome.exe`))
+		}
+	}
+	candidates = append(candidates, "google-chrome", "google-chrome-stable", "chromium", "chromium-browser")
+	for _, c := range candidates {
+		if path, err := exec.LookPath(c); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("chrome not found, set CHROME_BIN or use WithChromeBinary")
+}
+
+// launchChrome starts a Chrome process for a session and connects to its
+// page.
+func launchChrome(cfg *sessionConfig) (*cdpWD, error) {
+	bin, err := chromeBinary(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := cfg.profile
+	if profile == "" {
+		if profile, err = CreateTempProfile(); err != nil {
+			return nil, err
+		}
+	}
+
+	defaults := []string{
+		"remote-debugging-port=0",
+		"user-data-dir=" + profile,
+		"no-first-run",
+		"no-default-browser-check",
+		fmt.Sprintf("window-size=%v,%v", cfg.width, cfg.height),
+		"disable-notifications",
+	}
+	if cfg.headless {
+		defaults = append(defaults, "headless")
+	}
+	// Without chromedriver there are no implicit switches to exclude, so
+	// excluded switches only drop the defaults above.
+	excluded := map[string]bool{}
+	for _, sw := range cfg.excludedSwitches {
+		name, _ := splitFlag(sw)
+		excluded[name] = true
+	}
+	var kept []string
+	for _, arg := range defaults {
+		if name, _ := splitFlag(arg); !excluded[name] {
+			kept = append(kept, arg)
+		}
+	}
+	args, err := mergeChromeArgs(kept, cfg.extraChromeArgs(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for i, arg := range args {
+		args[i] = "--" + arg
+	}
+
+	portFile := filepath.Join(profile, "DevToolsActivePort")
+	os.Remove(portFile)
+
+	cmd := exec.Command(bin, append(args, "about:blank")...)
+	if debugFlag {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+
+	wd, err := connectChrome(portFile, exited)
+	if err != nil {
+		cmd.Process.Kill()
+		return nil, err
+	}
+	wd.cmd = cmd
+	wd.exited = exited
+	return wd, nil
+}
+
+// connectChrome waits for Chrome to report its DevTools port and connects to
+// its first page.
+func connectChrome(portFile string, exited <-chan struct{}) (*cdpWD, error) {
+	var port int
+	deadline := time.Now().Add(30 * time.Second)
+	for port == 0 {
+		select {
+		case <-exited:
+			return nil, fmt.Errorf("chrome exited on startup")
+		default:
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for chrome to start")
+		}
+		if f, err := os.Open(portFile); err == nil {
+			sc := bufio.NewScanner(f)
+			if sc.Scan() {
+				port, _ = strconv.Atoi(strings.TrimSpace(sc.Text()))
+			}
+			f.Close()
+		}
+		if port == 0 {
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+
+	base := fmt.Sprintf("http://127.0.0.1:%d", port)
+	var targets []struct {
+		ID                   string `json:"id"`
+		Type                 string `json:"type"`
+		WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+	}
+	resp, err := http.Get(base + "/json/list")
+	if err != nil {
+		return nil, err
+	}
+	err = json.NewDecoder(resp.Body).Decode(&targets)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range targets {
+		if t.Type != "page" {
+			continue
+		}
+		ws, err := dialWebSocket(t.WebSocketDebuggerURL, 10*time.Second)
+		if err != nil {
+			return nil, err
+		}
+		wd := &cdpWD{
+			conn:            newCDPConn(ws),
+			targetID:        t.ID,
+			browser:         "chrome",
+			debuggerAddress: fmt.Sprintf("127.0.0.1:%d", port),
+			pageLoadTimeout: 5 * time.Minute,
+			scriptTimeout:   30 * time.Second,
+			dialogOpened:    make(chan struct{}),
+		}
+		if err := wd.init(); err != nil {
+			wd.conn.Close()
+			return nil, err
+		}
+		return wd, nil
+	}
+	return nil, fmt.Errorf("chrome has no page target")
+}
+
+// init enables the domains whose events the driver tracks.
+func (wd *cdpWD) init() error {
+	wd.conn.on("Page.javascriptDialogOpening", func(params json.RawMessage) {
+		d := new(cdpDialog)
+		if err := json.Unmarshal(params, d); err != nil {
+			return
+		}
+		wd.mu.Lock()
+		defer wd.mu.Unlock()
+		wd.dialog = d
+		wd.promptText = nil
+		close(wd.dialogOpened)
+	})
+	wd.conn.on("Page.javascriptDialogClosed", func(json.RawMessage) {
+		wd.mu.Lock()
+		defer wd.mu.Unlock()
+		wd.dialog = nil
+		wd.dialogOpened = make(chan struct{})
+	})
+	wd.conn.on("Runtime.consoleAPICalled", func(params json.RawMessage) {
+		ev := struct {
+			Type string
+			Args []struct {
+				Value       interface{}
+				Description string
+			}
+			Timestamp float64
+		}{}
+		if err := json.Unmarshal(params, &ev); err != nil {
+			return
+		}
+		var parts []string
+		for _, a := range ev.Args {
+			if a.Value != nil {
+				parts = append(parts, fmt.Sprint(a.Value))
+			} else {
+				parts = append(parts, a.Description)
+			}
+		}
+		level := Info
+		switch ev.Type {
+		case "error", "assert":
+			level = Severe
+		case "warning":
+			level = Warning
+		case "debug":
+			level = Debug
+		}
+		wd.addLog(ev.Timestamp, level, "console-api "+strings.Join(parts, " "))
+	})
+	wd.conn.on("Runtime.exceptionThrown", func(params json.RawMessage) {
+		ev := struct {
+			Timestamp        float64
+			ExceptionDetails cdpExceptionDetails
+		}{}
+		if err := json.Unmarshal(params, &ev); err != nil {
+			return
+		}
+		wd.addLog(ev.Timestamp, Severe, ev.ExceptionDetails.String())
+	})
+	wd.conn.on("CSS.styleSheetAdded", func(params json.RawMessage) {
+		ev := struct {
+			Header struct {
+				StyleSheetID string `json:"styleSheetId"`
+				SourceURL    string `json:"sourceURL"`
+			}
+		}{}
+		if err := json.Unmarshal(params, &ev); err != nil {
+			return
+		}
+		wd.mu.Lock()
+		defer wd.mu.Unlock()
+		if wd.styleSheets == nil {
+			wd.styleSheets = map[string]string{}
+		}
+		wd.styleSheets[ev.Header.StyleSheetID] = ev.Header.SourceURL
+	})
+	wd.conn.on("Log.entryAdded", func(params json.RawMessage) {
+		ev := struct {
+			Entry struct {
+				Level     string
+				Text      string
+				URL       string
+				Timestamp float64
+			}
+		}{}
+		if err := json.Unmarshal(params, &ev); err != nil {
+			return
+		}
+		level := Info
+		switch ev.Entry.Level {
+		case "error":
+			level = Severe
+		case "warning":
+			level = Warning
+		case "verbose":
+			level = Debug
+		}
+		wd.addLog(ev.Entry.Timestamp, level, strings.TrimSpace(ev.Entry.URL+" "+ev.Entry.Text))
+	})
+
+	for _, domain := range []string{"Page", "Runtime", "Log"} {
+		if _, err := wd.conn.call(domain+".enable", nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (wd *cdpWD) addLog(ms float64, level LogLevel, msg string) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.logs = append(wd.logs, Message{
+		Timestamp: time.Unix(0, int64(ms*float64(time.Millisecond))),
+		Level:     level,
+		Message:   msg,
+	})
+}
+
+type cdpExceptionDetails struct {
+	Text      string
+	Exception struct {
+		Description string
+	}
+}
+
+func (d *cdpExceptionDetails) String() string {
+	if d.Exception.Description != "" {
+		return d.Exception.Description
+	}
+	return d.Text
+}
+
+// call issues a command through the hooks of the session, see Session.Use.
+func (wd *cdpWD) call(method string, params interface{}, ret interface{}) error {
+	atomic.StoreInt64(&wd.lastActive, time.Now().UnixNano())
+
+	var (
+		result json.RawMessage
+		err    error
+	)
+	if hooks := wd.list(); len(hooks) == 0 {
+		result, err = wd.send(method, params)
+	} else {
+		cmd := &Command{Method: cdpCommand, URL: method}
+		if params != nil {
+			if cmd.Params, err = json.Marshal(params); err != nil {
+				return err
+			}
+		}
+		result, err = runHooks(hooks, cmd, func(cmd *Command) (json.RawMessage, error) {
+			if cmd.Params == nil {
+				return wd.send(cmd.URL, nil)
+			}
+			return wd.send(cmd.URL, json.RawMessage(cmd.Params))
+		})
+	}
+	if err != nil || ret == nil {
+		return err
+	}
+	return json.Unmarshal(result, ret)
+}
+
+// send issues a command, failing with errDialogOpened if a JavaScript dialog
+// opens before it completes, since the page blocks until it is closed.
+func (wd *cdpWD) send(method string, params interface{}) (json.RawMessage, error) {
+	wd.mu.Lock()
+	opened := wd.dialogOpened
+	wd.mu.Unlock()
+
+	ch, err := wd.conn.send(method, params)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case reply := <-ch:
+		if reply.Err != nil {
+			return nil, reply.Err
+		}
+		return reply.Result, nil
+	case <-opened:
+		return nil, errDialogOpened
+	}
+}
+
+// lastActivity returns the time the last call was sent, or the zero time if
+// none was.
+func (wd *cdpWD) lastActivity() time.Time {
+	if ns := atomic.LoadInt64(&wd.lastActive); ns != 0 {
+		return time.Unix(0, ns)
+	}
+	return time.Time{}
+}
+
+// script runs a WebDriver script in the page. The script is the body of a
+// function receiving args; if async, the function receives a callback as
+// its last argument to report the result.
+func (wd *cdpWD) script(script string, args []interface{}, async bool, timeout time.Duration) (json.RawMessage, error) {
+	if args == nil {
+		args = []interface{}{}
+	}
+	argsJSON, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+
+	var body string
+	if async {
+		body = fmt.Sprintf(`return new Promise(function(resolve, reject) {
+	var args = revive(%s);
+	args.push(function(v) { resolve(v); });
+	setTimeout(function() { reject(new Error("script timeout: result was not received in %d ms")); }, %d);
+	(function() { %s }).apply(window, args);
+}).then(function(v) { return wrap(v); });`, argsJSON, timeout/time.Millisecond, timeout/time.Millisecond, script)
+	} else {
+		body = fmt.Sprintf(`var args = revive(%s);
+var ret = (function() { %s }).apply(window, args);
+if (ret && typeof ret.then === "function") {
+	return ret.then(function(v) { return wrap(v); });
+}
+return wrap(ret);`, argsJSON, script)
+	}
+
+	reply := struct {
+		Result struct {
+			Value json.RawMessage
+		}
+		ExceptionDetails *cdpExceptionDetails
+	}{}
+	err = wd.call("Runtime.evaluate", map[string]interface{}{
+		"expression":    "(function() {" + cdpPrelude + body + "\n})()",
+		"returnByValue": true,
+		"awaitPromise":  true,
+		"userGesture":   true,
+	}, &reply)
+	if err != nil {
+		return nil, err
+	}
+	if reply.ExceptionDetails != nil {
+		return nil, scriptError(reply.ExceptionDetails.String())
+	}
+	if len(reply.Result.Value) == 0 {
+		return json.RawMessage("null"), nil
+	}
+	return reply.Result.Value, nil
+}
+
+// w3cErrors are the WebDriver error codes raised by the page-side helpers.
+var w3cErrors = []string{
+	"stale element reference",
+	"no such element",
+	"element click intercepted",
+	"element not interactable",
+	"invalid selector",
+	"invalid argument",
+	"script timeout",
+}
+
+// scriptError converts an exception thrown by a script into the error a
+// WebDriver server would return.
+func scriptError(desc string) error {
+	msg := strings.TrimPrefix(desc, "Error: ")
+	if i := strings.Index(msg, "\n"); i >= 0 {
+		msg = msg[:i]
+	}
+	for _, code := range w3cErrors {
+		if strings.HasPrefix(msg, code+": ") {
+			return &Error{Err: code, Message: strings.TrimPrefix(msg, code+": "), HTTPCode: http.StatusBadRequest}
+		}
+	}
+	return &Error{Err: "javascript error", Message: desc, HTTPCode: http.StatusInternalServerError}
+}
+
+func (wd *cdpWD) eval(script string, args []interface{}, ret interface{}) error {
+	data, err := wd.script(script, args, false, 0)
+	if err != nil {
+		return err
+	}
+	if ret == nil {
+		return nil
+	}
+	return json.Unmarshal(data, ret)
+}
+
+func (wd *cdpWD) Status() (*Status, error) {
+	select {
+	case <-wd.conn.closed:
+		return &Status{Ready: false, Message: "devtools connection closed"}, nil
+	default:
+		return &Status{Ready: true, Message: "ready"}, nil
+	}
+}
+
+func (wd *cdpWD) NewSession() (string, error) {
+	return "", fmt.Errorf("NewSession is not supported by the cdp driver")
+}
+
+func (wd *cdpWD) SessionId() string {
+	return wd.targetID
+}
+
+func (wd *cdpWD) SessionID() string {
+	return wd.targetID
+}
+
+func (wd *cdpWD) SwitchSession(sessionID string) error {
+	return fmt.Errorf("SwitchSession is not supported by the cdp driver")
+}
+
+func (wd *cdpWD) Capabilities() (Capabilities, error) {
+	return Capabilities{"browserName": wd.browser}, nil
+}
+
+func (wd *cdpWD) SetAsyncScriptTimeout(timeout time.Duration) error {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.scriptTimeout = timeout
+	return nil
+}
+
+func (wd *cdpWD) SetImplicitWaitTimeout(timeout time.Duration) error {
+	// The high-level API polls instead of waiting implicitly.
+	return nil
+}
+
+func (wd *cdpWD) SetPageLoadTimeout(timeout time.Duration) error {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.pageLoadTimeout = timeout
+	return nil
+}
+
+func (wd *cdpWD) Quit() error {
+	// Browser.close lets Chrome flush the profile; the reply may be lost
+	// with the connection.
+	if ch, err := wd.conn.send("Browser.close", nil); err == nil {
+		select {
+		case <-ch:
+		case <-time.After(5 * time.Second):
+		}
+	}
+	wd.conn.Close()
+
+	if wd.cmd == nil {
+		return nil
+	}
+	select {
+	case <-wd.exited:
+	case <-time.After(5 * time.Second):
+		return wd.cmd.Process.Kill()
+	}
+	return nil
+}
+
+func (wd *cdpWD) CurrentWindowHandle() (string, error) {
+	return wd.targetID, nil
+}
+
+func (wd *cdpWD) WindowHandles() ([]string, error) {
+	return []string{wd.targetID}, nil
+}
+
+func (wd *cdpWD) CurrentURL() (string, error) {
+	var u string
+	err := wd.eval("return window.location.href;", nil, &u)
+	return u, err
+}
+
+func (wd *cdpWD) Title() (string, error) {
+	var t string
+	err := wd.eval("return document.title;", nil, &t)
+	return t, err
+}
+
+func (wd *cdpWD) PageSource() (string, error) {
+	var src string
+	err := wd.eval("return document.documentElement ? document.documentElement.outerHTML : '';", nil, &src)
+	return src, err
+}
+
+func (wd *cdpWD) Close() error {
+	return wd.Quit()
+}
+
+func (wd *cdpWD) SwitchFrame(frame interface{}) error {
+	if frame == nil || frame == "" {
+		return nil
+	}
+	return fmt.Errorf("frames are not supported by the cdp driver")
+}
+
+func (wd *cdpWD) SwitchWindow(name string) error {
+	if name == wd.targetID {
+		return nil
+	}
+	return fmt.Errorf("multiple windows are not supported by the cdp driver")
+}
+
+func (wd *cdpWD) CloseWindow(name string) error {
+	if name != "" && name != wd.targetID {
+		return fmt.Errorf("no such window %q", name)
+	}
+	return wd.Quit()
+}
+
+func (wd *cdpWD) setWindowBounds(bounds map[string]interface{}) error {
+	win := struct{ WindowID int }{}
+	if err := wd.call("Browser.getWindowForTarget", map[string]interface{}{"targetId": wd.targetID}, &win); err != nil {
+		return err
+	}
+	return wd.call("Browser.setWindowBounds", map[string]interface{}{
+		"windowId": win.WindowID,
+		"bounds":   bounds,
+	}, nil)
+}
+
+func (wd *cdpWD) MaximizeWindow(name string) error {
+	return wd.setWindowBounds(map[string]interface{}{"windowState": "maximized"})
+}
+
+func (wd *cdpWD) ResizeWindow(name string, width, height int) error {
+	if err := wd.setWindowBounds(map[string]interface{}{"windowState": "normal"}); err != nil {
+		return err
+	}
+	return wd.setWindowBounds(map[string]interface{}{"width": width, "height": height})
+}
+
+// waitLoad waits until the document has loaded and, after markNavigation,
+// was replaced.
+func (wd *cdpWD) waitLoad() error {
+	wd.mu.Lock()
+	timeout := wd.pageLoadTimeout
+	wd.mu.Unlock()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		var state string
+		err := wd.eval(`return window.__webdriverNavigating ? "navigating" : document.readyState;`, nil, &state)
+		if err == errDialogOpened {
+			// The page is blocked on the dialog, as with a WebDriver server.
+			return nil
+		}
+		if err == nil && state == "complete" {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return &Error{Err: "timeout", Message: fmt.Sprintf("page did not load in %v", timeout)}
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// markNavigation tags the current document so that waitLoad can tell when it
+// was replaced.
+func (wd *cdpWD) markNavigation() error {
+	return wd.eval(`window.__webdriverNavigating = true;`, nil, nil)
+}
+
+func (wd *cdpWD) Get(url string) error {
+	if err := wd.markNavigation(); err != nil {
+		return err
+	}
+	reply := struct {
+		LoaderID  string
+		ErrorText string
+	}{}
+	if err := wd.call("Page.navigate", map[string]interface{}{"url": url}, &reply); err != nil {
+		return err
+	}
+	if reply.ErrorText != "" {
+		return &Error{Err: "unknown error", Message: reply.ErrorText}
+	}
+	if reply.LoaderID == "" {
+		// Same-document navigation keeps the document.
+		return wd.eval(`delete window.__webdriverNavigating;`, nil, nil)
+	}
+	return wd.waitLoad()
+}
+
+func (wd *cdpWD) historyGo(delta int) error {
+	history := struct {
+		CurrentIndex int
+		Entries      []struct{ ID int }
+	}{}
+	if err := wd.call("Page.getNavigationHistory", nil, &history); err != nil {
+		return err
+	}
+	i := history.CurrentIndex + delta
+	if i < 0 || i >= len(history.Entries) {
+		return nil
+	}
+	if err := wd.call("Page.navigateToHistoryEntry", map[string]interface{}{"entryId": history.Entries[i].ID}, nil); err != nil {
+		return err
+	}
+	return wd.waitLoad()
+}
+
+func (wd *cdpWD) Forward() error {
+	return wd.historyGo(1)
+}
+
+func (wd *cdpWD) Back() error {
+	return wd.historyGo(-1)
+}
+
+func (wd *cdpWD) Refresh() error {
+	if err := wd.markNavigation(); err != nil {
+		return err
+	}
+	if err := wd.call("Page.reload", nil, nil); err != nil {
+		return err
+	}
+	return wd.waitLoad()
+}
+
+func (wd *cdpWD) find(root *cdpWE, by, value string) ([]WebElement, error) {
+	var args []interface{}
+	if root != nil {
+		args = []interface{}{root}
+	} else {
+		args = []interface{}{nil}
+	}
+	args = append(args, by, value)
+
+	data, err := wd.script("return find(arguments[0], arguments[1], arguments[2]);", args, false, 0)
+	if err != nil {
+		return nil, err
+	}
+	return wd.decodeRefs(data)
+}
+
+func (wd *cdpWD) FindElement(by, value string) (WebElement, error) {
+	elems, err := wd.find(nil, by, value)
+	if err != nil {
+		return nil, err
+	}
+	if len(elems) == 0 {
+		return nil, &Error{Err: "no such element", Message: fmt.Sprintf("no element matches %s %q", by, value), HTTPCode: http.StatusNotFound}
+	}
+	return elems[0], nil
+}
+
+func (wd *cdpWD) FindElements(by, value string) ([]WebElement, error) {
+	return wd.find(nil, by, value)
+}
+
+func (wd *cdpWD) ActiveElement() (WebElement, error) {
+	data, err := wd.script("return document.activeElement;", nil, false, 0)
+	if err != nil {
+		return nil, err
+	}
+	return wd.decodeRef(data)
+}
+
+// DecodeElement decodes an element in the reply format of a WebDriver server,
+// as returned by ExecuteScriptRaw, see rawValue.
+func (wd *cdpWD) DecodeElement(data []byte) (WebElement, error) {
+	reply := struct{ Value json.RawMessage }{}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return nil, err
+	}
+	return wd.decodeRef(reply.Value)
+}
+
+// DecodeElements is DecodeElement for an array of elements.
+func (wd *cdpWD) DecodeElements(data []byte) ([]WebElement, error) {
+	reply := struct{ Value json.RawMessage }{}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return nil, err
+	}
+	return wd.decodeRefs(reply.Value)
+}
+
+// decodeRef decodes an element reference of a script result.
+func (wd *cdpWD) decodeRef(data []byte) (WebElement, error) {
+	ref := map[string]string{}
+	if err := json.Unmarshal(data, &ref); err != nil {
+		return nil, err
+	}
+	id, ok := ref[webElementIdentifier]
+	if !ok {
+		return nil, fmt.Errorf("invalid element returned: %s", data)
+	}
+	return &cdpWE{parent: wd, id: id}, nil
+}
+
+// decodeRefs decodes an array of element references of a script result.
+func (wd *cdpWD) decodeRefs(data []byte) ([]WebElement, error) {
+	var refs []json.RawMessage
+	if err := json.Unmarshal(data, &refs); err != nil {
+		return nil, err
+	}
+	elems := make([]WebElement, len(refs))
+	for i, ref := range refs {
+		elem, err := wd.decodeRef(ref)
+		if err != nil {
+			return nil, err
+		}
+		elems[i] = elem
+	}
+	return elems, nil
+}
+
+type cdpCookie struct {
+	Name    string  `json:"name"`
+	Value   string  `json:"value"`
+	Domain  string  `json:"domain"`
+	Path    string  `json:"path"`
+	Secure  bool    `json:"secure"`
+	Expires float64 `json:"expires"`
+}
+
+func (wd *cdpWD) GetCookies() ([]Cookie, error) {
+	reply := struct{ Cookies []cdpCookie }{}
+	if err := wd.call("Network.getCookies", nil, &reply); err != nil {
+		return nil, err
+	}
+	cookies := make([]Cookie, len(reply.Cookies))
+	for i, c := range reply.Cookies {
+		cookies[i] = Cookie{Name: c.Name, Value: c.Value, Domain: c.Domain, Path: c.Path, Secure: c.Secure}
+		if c.Expires > 0 {
+			cookies[i].Expiry = uint(c.Expires)
+		}
+	}
+	return cookies, nil
+}
+
+func (wd *cdpWD) GetCookie(name string) (Cookie, error) {
+	cookies, err := wd.GetCookies()
+	if err != nil {
+		return Cookie{}, err
+	}
+	for _, c := range cookies {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	return Cookie{}, &Error{Err: "no such cookie", Message: fmt.Sprintf("cookie %q not found", name), HTTPCode: http.StatusNotFound}
+}
+
+func (wd *cdpWD) AddCookie(cookie *Cookie) error {
+	u, err := wd.CurrentURL()
+	if err != nil {
+		return err
+	}
+	params := map[string]interface{}{
+		"name":   cookie.Name,
+		"value":  cookie.Value,
+		"url":    u,
+		"secure": cookie.Secure,
+	}
+	if cookie.Domain != "" {
+		params["domain"] = cookie.Domain
+	}
+	if cookie.Path != "" {
+		params["path"] = cookie.Path
+	}
+	if cookie.Expiry > 0 {
+		params["expires"] = cookie.Expiry
+	}
+	return wd.call("Network.setCookie", params, nil)
+}
+
+func (wd *cdpWD) DeleteCookie(name string) error {
+	u, err := wd.CurrentURL()
+	if err != nil {
+		return err
+	}
+	return wd.call("Network.deleteCookies", map[string]interface{}{"name": name, "url": u}, nil)
+}
+
+func (wd *cdpWD) DeleteAllCookies() error {
+	cookies, err := wd.GetCookies()
+	if err != nil {
+		return err
+	}
+	for _, c := range cookies {
+		params := map[string]interface{}{"name": c.Name, "domain": c.Domain, "path": c.Path}
+		if err := wd.call("Network.deleteCookies", params, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var cdpButtons = []string{"left", "middle", "right"}
+
+func (wd *cdpWD) mouse(typ string, button, clickCount int) error {
+	wd.mu.Lock()
+	params := map[string]interface{}{
+		"type":       typ,
+		"x":          wd.mouseX,
+		"y":          wd.mouseY,
+		"modifiers":  wd.modifiers,
+		"clickCount": clickCount,
+	}
+	wd.mu.Unlock()
+	if typ != "mouseMoved" {
+		if button < 0 || button >= len(cdpButtons) {
+			return fmt.Errorf("invalid mouse button %d", button)
+		}
+		params["button"] = cdpButtons[button]
+	}
+
+	err := wd.call("Input.dispatchMouseEvent", params, nil)
+	if err == errDialogOpened {
+		// The event opened a dialog, which is not an error of the event.
+		return nil
+	}
+	return err
+}
+
+func (wd *cdpWD) moveMouse(x, y float64) error {
+	wd.mu.Lock()
+	wd.mouseX, wd.mouseY = x, y
+	wd.mu.Unlock()
+	return wd.mouse("mouseMoved", 0, 0)
+}
+
+func (wd *cdpWD) Click(button int) error {
+	if err := wd.mouse("mousePressed", button, 1); err != nil {
+		return err
+	}
+	return wd.mouse("mouseReleased", button, 1)
+}
+
+func (wd *cdpWD) DoubleClick() error {
+	for count := 1; count <= 2; count++ {
+		if err := wd.mouse("mousePressed", 0, count); err != nil {
+			return err
+		}
+		if err := wd.mouse("mouseReleased", 0, count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (wd *cdpWD) ButtonDown() error {
+	return wd.mouse("mousePressed", 0, 1)
+}
+
+func (wd *cdpWD) ButtonUp() error {
+	return wd.mouse("mouseReleased", 0, 1)
+}
+
+// cdpKey describes a WebDriver special key for Input.dispatchKeyEvent.
+type cdpKey struct {
+	key      string
+	code     string
+	keyCode  int
+	text     string
+	modifier int
+}
+
+// cdpKeys maps the WebDriver special keys, see the *Key constants.
+var cdpKeys = map[rune]cdpKey{
+	'\ue003': {key: "Backspace", code: "Backspace", keyCode: 8},
+	'\ue004': {key: "Tab", code: "Tab", keyCode: 9},
+	'\ue006': {key: "Enter", code: "Enter", keyCode: 13, text: "\r"},
+	'\ue007': {key: "Enter", code: "Enter", keyCode: 13, text: "\r"},
+	'\ue008': {key: "Shift", code: "ShiftLeft", keyCode: 16, modifier: 8},
+	'\ue009': {key: "Control", code: "ControlLeft", keyCode: 17, modifier: 2},
+	'\ue00a': {key: "Alt", code: "AltLeft", keyCode: 18, modifier: 1},
+	'\ue00b': {key: "Pause", code: "Pause", keyCode: 19},
+	'\ue00c': {key: "Escape", code: "Escape", keyCode: 27},
+	'\ue00d': {key: " ", code: "Space", keyCode: 32, text: " "},
+	'\ue00e': {key: "PageUp", code: "PageUp", keyCode: 33},
+	'\ue00f': {key: "PageDown", code: "PageDown", keyCode: 34},
+	'\ue010': {key: "End", code: "End", keyCode: 35},
+	'\ue011': {key: "Home", code: "Home", keyCode: 36},
+	'\ue012': {key: "ArrowLeft", code: "ArrowLeft", keyCode: 37},
+	'\ue013': {key: "ArrowUp", code: "ArrowUp", keyCode: 38},
+	'\ue014': {key: "ArrowRight", code: "ArrowRight", keyCode: 39},
+	'\ue015': {key: "ArrowDown", code: "ArrowDown", keyCode: 40},
+	'\ue016': {key: "Insert", code: "Insert", keyCode: 45},
+	'\ue017': {key: "Delete", code: "Delete", keyCode: 46},
+	'\ue03d': {key: "Meta", code: "MetaLeft", keyCode: 91, modifier: 4},
+}
+
+func init() {
+	for i := 0; i < 12; i++ {
+		name := fmt.Sprintf("F%d", i+1)
+		cdpKeys['\ue031'+rune(i)] = cdpKey{key: name, code: name, keyCode: 112 + i}
+	}
+}
+
+// key dispatches a key press or release of r.
+func (wd *cdpWD) key(r rune, down bool) error {
+	k, special := cdpKeys[r]
+	if !special {
+		s := string(r)
+		k = cdpKey{key: s, text: s}
+	}
+
+	wd.mu.Lock()
+	if k.modifier != 0 {
+		if down {
+			wd.modifiers |= k.modifier
+		} else {
+			wd.modifiers &^= k.modifier
+		}
+	}
+	params := map[string]interface{}{
+		"key":       k.key,
+		"modifiers": wd.modifiers,
+	}
+	wd.mu.Unlock()
+	if k.code != "" {
+		params["code"] = k.code
+	}
+	if k.keyCode != 0 {
+		params["windowsVirtualKeyCode"] = k.keyCode
+	}
+	if down {
+		params["type"] = "rawKeyDown"
+		if k.text != "" {
+			params["type"] = "keyDown"
+			params["text"] = k.text
+			params["unmodifiedText"] = k.text
+		}
+	} else {
+		params["type"] = "keyUp"
+	}
+
+	err := wd.call("Input.dispatchKeyEvent", params, nil)
+	if err == errDialogOpened {
+		return nil
+	}
+	return err
+}
+
+// typeKeys types keys as WebDriver's element send keys does: modifier keys
+// stay pressed until the NullKey or the end of the sequence.
+func (wd *cdpWD) typeKeys(keys string) error {
+	var held []rune
+	release := func() error {
+		for _, r := range held {
+			if err := wd.key(r, false); err != nil {
+				return err
+			}
+		}
+		held = nil
+		return nil
+	}
+
+	for _, r := range keys {
+		if r == '\ue000' {
+			if err := release(); err != nil {
+				return err
+			}
+			continue
+		}
+		if cdpKeys[r].modifier != 0 {
+			if err := wd.key(r, true); err != nil {
+				return err
+			}
+			held = append(held, r)
+			continue
+		}
+		if err := wd.key(r, true); err != nil {
+			return err
+		}
+		if err := wd.key(r, false); err != nil {
+			return err
+		}
+	}
+	return release()
+}
+
+func (wd *cdpWD) SendModifier(modifier string, isDown bool) error {
+	for _, r := range modifier {
+		if err := wd.key(r, isDown); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (wd *cdpWD) KeyDown(keys string) error {
+	for _, r := range keys {
+		if err := wd.key(r, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (wd *cdpWD) KeyUp(keys string) error {
+	for _, r := range keys {
+		if err := wd.key(r, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (wd *cdpWD) screenshot(clip map[string]interface{}) ([]byte, error) {
+	params := map[string]interface{}{"format": "png"}
+	if clip != nil {
+		params["clip"] = clip
+		params["captureBeyondViewport"] = true
+	}
+	reply := struct{ Data string }{}
+	if err := wd.call("Page.captureScreenshot", params, &reply); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(reply.Data)
+}
+
+func (wd *cdpWD) Screenshot() ([]byte, error) {
+	return wd.screenshot(nil)
+}
+
+func (wd *cdpWD) Log(typ LogType) ([]Message, error) {
+	if typ != Browser {
+		return nil, fmt.Errorf("log type %q is not supported by the cdp driver", typ)
+	}
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	logs := wd.logs
+	wd.logs = nil
+	return logs, nil
+}
+
+func (wd *cdpWD) handleDialog(accept bool) error {
+	wd.mu.Lock()
+	dialog := wd.dialog
+	params := map[string]interface{}{"accept": accept}
+	if wd.promptText != nil {
+		params["promptText"] = *wd.promptText
+	}
+	wd.mu.Unlock()
+	if dialog == nil {
+		return &Error{Err: "no such alert", Message: "no JavaScript dialog is open", HTTPCode: http.StatusNotFound}
+	}
+	_, err := wd.conn.call("Page.handleJavaScriptDialog", params)
+	return err
+}
+
+func (wd *cdpWD) DismissAlert() error {
+	return wd.handleDialog(false)
+}
+
+func (wd *cdpWD) AcceptAlert() error {
+	return wd.handleDialog(true)
+}
+
+func (wd *cdpWD) AlertText() (string, error) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	if wd.dialog == nil {
+		return "", &Error{Err: "no such alert", Message: "no JavaScript dialog is open", HTTPCode: http.StatusNotFound}
+	}
+	return wd.dialog.Message, nil
+}
+
+func (wd *cdpWD) SetAlertText(text string) error {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	if wd.dialog == nil {
+		return &Error{Err: "no such alert", Message: "no JavaScript dialog is open", HTTPCode: http.StatusNotFound}
+	}
+	wd.promptText = &text
+	return nil
+}
+
+func (wd *cdpWD) ExecuteScript(script string, args []interface{}) (interface{}, error) {
+	data, err := wd.script(script, args, false, 0)
+	if err != nil {
+		return nil, err
+	}
+	var ret interface{}
+	err = json.Unmarshal(data, &ret)
+	return ret, err
+}
+
+func (wd *cdpWD) ExecuteScriptAsync(script string, args []interface{}) (interface{}, error) {
+	data, err := wd.ExecuteScriptAsyncRaw(script, args)
+	if err != nil {
+		return nil, err
+	}
+	reply := struct{ Value interface{} }{}
+	err = json.Unmarshal(data, &reply)
+	return reply.Value, err
+}
+
+// rawValue wraps a result in the reply format of a WebDriver server, which
+// the Raw methods return.
+func rawValue(data json.RawMessage) []byte {
+	return []byte(`{"value":` + string(data) + `}`)
+}
+
+func (wd *cdpWD) ExecuteScriptRaw(script string, args []interface{}) ([]byte, error) {
+	data, err := wd.script(script, args, false, 0)
+	if err != nil {
+		return nil, err
+	}
+	return rawValue(data), nil
+}
+
+func (wd *cdpWD) ExecuteScriptAsyncRaw(script string, args []interface{}) ([]byte, error) {
+	wd.mu.Lock()
+	timeout := wd.scriptTimeout
+	wd.mu.Unlock()
+
+	data, err := wd.script(script, args, true, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return rawValue(data), nil
+}
+
+func (wd *cdpWD) ExecuteCDPRaw(cmd string, params map[string]interface{}) ([]byte, error) {
+	var ret json.RawMessage
+	if err := wd.call(cmd, params, &ret); err != nil {
+		return nil, err
+	}
+	if len(ret) == 0 {
+		ret = json.RawMessage("{}")
+	}
+	return rawValue(ret), nil
+}
+
+func (wd *cdpWD) ExecuteCDP(cmd string, params map[string]interface{}) (interface{}, error) {
+	data, err := wd.ExecuteCDPRaw(cmd, params)
+	if err != nil {
+		return nil, err
+	}
+	reply := struct{ Value interface{} }{}
+	err = json.Unmarshal(data, &reply)
+	return reply.Value, err
+}
+
+func (wd *cdpWD) WaitWithTimeoutAndInterval(condition Condition, timeout, interval time.Duration) error {
+	startTime := time.Now()
+
+	for {
+		done, err := condition(wd)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		if elapsed := time.Since(startTime); elapsed > timeout {
+			return fmt.Errorf("timeout after %v", elapsed)
+		}
+		time.Sleep(interval)
+	}
+}
+
+func (wd *cdpWD) WaitWithTimeout(condition Condition, timeout time.Duration) error {
+	return wd.WaitWithTimeoutAndInterval(condition, timeout, DefaultWaitInterval)
+}
+
+func (wd *cdpWD) Wait(condition Condition) error {
+	return wd.WaitWithTimeoutAndInterval(condition, DefaultWaitTimeout, DefaultWaitInterval)
+}
+
+// cdpWE implements WebElement for the cdp driver.
+type cdpWE struct {
+	parent *cdpWD
+	id     string
+}
+
+func (elem *cdpWE) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{webElementIdentifier: elem.id})
+}
+
+func (elem *cdpWE) eval(script string, ret interface{}, args ...interface{}) error {
+	return elem.parent.eval(script, append([]interface{}{elem}, args...), ret)
+}
+
+// center scrolls the element into view and returns the viewport coordinates
+// of its center, failing if another element would receive a click there.
+func (elem *cdpWE) center() (float64, float64, error) {
+	var p []float64
+	err := elem.eval(`var e = arguments[0];
+e.scrollIntoView({block: "center", inline: "center"});
+var rects = e.getClientRects();
+if (!rects.length) {
+	fail("element not interactable", "element has no size and location");
+}
+var r = rects[0];
+var x = r.left + r.width / 2, y = r.top + r.height / 2;
+var hit = document.elementFromPoint(x, y);
+if (hit && hit !== e && !e.contains(hit)) {
+	fail("element click intercepted", "element " + e.tagName.toLowerCase() + " is not clickable, other element would receive the click: " + hit.outerHTML.slice(0, 200));
+}
+return [x, y];`, &p)
+	if err != nil {
+		return 0, 0, err
+	}
+	return p[0], p[1], nil
+}
+
+func (elem *cdpWE) Click() error {
+	x, y, err := elem.center()
+	if err != nil {
+		return err
+	}
+	if err := elem.parent.moveMouse(x, y); err != nil {
+		return err
+	}
+	return elem.parent.Click(0)
+}
+
+func (elem *cdpWE) SendKeys(keys string) error {
+	if err := elem.eval(`var e = arguments[0];
+e.focus();
+if (document.activeElement !== e && !e.isContentEditable) {
+	fail("element not interactable", "element is not focusable");
+}
+if (typeof e.value === "string" && e.setSelectionRange) {
+	try { e.setSelectionRange(e.value.length, e.value.length); } catch (err) {}
+}`, nil); err != nil {
+		return err
+	}
+	return elem.parent.typeKeys(keys)
+}
+
+func (elem *cdpWE) Submit() error {
+	return elem.eval(`var e = arguments[0];
+var form = e.form || e.closest("form");
+if (!form) {
+	fail("invalid argument", "element is not in a form");
+}
+form.requestSubmit ? form.requestSubmit() : form.submit();`, nil)
+}
+
+func (elem *cdpWE) Clear() error {
+	return elem.eval(`var e = arguments[0];
+if (e.isContentEditable) {
+	e.innerHTML = "";
+} else {
+	e.value = "";
+}
+e.dispatchEvent(new Event("input", {bubbles: true}));
+e.dispatchEvent(new Event("change", {bubbles: true}));`, nil)
+}
+
+func (elem *cdpWE) MoveTo(xOffset, yOffset int) error {
+	var p []float64
+	if err := elem.eval(`var e = arguments[0];
+e.scrollIntoView({block: "center", inline: "center"});
+var r = e.getBoundingClientRect();
+return [r.left, r.top];`, &p); err != nil {
+		return err
+	}
+	return elem.parent.moveMouse(p[0]+float64(xOffset), p[1]+float64(yOffset))
+}
+
+func (elem *cdpWE) FindElement(by, value string) (WebElement, error) {
+	elems, err := elem.parent.find(elem, by, value)
+	if err != nil {
+		return nil, err
+	}
+	if len(elems) == 0 {
+		return nil, &Error{Err: "no such element", Message: fmt.Sprintf("no element matches %s %q", by, value), HTTPCode: http.StatusNotFound}
+	}
+	return elems[0], nil
+}
+
+func (elem *cdpWE) FindElements(by, value string) ([]WebElement, error) {
+	return elem.parent.find(elem, by, value)
+}
+
+func (elem *cdpWE) TagName() (string, error) {
+	var t string
+	err := elem.eval("return arguments[0].tagName.toLowerCase();", &t)
+	return t, err
+}
+
+func (elem *cdpWE) Text() (string, error) {
+	var t string
+	err := elem.eval("var e = arguments[0]; return e.innerText !== undefined ? e.innerText : e.textContent;", &t)
+	return t, err
+}
+
+func (elem *cdpWE) IsSelected() (bool, error) {
+	var b bool
+	err := elem.eval("var e = arguments[0]; return !!(e.checked || e.selected);", &b)
+	return b, err
+}
+
+func (elem *cdpWE) IsEnabled() (bool, error) {
+	var b bool
+	err := elem.eval("return !arguments[0].disabled;", &b)
+	return b, err
+}
+
+func (elem *cdpWE) IsDisplayed() (bool, error) {
+	var b bool
+	err := elem.eval(`var e = arguments[0];
+if (e.checkVisibility) {
+	return e.checkVisibility({checkOpacity: true, checkVisibilityCSS: true});
+}
+var s = window.getComputedStyle(e);
+return e.getClientRects().length > 0 && s.visibility !== "hidden" && s.opacity !== "0";`, &b)
+	return b, err
+}
+
+func (elem *cdpWE) GetAttribute(name string) (string, error) {
+	var v *string
+	if err := elem.eval(`var e = arguments[0], name = arguments[1];
+var v = e.getAttribute(name);
+if (v === null && name in e && typeof e[name] !== "object" && typeof e[name] !== "function") {
+	v = String(e[name]);
+}
+return v;`, &v, name); err != nil {
+		return "", err
+	}
+	if v == nil {
+		return "", fmt.Errorf("nil return value")
+	}
+	return *v, nil
+}
+
+func (elem *cdpWE) Location() (*Point, error) {
+	var p []float64
+	if err := elem.eval("var r = arguments[0].getBoundingClientRect(); return [r.left + window.scrollX, r.top + window.scrollY];", &p); err != nil {
+		return nil, err
+	}
+	return &Point{X: round(p[0]), Y: round(p[1])}, nil
+}
+
+func (elem *cdpWE) LocationInView() (*Point, error) {
+	var p []float64
+	if err := elem.eval(`var e = arguments[0];
+e.scrollIntoView({block: "center", inline: "center"});
+var r = e.getBoundingClientRect();
+return [r.left, r.top];`, &p); err != nil {
+		return nil, err
+	}
+	return &Point{X: round(p[0]), Y: round(p[1])}, nil
+}
+
+func (elem *cdpWE) Size() (*Size, error) {
+	var s []float64
+	if err := elem.eval("var r = arguments[0].getBoundingClientRect(); return [r.width, r.height];", &s); err != nil {
+		return nil, err
+	}
+	return &Size{Width: round(s[0]), Height: round(s[1])}, nil
+}
+
+func (elem *cdpWE) CSSProperty(name string) (string, error) {
+	var v string
+	err := elem.eval("return window.getComputedStyle(arguments[0]).getPropertyValue(arguments[1]);", &v, name)
+	return v, err
+}
+
+func (elem *cdpWE) Screenshot(scroll bool) ([]byte, error) {
+	script := "var r = arguments[0].getBoundingClientRect(); return [r.left + window.scrollX, r.top + window.scrollY, r.width, r.height];"
+	if scroll {
+		script = `arguments[0].scrollIntoView({block: "center", inline: "center"}); ` + script
+	}
+	var r []float64
+	if err := elem.eval(script, &r); err != nil {
+		return nil, err
+	}
+	if r[2] == 0 || r[3] == 0 {
+		return nil, &Error{Err: "element not interactable", Message: "element has no size"}
+	}
+	return elem.parent.screenshot(map[string]interface{}{
+		"x":      r[0],
+		"y":      r[1],
+		"width":  math.Ceil(r[2]),
+		"height": math.Ceil(r[3]),
+		"scale":  1,
+	})
+}
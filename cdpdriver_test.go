@@ -0,0 +1,39 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDecodeElement(t *testing.T) {
+	ref := json.RawMessage(`{"` + webElementIdentifier + `": "e1"}`)
+	for _, wd := range []WebDriver{&cdpWD{}, &remoteWD{}} {
+		elem, err := wd.DecodeElement(rawValue(ref))
+		if err != nil {
+			t.Errorf("%T.DecodeElement(reply) returned error: %v", wd, err)
+		} else if id := elementID(elem); id != "e1" {
+			t.Errorf("%T.DecodeElement(reply) = %q, want e1", wd, id)
+		}
+		if _, err := wd.DecodeElement(ref); err == nil {
+			t.Errorf("%T.DecodeElement accepted a bare reference", wd)
+		}
+		if _, err := wd.DecodeElement(rawValue(json.RawMessage("null"))); err == nil {
+			t.Errorf("%T.DecodeElement accepted null", wd)
+		}
+
+		elems, err := wd.DecodeElements(rawValue(json.RawMessage("[" + string(ref) + "]")))
+		if err != nil || len(elems) != 1 || elementID(elems[0]) != "e1" {
+			t.Errorf("%T.DecodeElements(reply) = %v, %v, want [e1]", wd, elems, err)
+		}
+	}
+}
+
+func elementID(elem WebElement) string {
+	switch e := elem.(type) {
+	case *cdpWE:
+		return e.id
+	case *remoteWE:
+		return e.id
+	}
+	return ""
+}
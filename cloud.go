@@ -0,0 +1,148 @@
+package webdriver
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+)
+
+// CloudProvider identifies a hosted browser grid supported by WithCloud.
+type CloudProvider string
+
+const (
+	// BrowserStack is https://www.browserstack.com/automate. Credentials
+	// default to the BROWSERSTACK_USERNAME and BROWSERSTACK_ACCESS_KEY
+	// environment variables.
+	BrowserStack CloudProvider = "browserstack"
+	// SauceLabs is https://saucelabs.com. Credentials default to the
+	// SAUCE_USERNAME and SAUCE_ACCESS_KEY environment variables.
+	SauceLabs CloudProvider = "saucelabs"
+	// LambdaTest is https://www.lambdatest.com. Credentials default to the
+	// LT_USERNAME and LT_ACCESS_KEY environment variables.
+	LambdaTest CloudProvider = "lambdatest"
+)
+
+// CloudOptions configures WithCloud.
+type CloudOptions struct {
+	Provider CloudProvider
+	// Username and AccessKey authenticate with the provider. They default to
+	// the provider's environment variables.
+	Username  string
+	AccessKey string
+	// Region selects the Sauce Labs data center, e.g. "eu-central-1". It
+	// defaults to "us-west-1".
+	Region string
+	// Options holds additional entries of the vendor capability block, e.g.
+	// "build", "sessionName" or "os" for BrowserStack.
+	Options map[string]interface{}
+}
+
+// WithCloud makes Init use the grid of a cloud provider instead of starting a
+// local driver, so that the sessions created by New run on hosted browsers.
+// The port passed to Init is ignored.
+//
+// Note that paths passed to New, such as the profile directory, refer to the
+// remote machine's filesystem.
+func WithCloud(opts CloudOptions) InitOption {
+	return func(c *initConfig) {
+		c.cloud = &opts
+	}
+}
+
+// credentials returns the username and access key, looking them up in the
+// environment if unset.
+func (o *CloudOptions) credentials() (string, string) {
+	user, key := o.Username, o.AccessKey
+	var userEnv, keyEnv string
+	switch o.Provider {
+	case BrowserStack:
+		userEnv, keyEnv = "BROWSERSTACK_USERNAME", "BROWSERSTACK_ACCESS_KEY"
+	case SauceLabs:
+		userEnv, keyEnv = "SAUCE_USERNAME", "SAUCE_ACCESS_KEY"
+	case LambdaTest:
+		userEnv, keyEnv = "LT_USERNAME", "LT_ACCESS_KEY"
+	}
+	if user == "" {
+		user = os.Getenv(userEnv)
+	}
+	if key == "" {
+		key = os.Getenv(keyEnv)
+	}
+	return user, key
+}
+
+func (o *CloudOptions) region() string {
+	if o.Region == "" {
+		return "us-west-1"
+	}
+	return o.Region
+}
+
+// hubURL returns the WebDriver endpoint of the provider, including the
+// credentials.
+func (o *CloudOptions) hubURL() (string, error) {
+	var host string
+	switch o.Provider {
+	case BrowserStack:
+		host = "hub-cloud.browserstack.com"
+	case SauceLabs:
+		host = fmt.Sprintf("ondemand.%s.saucelabs.com", o.region())
+	case LambdaTest:
+		host = "hub.lambdatest.com"
+	default:
+		return "", fmt.Errorf("unknown cloud provider %q", o.Provider)
+	}
+
+	user, key := o.credentials()
+	if user == "" || key == "" {
+		return "", fmt.Errorf("missing %v credentials", o.Provider)
+	}
+
+	u := url.URL{
+		Scheme: "https",
+		User:   url.UserPassword(user, key),
+		Host:   host,
+		Path:   "/wd/hub",
+	}
+	return u.String(), nil
+}
+
+// capabilities adds the vendor capability block to caps.
+func (o *CloudOptions) capabilities(caps Capabilities) {
+	var key string
+	vendor := map[string]interface{}{}
+	switch o.Provider {
+	case BrowserStack:
+		key = "bstack:options"
+		vendor["source"] = "webdriver-go"
+	case SauceLabs:
+		key = "sauce:options"
+	case LambdaTest:
+		key = "LT:Options"
+		vendor["w3c"] = true
+	}
+	for k, v := range o.Options {
+		vendor[k] = v
+	}
+	caps[key] = vendor
+}
+
+// sessionURL returns the page showing the session on the provider's
+// dashboard.
+func (o *CloudOptions) sessionURL(id string) string {
+	switch o.Provider {
+	case BrowserStack:
+		return "https://automate.browserstack.com/dashboard/v2/sessions/" + id
+	case SauceLabs:
+		return fmt.Sprintf("https://app.%s.saucelabs.com/tests/%s", o.region(), id)
+	case LambdaTest:
+		return "https://automation.lambdatest.com/logs/?sessionID=" + id
+	}
+	return ""
+}
+
+// CloudURL returns the page showing the session on the dashboard of the cloud
+// provider selected by WithCloud, or an empty string for local sessions.
+func (s *Session) CloudURL() string {
+	return s.cloudURL
+}
@@ -0,0 +1,28 @@
+package webdriver
+
+import "testing"
+
+func TestCloudHubURL(t *testing.T) {
+	for _, tc := range []struct {
+		opts CloudOptions
+		want string
+	}{
+		{CloudOptions{Provider: BrowserStack, Username: "u", AccessKey: "k"}, "https://u:k@hub-cloud.browserstack.com/wd/hub"},
+		{CloudOptions{Provider: SauceLabs, Username: "u", AccessKey: "k"}, "https://u:k@ondemand.us-west-1.saucelabs.com/wd/hub"},
+		{CloudOptions{Provider: SauceLabs, Username: "u", AccessKey: "k", Region: "eu-central-1"}, "https://u:k@ondemand.eu-central-1.saucelabs.com/wd/hub"},
+		{CloudOptions{Provider: LambdaTest, Username: "u", AccessKey: "k"}, "https://u:k@hub.lambdatest.com/wd/hub"},
+	} {
+		got, err := tc.opts.hubURL()
+		if err != nil {
+			t.Errorf("hubURL(%+v) returned error: %v", tc.opts, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("hubURL(%+v) = %q, want %q", tc.opts, got, tc.want)
+		}
+	}
+
+	if _, err := (&CloudOptions{Provider: "nope", Username: "u", AccessKey: "k"}).hubURL(); err == nil {
+		t.Error("hubURL() with an unknown provider returned no error")
+	}
+}
@@ -0,0 +1,181 @@
+// Command webdriver-pagegen generates typed page objects from a YAML
+// description of a page, keeping selectors out of business logic.
+//
+// The description lists the page URL and its named XPath selectors:
+//
+//	package: pages
+//	name: Login
+//	url: https://example.com/login
+//	selectors:
+//	  - name: username
+//	    xpath: //input[@name='user']
+//	  - name: submit
+//	    xpath: //button[@type='submit']
+//
+// For each selector the generated type has a method returning the element
+// (Username), all matching elements (UsernameAll) and a click helper
+// (ClickUsername), all built on the Session's waiting finders.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"gopkg.in/yaml.v2"
+)
+
+type spec struct {
+	Package   string     `yaml:"package"`
+	Name      string     `yaml:"name"`
+	URL       string     `yaml:"url"`
+	Selectors []selector `yaml:"selectors"`
+}
+
+type selector struct {
+	Name  string `yaml:"name"`
+	XPath string `yaml:"xpath"`
+}
+
+func main() {
+	in := flag.String("in", "", "YAML page description")
+	out := flag.String("out", "", "output Go file, stdout if empty")
+	flag.Parse()
+
+	data, err := ioutil.ReadFile(*in)
+	if err != nil {
+		fmt.Printf("error reading page description %v\n", err)
+		os.Exit(1)
+	}
+
+	src, err := generate(data)
+	if err != nil {
+		fmt.Printf("error generating page object %v\n", err)
+		os.Exit(1)
+	}
+
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		fmt.Printf("error writing page object %v\n", err)
+		os.Exit(1)
+	}
+}
+
+var pageTemplate = template.Must(template.New("page").Funcs(template.FuncMap{
+	"export": export,
+}).Parse(`// Code generated by webdriver-pagegen. DO NOT EDIT.
+
+package {{.Package}}
+
+import "github.com/iamjinlei/webdriver"
+
+{{$page := export .Name}}
+{{- if .URL}}
+// {{$page}}URL is the address of the {{$page}} page.
+const {{$page}}URL = {{printf "%q" .URL}}
+{{end}}
+// {{$page}} is the page object of the {{$page}} page.
+type {{$page}} struct {
+	s *webdriver.Session
+}
+
+// New{{$page}} returns the {{$page}} page object operating on s.
+func New{{$page}}(s *webdriver.Session) *{{$page}} {
+	return &{{$page}}{s}
+}
+{{if .URL}}
+// Open navigates to {{$page}}URL.
+func (p *{{$page}}) Open() error {
+	return p.s.Get({{$page}}URL)
+}
+{{end}}
+{{- range .Selectors}}{{$name := export .Name}}
+// {{$name}} returns the element located by {{printf "%q" .XPath}}.
+func (p *{{$page}}) {{$name}}() (*webdriver.Element, error) {
+	return p.s.GetDOM({{printf "%q" .XPath}})
+}
+
+// {{$name}}All returns all elements located by {{printf "%q" .XPath}}.
+func (p *{{$page}}) {{$name}}All() ([]*webdriver.Element, error) {
+	return p.s.GetDOMs({{printf "%q" .XPath}})
+}
+
+// Click{{$name}} clicks the element located by {{printf "%q" .XPath}}.
+func (p *{{$page}}) Click{{$name}}() error {
+	return p.s.ClickDOM({{printf "%q" .XPath}})
+}
+{{end}}`))
+
+// generate returns the formatted Go source of the page object described by
+// the YAML data.
+func generate(data []byte) ([]byte, error) {
+	var sp spec
+	if err := yaml.UnmarshalStrict(data, &sp); err != nil {
+		return nil, err
+	}
+	if sp.Package == "" {
+		sp.Package = "pages"
+	}
+	if !token.IsIdentifier(sp.Package) {
+		return nil, fmt.Errorf("package name %q is not a Go identifier", sp.Package)
+	}
+	if export(sp.Name) == "" {
+		return nil, fmt.Errorf("page name is missing")
+	}
+
+	// methods maps the generated method names to what they come from, to
+	// reject selectors whose methods collide, e.g. "foo" and "foo all".
+	methods := map[string]string{}
+	if sp.URL != "" {
+		methods["Open"] = "the url"
+	}
+	for _, sel := range sp.Selectors {
+		name := export(sel.Name)
+		if name == "" || sel.XPath == "" {
+			return nil, fmt.Errorf("selector %q needs both a name and an xpath", sel.Name)
+		}
+		for _, m := range []string{name, name + "All", "Click" + name} {
+			if other, ok := methods[m]; ok {
+				return nil, fmt.Errorf("method %v of selector %q collides with %v", m, sel.Name, other)
+			}
+			methods[m] = fmt.Sprintf("selector %q", sel.Name)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pageTemplate.Execute(&buf, sp); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// export converts a name such as "search box" or "search_box" to an exported
+// Go identifier ("SearchBox").
+func export(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r) || (unicode.IsDigit(r) && b.Len() > 0):
+			if upper {
+				r = unicode.ToUpper(r)
+			}
+			b.WriteRune(r)
+			upper = false
+		case unicode.IsDigit(r):
+		default:
+			upper = true
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	src, err := generate([]byte(`
+name: login page
+url: https://example.com/login
+selectors:
+  - name: user_name
+    xpath: //input[@name="user"]
+  - name: submit
+    xpath: //button[@type='submit']
+`))
+	if err != nil {
+		t.Fatalf("generate() returned error: %v", err)
+	}
+
+	for _, want := range []string{
+		"package pages\n",
+		`const LoginPageURL = "https://example.com/login"`,
+		"func (p *LoginPage) UserName() (*webdriver.Element, error) {",
+		`return p.s.GetDOM("//input[@name=\"user\"]")`,
+		"func (p *LoginPage) ClickSubmit() error {",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generate() output does not contain %q:\n%s", want, src)
+		}
+	}
+}
+
+func TestGenerateDuplicateSelector(t *testing.T) {
+	_, err := generate([]byte(`
+name: login
+selectors:
+  - {name: submit, xpath: //button}
+  - {name: Submit, xpath: //input}
+`))
+	if err == nil {
+		t.Errorf("generate() with duplicate selectors returned nil error")
+	}
+}
+
+func TestGenerateCollisions(t *testing.T) {
+	for _, desc := range []string{`
+name: login
+selectors:
+  - {name: foo, xpath: //a}
+  - {name: foo all, xpath: //b}
+`, `
+name: login
+selectors:
+  - {name: click foo, xpath: //a}
+  - {name: foo, xpath: //b}
+`, `
+name: login
+url: https://example.com/login
+selectors:
+  - {name: open, xpath: //a}
+`, `
+package: my-pages
+name: login
+`, `
+package: func
+name: login
+`} {
+		if _, err := generate([]byte(desc)); err == nil {
+			t.Errorf("generate() returned nil error for:%s", desc)
+		}
+	}
+
+	if _, err := generate([]byte(`
+name: login
+selectors:
+  - {name: open, xpath: //a}
+`)); err != nil {
+		t.Errorf("generate() without url rejected selector open: %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// lineReader reads lines from the terminal with minimal editing support:
+// backspace, history recall with the up/down arrows and completion of the
+// argument with the Tab key. If stdin is not a terminal, it falls back to
+// plain buffered reads.
+type lineReader struct {
+	r *bufio.Reader
+	// saved is the terminal state to restore, empty if stdin is not a
+	// terminal.
+	saved       string
+	closeOnce   sync.Once
+	commands    []string
+	history     []string
+	completions []string
+}
+
+func newLineReader(commands []string) *lineReader {
+	lr := &lineReader{
+		r:        bufio.NewReader(os.Stdin),
+		commands: commands,
+	}
+	if saved, err := stty("-g"); err == nil {
+		if _, err := stty("-icanon", "-echo", "min", "1"); err == nil {
+			lr.saved = strings.TrimSpace(saved)
+		}
+	}
+	return lr
+}
+
+// Close restores the terminal settings. It is safe to call more than once,
+// e.g. from a signal handler.
+func (lr *lineReader) Close() {
+	lr.closeOnce.Do(func() {
+		if lr.saved != "" {
+			stty(lr.saved)
+		}
+	})
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// AddCompletion records a selector to be offered by Tab completion, most
+// recent first.
+func (lr *lineReader) AddCompletion(s string) {
+	for i, c := range lr.completions {
+		if c == s {
+			lr.completions = append(lr.completions[:i], lr.completions[i+1:]...)
+			break
+		}
+	}
+	lr.completions = append([]string{s}, lr.completions...)
+}
+
+func (lr *lineReader) ReadLine(prompt string) (string, error) {
+	fmt.Print(prompt)
+	if lr.saved == "" {
+		line, err := lr.r.ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	// pending keeps the line being edited while browsing the history.
+	var buf, pending []rune
+	hist := len(lr.history)
+	redraw := func() {
+		fmt.Printf("\r\033[K%s%s", prompt, string(buf))
+	}
+	for {
+		c, _, err := lr.r.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch c {
+		case '\r', '\n':
+			fmt.Println()
+			line := string(buf)
+			if strings.TrimSpace(line) != "" {
+				lr.history = append(lr.history, line)
+			}
+			return line, nil
+		case 4: // Ctrl-D
+			if len(buf) == 0 {
+				fmt.Println()
+				return "", io.EOF
+			}
+		case 127, '\b':
+			if len(buf) > 0 {
+				buf = buf[:len(buf)-1]
+				redraw()
+			}
+		case '\t':
+			buf = []rune(lr.complete(string(buf)))
+			redraw()
+		case 27: // Escape sequence, e.g. arrow keys.
+			if b, _ := lr.r.ReadByte(); b != '[' {
+				continue
+			}
+			b, _ := lr.r.ReadByte()
+			switch b {
+			case 'A':
+				if hist > 0 {
+					if hist == len(lr.history) {
+						pending = buf
+					}
+					hist--
+					buf = []rune(lr.history[hist])
+				}
+			case 'B':
+				if hist < len(lr.history)-1 {
+					hist++
+					buf = []rune(lr.history[hist])
+				} else if hist == len(lr.history)-1 {
+					hist++
+					buf = pending
+				}
+			}
+			redraw()
+		default:
+			if c >= ' ' {
+				buf = append(buf, c)
+				fmt.Print(string(c))
+			}
+		}
+	}
+}
+
+// complete extends the command name or selector being typed with the most
+// recent candidate that it prefixes.
+func (lr *lineReader) complete(line string) string {
+	i := strings.IndexByte(line, ' ')
+	if i < 0 {
+		for _, c := range lr.commands {
+			if strings.HasPrefix(c, line) {
+				return c + " "
+			}
+		}
+		return line
+	}
+
+	cmd, arg := line[:i+1], line[i+1:]
+	for _, c := range lr.completions {
+		if strings.HasPrefix(c, arg) {
+			return cmd + c
+		}
+	}
+	return line
+}
@@ -0,0 +1,160 @@
+// Command webdriver-repl opens a browser session and reads commands
+// interactively, which makes developing XPath selectors much faster than
+// recompiling a program for each attempt.
+//
+// Selectors used in previous commands are offered for completion with the
+// Tab key; the arrow keys walk through the command history.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/iamjinlei/webdriver"
+)
+
+const help = `commands:
+  get URL            navigate to URL
+  find XPATH         list the elements matching XPATH
+  click XPATH        click the first element matching XPATH
+  text XPATH         print the text of the first element matching XPATH
+  attr XPATH NAME    print an attribute of the first element matching XPATH
+  url                print the current URL
+  snap               serve a screenshot of the page
+  help               print this message
+  quit               end the session`
+
+var commands = []string{"get", "find", "click", "text", "attr", "url", "snap", "help", "quit"}
+
+func main() {
+	port := flag.Int("port", 9090, "webdriver port")
+	profile := flag.String("profile", "", "chrome profile directory")
+	headless := flag.Bool("headless", false, "run chrome headless")
+	timeout := flag.Duration("timeout", 10*time.Second, "element wait timeout")
+	flag.Parse()
+
+	in := newLineReader(commands)
+	defer in.Close()
+
+	// The default handler of Init would exit with the terminal in raw mode.
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		in.Close()
+		webdriver.Shutdown()
+		os.Exit(1)
+	}()
+
+	if err := webdriver.Init(*port, false, webdriver.WithoutSignalHandler()); err != nil {
+		fmt.Printf("init error %v\n", err)
+		return
+	}
+	defer webdriver.Shutdown()
+
+	s, err := webdriver.New(*profile, 1920, 1080, *headless, *timeout)
+	if err != nil {
+		fmt.Printf("error creating a new session %v\n", err)
+		return
+	}
+
+	fmt.Println(help)
+	for {
+		line, err := in.ReadLine("> ")
+		if err == io.EOF {
+			return
+		} else if err != nil {
+			fmt.Printf("read error %v\n", err)
+			return
+		}
+
+		cmd, arg := splitCommand(line)
+		if cmd == "quit" || cmd == "exit" {
+			return
+		}
+		if err := run(s, cmd, arg); err != nil {
+			fmt.Printf("error: %v\n", err)
+			continue
+		}
+		if cmd != "get" && arg != "" {
+			in.AddCompletion(selector(cmd, arg))
+		}
+	}
+}
+
+func splitCommand(line string) (string, string) {
+	line = strings.TrimSpace(line)
+	if i := strings.IndexByte(line, ' '); i >= 0 {
+		return line[:i], strings.TrimSpace(line[i+1:])
+	}
+	return line, ""
+}
+
+// selector strips the trailing attribute name from attr arguments.
+func selector(cmd, arg string) string {
+	if cmd == "attr" {
+		if i := strings.LastIndexByte(arg, ' '); i >= 0 {
+			return arg[:i]
+		}
+	}
+	return arg
+}
+
+func run(s *webdriver.Session, cmd, arg string) error {
+	switch cmd {
+	case "":
+		return nil
+	case "help":
+		fmt.Println(help)
+	case "get":
+		return s.Get(arg)
+	case "url":
+		u, err := s.CurrentURL()
+		if err != nil {
+			return err
+		}
+		fmt.Println(u)
+	case "find":
+		elems, err := s.GetDOMs(arg)
+		if err != nil {
+			return err
+		}
+		for i, elem := range elems {
+			tag, _ := elem.TagName()
+			fmt.Printf("[%d] <%s> %q\n", i, tag, elem.Txt())
+		}
+	case "click":
+		return s.ClickDOM(arg)
+	case "text":
+		elem, err := s.GetDOM(arg)
+		if err != nil {
+			return err
+		}
+		fmt.Println(elem.Txt())
+	case "attr":
+		i := strings.LastIndexByte(arg, ' ')
+		if i < 0 {
+			return fmt.Errorf("usage: attr XPATH NAME")
+		}
+		elem, err := s.GetDOM(arg[:i])
+		if err != nil {
+			return err
+		}
+		val, err := elem.GetAttribute(arg[i+1:])
+		if err != nil {
+			return err
+		}
+		fmt.Println(val)
+	case "snap":
+		return s.Snap()
+	default:
+		return fmt.Errorf("unknown command %q, try help", cmd)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+// Command webdriverd exposes webdriver sessions over a REST API so that
+// non-Go programs can reuse the package's waiting and retry semantics.
+//
+// Endpoints (request and response bodies are JSON):
+//
+//	POST   /sessions                      {"profile", "width", "height", "headless", "timeout"} -> {"id"}
+//	GET    /sessions                      -> ["id", ...]
+//	DELETE /sessions/{id}
+//	POST   /sessions/{id}/get             {"url"}
+//	POST   /sessions/{id}/dom             {"xpath"} -> {"tag", "text"}
+//	POST   /sessions/{id}/doms            {"xpath"} -> stream of {"tag", "text"}, one per line
+//	POST   /sessions/{id}/click           {"xpath"}
+//	GET    /sessions/{id}/screenshot      -> image/png
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iamjinlei/webdriver"
+)
+
+type service struct {
+	mu       sync.Mutex
+	sessions map[string]*webdriver.Session
+}
+
+func main() {
+	port := flag.Int("port", 9090, "webdriver port")
+	listen := flag.String("listen", "localhost:8080", "address to serve the REST API on")
+	debug := flag.Bool("debug", false, "debug mode")
+	flag.Parse()
+
+	if err := webdriver.Init(*port, *debug); err != nil {
+		fmt.Printf("init error %v\n", err)
+		return
+	}
+	defer webdriver.Shutdown()
+
+	svc := &service{sessions: map[string]*webdriver.Session{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sessions", svc.handleSessions)
+	mux.HandleFunc("/sessions/", svc.handleSession)
+
+	fmt.Printf("serving http://%v\n", *listen)
+	if err := http.ListenAndServe(*listen, mux); err != nil {
+		fmt.Printf("serve error %v\n", err)
+	}
+}
+
+type element struct {
+	Tag  string `json:"tag"`
+	Text string `json:"text"`
+}
+
+func (svc *service) handleSessions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		svc.mu.Lock()
+		ids := []string{}
+		for id := range svc.sessions {
+			ids = append(ids, id)
+		}
+		svc.mu.Unlock()
+		reply(w, ids)
+
+	case "POST":
+		req := struct {
+			Profile  string `json:"profile"`
+			Width    int    `json:"width"`
+			Height   int    `json:"height"`
+			Headless bool   `json:"headless"`
+			Timeout  string `json:"timeout"`
+		}{Width: 1920, Height: 1080, Headless: true, Timeout: "1m"}
+		if !decode(w, r, &req) {
+			return
+		}
+		timeout, err := time.ParseDuration(req.Timeout)
+		if err != nil {
+			fail(w, http.StatusBadRequest, err)
+			return
+		}
+
+		s, err := webdriver.New(req.Profile, req.Width, req.Height, req.Headless, timeout)
+		if err != nil {
+			fail(w, http.StatusInternalServerError, err)
+			return
+		}
+		svc.mu.Lock()
+		svc.sessions[s.SessionID()] = s
+		svc.mu.Unlock()
+		reply(w, map[string]string{"id": s.SessionID()})
+
+	default:
+		fail(w, http.StatusMethodNotAllowed, fmt.Errorf("method %v not allowed", r.Method))
+	}
+}
+
+func (svc *service) handleSession(w http.ResponseWriter, r *http.Request) {
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/sessions/"), "/", 2)
+	id, op := parts[0], ""
+	if len(parts) == 2 {
+		op = parts[1]
+	}
+
+	svc.mu.Lock()
+	s := svc.sessions[id]
+	svc.mu.Unlock()
+	if s == nil {
+		fail(w, http.StatusNotFound, fmt.Errorf("session %v not found", id))
+		return
+	}
+
+	var req struct {
+		URL   string `json:"url"`
+		XPath string `json:"xpath"`
+	}
+	if r.Method == "POST" && !decode(w, r, &req) {
+		return
+	}
+
+	switch {
+	case r.Method == "DELETE" && op == "":
+		svc.mu.Lock()
+		delete(svc.sessions, id)
+		svc.mu.Unlock()
+		result(w, nil, s.Close())
+
+	case r.Method == "POST" && op == "get":
+		result(w, nil, s.Get(req.URL))
+
+	case r.Method == "POST" && op == "dom":
+		elem, err := s.GetDOM(req.XPath)
+		if err != nil {
+			result(w, nil, err)
+			return
+		}
+		tag, err := elem.TagName()
+		result(w, element{tag, elem.Txt()}, err)
+
+	case r.Method == "POST" && op == "doms":
+		elems, err := s.GetDOMs(req.XPath)
+		if err != nil {
+			result(w, nil, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(w)
+		flusher, _ := w.(http.Flusher)
+		for _, elem := range elems {
+			tag, _ := elem.TagName()
+			if err := enc.Encode(element{tag, elem.Txt()}); err != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+
+	case r.Method == "POST" && op == "click":
+		result(w, nil, s.ClickDOM(req.XPath))
+
+	case r.Method == "GET" && op == "screenshot":
+		img, err := s.Screenshot()
+		if err != nil {
+			result(w, nil, err)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(img)
+
+	default:
+		fail(w, http.StatusNotFound, fmt.Errorf("unknown operation %v %v", r.Method, op))
+	}
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.ContentLength == 0 {
+		return true
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		fail(w, http.StatusBadRequest, err)
+		return false
+	}
+	return true
+}
+
+// result replies with v, or with err mapped to an HTTP status if non-nil.
+func result(w http.ResponseWriter, v interface{}, err error) {
+	switch {
+	case err == nil:
+		reply(w, v)
+	case err == webdriver.ErrNotFound:
+		fail(w, http.StatusNotFound, err)
+	case webdriver.IsTimeout(err):
+		fail(w, http.StatusGatewayTimeout, webdriver.ErrWaitTimeout)
+	default:
+		fail(w, http.StatusInternalServerError, err)
+	}
+}
+
+func reply(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if v == nil {
+		v = struct{}{}
+	}
+	json.NewEncoder(w).Encode(v)
+}
+
+func fail(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
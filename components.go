@@ -0,0 +1,143 @@
+package webdriver
+
+import "fmt"
+
+// componentsScript finds the outermost DOM element rendered by each instance
+// of a React or Vue component. It walks the internal fibers and instances
+// that the frameworks attach to DOM nodes for their devtools, which works on
+// production builds as long as component names survive minification.
+const componentsScript = xpathFunc + `
+var framework = arguments[0], name = arguments[1], props = arguments[2] || {};
+
+function propsMatch(actual) {
+	if (!actual) {
+		return Object.keys(props).length === 0;
+	}
+	for (var k in props) {
+		if (JSON.stringify(actual[k]) !== JSON.stringify(props[k])) {
+			return false;
+		}
+	}
+	return true;
+}
+
+function reactName(type) {
+	if (!type || typeof type === "string") {
+		return "";
+	}
+	return type.displayName || type.name ||
+		(type.render && (type.render.displayName || type.render.name)) ||
+		(type.type && (type.type.displayName || type.type.name)) || "";
+}
+
+function reactFiber(el) {
+	for (var k in el) {
+		if (k.indexOf("__reactFiber$") === 0 || k.indexOf("__reactInternalInstance$") === 0) {
+			return el[k];
+		}
+	}
+	return null;
+}
+
+// owners returns the component instances el belongs to, innermost first.
+function owners(el) {
+	var ret = [];
+	if (framework === "react") {
+		for (var f = reactFiber(el); f; f = f.return) {
+			if (typeof f.type !== "string" && reactName(f.type) === name && propsMatch(f.memoizedProps)) {
+				// Both fibers of a double buffered pair stand for the
+				// same instance.
+				ret.push(f.alternate && seen.has(f.alternate) ? f.alternate : f);
+			}
+		}
+	} else {
+		for (var i = el.__vueParentComponent; i; i = i.parent) {
+			if ((i.type.name || i.type.__name) === name && propsMatch(i.props)) {
+				ret.push(i);
+			}
+		}
+		for (var vm = el.__vue__; vm; vm = vm.$parent) {
+			if ((vm.$options.name || vm.$options._componentTag) === name && propsMatch(vm.$props)) {
+				ret.push(vm);
+			}
+		}
+	}
+	return ret;
+}
+
+var seen = new Set(), nodes = [];
+var all = document.querySelectorAll("*");
+for (var j = 0; j < all.length; j++) {
+	var found = owners(all[j]);
+	for (var n = 0; n < found.length; n++) {
+		// Elements are visited in document order, so the first element
+		// seen for an instance is its outermost one.
+		if (!seen.has(found[n])) {
+			seen.add(found[n]);
+			if (nodes[nodes.length - 1] !== all[j]) {
+				nodes.push(all[j]);
+			}
+		}
+	}
+}
+return nodes.map(function(n) {
+	return [n, xpath(n)];
+});`
+
+// findComponents returns the elements rendered by a framework's component.
+func (s *Session) findComponents(framework, name string, props map[string]interface{}) ([]*Element, error) {
+	data, err := s.ExecuteScriptRaw(componentsScript, []interface{}{framework, name, props})
+	if err != nil {
+		return nil, err
+	}
+	elems, err := s.decodeElementPaths(data)
+	if err != nil {
+		return nil, err
+	} else if len(elems) == 0 {
+		return nil, ErrNotFound
+	}
+	return elems, nil
+}
+
+// FindByReact returns the outermost DOM element of each mounted React
+// component with the display name and props. Props are compared by their
+// JSON encoding, and only the given props are compared; nil matches any
+// props. Unlike hashed CSS class names, component names are stable across
+// builds, unless a minifier mangles them.
+func (s *Session) FindByReact(component string, props map[string]interface{}) ([]*Element, error) {
+	return s.findComponents("react", component, props)
+}
+
+// FindByVue is FindByReact for Vue 2 and 3 components, matched by their
+// registered name.
+func (s *Session) FindByVue(component string, props map[string]interface{}) ([]*Element, error) {
+	return s.findComponents("vue", component, props)
+}
+
+// GetByReact waits for a React component, see FindByReact, and returns the
+// first one in document order.
+func (s *Session) GetByReact(component string, props map[string]interface{}) (*Element, error) {
+	return s.getComponent("GetByReact", "react", component, props)
+}
+
+// GetByVue waits for a Vue component, see FindByVue, and returns the first one
+// in document order.
+func (s *Session) GetByVue(component string, props map[string]interface{}) (*Element, error) {
+	return s.getComponent("GetByVue", "vue", component, props)
+}
+
+func (s *Session) getComponent(op, framework, name string, props map[string]interface{}) (*Element, error) {
+	var ret *Element
+	err := s.waitOn(func() (bool, error) {
+		elems, err := s.findComponents(framework, name, props)
+		if err == ErrNotFound || IsRetryable(err) {
+			return false, retryWith(err)
+		} else if err != nil {
+			return true, err
+		}
+
+		ret = elems[0]
+		return true, nil
+	}, s.timeout)
+	return ret, s.fail(op, fmt.Sprintf("%s %v", name, props), err)
+}
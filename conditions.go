@@ -0,0 +1,146 @@
+package webdriver
+
+import "strings"
+
+// WaitCond is a condition polled by WaitAll and WaitAny. Retryable errors,
+// see IsRetryable, count as the condition not being met yet.
+type WaitCond func(s *Session) (bool, error)
+
+// eval evaluates the condition, absorbing retryable errors.
+func (c WaitCond) eval(s *Session) (bool, error) {
+	ok, err := c(s)
+	if err != nil && (err == ErrNotFound || IsRetryable(err)) {
+		return false, nil
+	}
+	return ok, err
+}
+
+// AllOf is met when all conds are.
+func AllOf(conds ...WaitCond) WaitCond {
+	return func(s *Session) (bool, error) {
+		for _, c := range conds {
+			if ok, err := c.eval(s); err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+}
+
+// AnyOf is met when one of conds is.
+func AnyOf(conds ...WaitCond) WaitCond {
+	return func(s *Session) (bool, error) {
+		for _, c := range conds {
+			if ok, err := c.eval(s); err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	}
+}
+
+// Not is met when cond is not.
+func Not(cond WaitCond) WaitCond {
+	return func(s *Session) (bool, error) {
+		ok, err := cond.eval(s)
+		return !ok && err == nil, err
+	}
+}
+
+// Present is met when an element matches xpath.
+func Present(xpath string) WaitCond {
+	return func(s *Session) (bool, error) {
+		elems, err := s.FindElements(ByXPATH, xpath)
+		if notFound(err) {
+			return false, nil
+		}
+		return len(elems) > 0, err
+	}
+}
+
+// Absent is met when no element matches xpath.
+func Absent(xpath string) WaitCond {
+	return Not(Present(xpath))
+}
+
+// Visible is met when an element matching xpath is displayed.
+func Visible(xpath string) WaitCond {
+	return func(s *Session) (bool, error) {
+		elems, err := s.FindElements(ByXPATH, xpath)
+		if notFound(err) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		for _, e := range elems {
+			if ok, err := e.IsDisplayed(); err == nil && ok {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+// Count is met when the number of elements matching xpath satisfies
+// predicate, see WaitCount.
+func Count(xpath, predicate string) WaitCond {
+	match, perr := parseCountPredicate(predicate)
+	return func(s *Session) (bool, error) {
+		if perr != nil {
+			return false, perr
+		}
+		elems, err := s.FindElements(ByXPATH, xpath)
+		if notFound(err) {
+			elems, err = nil, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return match(len(elems)), nil
+	}
+}
+
+// URLContains is met when the current URL contains substr.
+func URLContains(substr string) WaitCond {
+	return func(s *Session) (bool, error) {
+		u, err := s.CurrentURL()
+		return err == nil && strings.Contains(u, substr), err
+	}
+}
+
+// TitleContains is met when the page title contains substr.
+func TitleContains(substr string) WaitCond {
+	return func(s *Session) (bool, error) {
+		t, err := s.Title()
+		return err == nil && strings.Contains(t, substr), err
+	}
+}
+
+// WaitAll waits until all conds are met, evaluating them on each poll within
+// the session timeout.
+func (s *Session) WaitAll(conds ...WaitCond) error {
+	err := s.waitOn(func() (bool, error) {
+		return AllOf(conds...).eval(s)
+	}, s.timeout)
+	return s.fail("WaitAll", "", err)
+}
+
+// WaitAny waits until one of conds is met and returns the index of the first
+// one met on that poll, or -1 on error.
+func (s *Session) WaitAny(conds ...WaitCond) (int, error) {
+	selected := -1
+	err := s.waitOn(func() (bool, error) {
+		for i, c := range conds {
+			ok, err := c.eval(s)
+			if err != nil {
+				return true, err
+			}
+			if ok {
+				selected = i
+				return true, nil
+			}
+		}
+		return false, nil
+	}, s.timeout)
+	return selected, s.fail("WaitAny", "", err)
+}
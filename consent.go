@@ -0,0 +1,165 @@
+package webdriver
+
+import "time"
+
+// ConsentRule recognizes the cookie consent banner of a consent manager and
+// the button dismissing it.
+type ConsentRule struct {
+	// Name identifies the rule, e.g. the consent manager, in logs.
+	Name string
+	// Frame, if set, locates the iframe the banner lives in, as with GDPR
+	// frameworks serving their banners from their own origin.
+	Frame *Locator
+	// Button locates the button dismissing the banner.
+	Button Locator
+}
+
+// DefaultConsentRules dismiss the banners of common consent managers,
+// rejecting optional cookies where the first layer of the banner allows it.
+// Rules for the same manager are tried in order, so that reject buttons take
+// precedence over accept buttons.
+var DefaultConsentRules = []ConsentRule{
+	{Name: "OneTrust", Button: CSSLocator("#onetrust-reject-all-handler")},
+	{Name: "OneTrust", Button: CSSLocator("#onetrust-accept-btn-handler")},
+	{Name: "Cookiebot", Button: CSSLocator("#CybotCookiebotDialogBodyButtonDecline")},
+	{Name: "Cookiebot", Button: CSSLocator("#CybotCookiebotDialogBodyLevelButtonLevelOptinAllowAll")},
+	{Name: "Didomi", Button: CSSLocator("#didomi-notice-disagree-button")},
+	{Name: "Didomi", Button: CSSLocator("#didomi-notice-agree-button")},
+	{Name: "TrustArc", Button: CSSLocator("#truste-consent-required")},
+	{Name: "TrustArc", Button: CSSLocator("#truste-consent-button")},
+	{Name: "Quantcast", Button: CSSLocator(".qc-cmp2-summary-buttons button[mode='primary']")},
+	{Name: "Usercentrics", Button: XPathLocator("#usercentrics-root >>> button[data-testid='uc-deny-all-button']")},
+	{
+		Name:   "Sourcepoint",
+		Frame:  &sourcepointFrame,
+		Button: CSSLocator("button.sp_choice_type_REJECT_ALL, button.sp_choice_type_11"),
+	},
+}
+
+var sourcepointFrame = CSSLocator("iframe[id^='sp_message_iframe']")
+
+// ConsentOptions configures WithConsentDismissal.
+type ConsentOptions struct {
+	// Rules are checked before DefaultConsentRules.
+	Rules []ConsentRule
+	// NoDefaults skips DefaultConsentRules.
+	NoDefaults bool
+	// Wait bounds how long a navigation waits for a banner to show up. It
+	// defaults to 3s.
+	Wait time.Duration
+	// OnDismiss, if set, is called with the rule of each dismissed banner.
+	OnDismiss func(s *Session, rule ConsentRule)
+}
+
+// WithConsentDismissal makes the session dismiss cookie consent banners after
+// each navigation by Get, as they overlay the page and intercept clicks. A
+// navigation then returns once a banner is dismissed, or after opts.Wait if
+// none shows up. Banners appearing after other navigations, e.g. by clicking
+// links, can be dismissed with DismissConsent.
+func WithConsentDismissal(opts ConsentOptions) Option {
+	return func(c *sessionConfig) {
+		c.consent = &opts
+	}
+}
+
+func (o *ConsentOptions) rules() []ConsentRule {
+	if o.NoDefaults {
+		return o.Rules
+	}
+	return append(append([]ConsentRule(nil), o.Rules...), DefaultConsentRules...)
+}
+
+// dismiss dismisses the banner of the page s navigated to.
+func (o *ConsentOptions) dismiss(s *Session) {
+	wait := o.Wait
+	if wait <= 0 {
+		wait = 3 * time.Second
+	}
+	if _, err := s.dismissConsent(o.rules(), wait, o.OnDismiss); err != nil {
+		debugLog("error dismissing consent banner: %v", err)
+	}
+}
+
+// DismissConsent waits up to timeout for a consent banner recognized by rules,
+// or DefaultConsentRules if none are given, and dismisses it. It reports
+// whether a banner was dismissed.
+func (s *Session) DismissConsent(timeout time.Duration, rules ...ConsentRule) (bool, error) {
+	if len(rules) == 0 {
+		rules = DefaultConsentRules
+	}
+	return s.dismissConsent(rules, timeout, nil)
+}
+
+func (s *Session) dismissConsent(rules []ConsentRule, timeout time.Duration, onDismiss func(*Session, ConsentRule)) (bool, error) {
+	// A rule shows up by its button, or its frame for banners in frames.
+	locators := make([]Locator, len(rules))
+	for i, r := range rules {
+		if r.Frame != nil {
+			locators[i] = *r.Frame
+		} else {
+			locators[i] = r.Button
+		}
+	}
+
+	dismissed := false
+	err := s.waitOn(func() (bool, error) {
+		if err := s.topFrame(); err != nil {
+			return true, err
+		}
+		idx, _, err := s.firstMatch(nil, locators)
+		if IsRetryable(err) {
+			return false, retryWith(err)
+		} else if err != nil {
+			return true, err
+		} else if idx < 0 {
+			return false, nil
+		}
+
+		rule := rules[idx]
+		err = s.clickConsent(rule)
+		if err == ErrNotFound || IsRetryable(err) {
+			// The banner is still rendering.
+			return false, retryWith(err)
+		} else if err != nil {
+			return true, err
+		}
+		debugLog("dismissed %v consent banner", rule.Name)
+		if onDismiss != nil {
+			onDismiss(s, rule)
+		}
+		dismissed = true
+		return true, nil
+	}, timeout)
+	if _, ok := err.(*TimeoutError); ok {
+		return false, nil
+	}
+	return dismissed, err
+}
+
+// clickConsent clicks the button of rule, in its frame if it has one.
+func (s *Session) clickConsent(rule ConsentRule) error {
+	if rule.Frame != nil {
+		frame, err := s.locateHere(*rule.Frame)
+		if err != nil {
+			return err
+		}
+		if err := s.SwitchFrame(frame.WebElement); err != nil {
+			return err
+		}
+		defer s.SwitchFrame(nil)
+	}
+
+	button, err := s.locateHere(rule.Button)
+	if err != nil {
+		return err
+	}
+	return button.Click()
+}
+
+// locateHere is locate in the current frame only, regardless of frame search.
+func (s *Session) locateHere(l Locator) (*Element, error) {
+	if l.By == ByXPATH {
+		return s.findHere(l.Value)
+	}
+	return s.locate(l)
+}
@@ -0,0 +1,17 @@
+package webdriver
+
+import "testing"
+
+func TestConsentRules(t *testing.T) {
+	own := ConsentRule{Name: "own", Button: CSSLocator("#reject")}
+	opts := &ConsentOptions{Rules: []ConsentRule{own}}
+	rules := opts.rules()
+	if len(rules) != 1+len(DefaultConsentRules) || rules[0].Name != "own" {
+		t.Errorf("rules = %v, want own rule before the defaults", rules)
+	}
+
+	opts.NoDefaults = true
+	if rules := opts.rules(); len(rules) != 1 {
+		t.Errorf("rules without defaults = %v", rules)
+	}
+}
@@ -0,0 +1,184 @@
+package webdriver
+
+import (
+	"sort"
+)
+
+// CoverageEntry is the coverage of a script or stylesheet.
+type CoverageEntry struct {
+	// Type is "js" or "css".
+	Type string
+	// URL is the source of the script or stylesheet. It is empty for inline
+	// ones, and for stylesheets unless the session uses the CDPDriver, as
+	// their URL is only reported through DevTools events.
+	URL string
+	// StyleSheetID identifies the stylesheet, for css.
+	StyleSheetID string
+	// Total is the size of the source, in characters.
+	Total int64
+	// Used is the size of the code that ran, or of the rules that matched.
+	Used int64
+}
+
+// Coverage is the result of StopCoverage.
+type Coverage struct {
+	Entries []CoverageEntry
+	// JSTotal, JSUsed, CSSTotal and CSSUsed aggregate the entries by type.
+	JSTotal, JSUsed   int64
+	CSSTotal, CSSUsed int64
+}
+
+// StartCoverage starts recording which JavaScript functions run and which
+// CSS rules match on the page, until StopCoverage. Scripts loaded before the
+// call are covered from then on only, so start it before navigating.
+func (s *Session) StartCoverage() error {
+	for _, cmd := range []string{"Profiler.enable", "DOM.enable", "CSS.enable"} {
+		if err := s.cdp(cmd, nil, nil); err != nil {
+			return err
+		}
+	}
+	if err := s.cdp("Profiler.startPreciseCoverage", map[string]interface{}{"callCount": false, "detailed": true}, nil); err != nil {
+		return err
+	}
+	return s.cdp("CSS.startRuleUsageTracking", nil, nil)
+}
+
+// StopCoverage stops the recording started by StartCoverage and returns the
+// coverage of each script and stylesheet.
+func (s *Session) StopCoverage() (*Coverage, error) {
+	js := struct {
+		Result []struct {
+			URL       string `json:"url"`
+			Functions []struct {
+				Ranges []coverageRange `json:"ranges"`
+			} `json:"functions"`
+		} `json:"result"`
+	}{}
+	if err := s.cdp("Profiler.takePreciseCoverage", nil, &js); err != nil {
+		return nil, err
+	}
+	css := struct {
+		RuleUsage []struct {
+			StyleSheetID string  `json:"styleSheetId"`
+			StartOffset  float64 `json:"startOffset"`
+			EndOffset    float64 `json:"endOffset"`
+			Used         bool    `json:"used"`
+		} `json:"ruleUsage"`
+	}{}
+	if err := s.cdp("CSS.stopRuleUsageTracking", nil, &css); err != nil {
+		return nil, err
+	}
+	if err := s.cdp("Profiler.stopPreciseCoverage", nil, nil); err != nil {
+		return nil, err
+	}
+
+	cov := &Coverage{}
+	for _, script := range js.Result {
+		var ranges []coverageRange
+		for _, f := range script.Functions {
+			ranges = append(ranges, f.Ranges...)
+		}
+		total, used := jsCoverage(ranges)
+		cov.Entries = append(cov.Entries, CoverageEntry{Type: "js", URL: script.URL, Total: total, Used: used})
+		cov.JSTotal += total
+		cov.JSUsed += used
+	}
+
+	var urls map[string]string
+	if wd, ok := s.WebDriver.(*cdpWD); ok {
+		wd.mu.Lock()
+		urls = make(map[string]string, len(wd.styleSheets))
+		for id, u := range wd.styleSheets {
+			urls[id] = u
+		}
+		wd.mu.Unlock()
+	}
+	sheets := map[string]*CoverageEntry{}
+	var order []string
+	for _, r := range css.RuleUsage {
+		e := sheets[r.StyleSheetID]
+		if e == nil {
+			text := struct {
+				Text string `json:"text"`
+			}{}
+			if err := s.cdp("CSS.getStyleSheetText", map[string]interface{}{"styleSheetId": r.StyleSheetID}, &text); err != nil {
+				return nil, err
+			}
+			e = &CoverageEntry{
+				Type:         "css",
+				URL:          urls[r.StyleSheetID],
+				StyleSheetID: r.StyleSheetID,
+				Total:        int64(len([]rune(text.Text))),
+			}
+			sheets[r.StyleSheetID] = e
+			order = append(order, r.StyleSheetID)
+		}
+		if r.Used {
+			e.Used += int64(r.EndOffset - r.StartOffset)
+		}
+	}
+	for _, id := range order {
+		e := sheets[id]
+		cov.Entries = append(cov.Entries, *e)
+		cov.CSSTotal += e.Total
+		cov.CSSUsed += e.Used
+	}
+	return cov, nil
+}
+
+// coverageRange is a range of a script, counted by the profiler. Ranges nest,
+// the innermost range counting for its span.
+type coverageRange struct {
+	StartOffset int64 `json:"startOffset"`
+	EndOffset   int64 `json:"endOffset"`
+	Count       int64 `json:"count"`
+}
+
+// jsCoverage returns the size of a script, as the end of its outermost range,
+// and the size of its spans whose innermost range has a non-zero count.
+func jsCoverage(ranges []coverageRange) (int64, int64) {
+	type point struct {
+		offset int64
+		open   bool
+		r      coverageRange
+	}
+	var total int64
+	points := make([]point, 0, 2*len(ranges))
+	for _, r := range ranges {
+		if r.EndOffset > total {
+			total = r.EndOffset
+		}
+		points = append(points, point{r.StartOffset, true, r}, point{r.EndOffset, false, r})
+	}
+	// Ranges close before others open at the same offset, the outer ones
+	// opening first and closing last.
+	sort.SliceStable(points, func(i, j int) bool {
+		a, b := points[i], points[j]
+		if a.offset != b.offset {
+			return a.offset < b.offset
+		}
+		if a.open != b.open {
+			return !a.open
+		}
+		la, lb := a.r.EndOffset-a.r.StartOffset, b.r.EndOffset-b.r.StartOffset
+		if a.open {
+			return la > lb
+		}
+		return la < lb
+	})
+
+	var used, last int64
+	var stack []coverageRange
+	for _, p := range points {
+		if len(stack) > 0 && stack[len(stack)-1].Count > 0 {
+			used += p.offset - last
+		}
+		last = p.offset
+		if p.open {
+			stack = append(stack, p.r)
+		} else if len(stack) > 0 {
+			stack = stack[:len(stack)-1]
+		}
+	}
+	return total, used
+}
@@ -0,0 +1,25 @@
+package webdriver
+
+import "testing"
+
+func TestJSCoverage(t *testing.T) {
+	tests := []struct {
+		ranges      []coverageRange
+		total, used int64
+	}{
+		{nil, 0, 0},
+		{[]coverageRange{{0, 100, 1}}, 100, 100},
+		// An uncalled function inside the script.
+		{[]coverageRange{{0, 100, 1}, {10, 30, 0}}, 100, 80},
+		// A called block inside an uncalled function.
+		{[]coverageRange{{0, 100, 1}, {10, 30, 0}, {15, 20, 1}}, 100, 85},
+		// Adjacent uncalled functions.
+		{[]coverageRange{{0, 100, 1}, {10, 30, 0}, {30, 50, 0}}, 100, 60},
+	}
+	for _, test := range tests {
+		total, used := jsCoverage(test.ranges)
+		if total != test.total || used != test.used {
+			t.Errorf("jsCoverage(%v) = %d, %d, want %d, %d", test.ranges, total, used, test.total, test.used)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package webdriver
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+)
+
+// NewCtx is New bound to ctx: canceling ctx quits the browser and marks the
+// session dead, so that a canceled job does not leave its browser alive until
+// Shutdown. Commands of a dead session fail with the error of ctx.
+func NewCtx(ctx context.Context, profile string, w, h int, headless bool, timeout time.Duration, opts ...Option) (*Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s, err := New(profile, w, h, headless, timeout, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.done = make(chan struct{})
+	done := s.done
+	s.mu.Unlock()
+	s.Use(s.deadGuard())
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+			return
+		}
+		debugLog("closing session %v: %v", s.SessionID(), ctx.Err())
+		// Commands racing the close fail with the error of ctx already.
+		s.errMu.Lock()
+		s.err = ctx.Err()
+		s.errMu.Unlock()
+		s.Close()
+	}()
+	return s, nil
+}
+
+// deadGuard returns the hook failing the commands of a dead session with the
+// reason it is dead.
+func (s *Session) deadGuard() Hook {
+	return Hook{Before: func(cmd *Command) error {
+		if isQuit(cmd) {
+			return nil
+		}
+		return s.Err()
+	}}
+}
+
+// isQuit reports whether cmd ends the session, which a dead session still
+// lets through to quit its browser.
+func isQuit(cmd *Command) bool {
+	return cmd.Method == "DELETE" && strings.HasSuffix(path.Dir(cmd.URL), "/session")
+}
+
+// Err returns the reason the session is dead, e.g. the error of the context
+// of NewCtx once it is done, or nil while the session is usable.
+func (s *Session) Err() error {
+	// Not s.mu: Err runs in a hook, and commands are sent with s.mu held.
+	s.errMu.Lock()
+	defer s.errMu.Unlock()
+	return s.err
+}
+
+// markDone stops the context watcher of the session.
+func (s *Session) markDone() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done != nil {
+		select {
+		case <-s.done:
+		default:
+			close(s.done)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package webdriver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDeadGuard(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		w.Write([]byte(`{"value": null}`))
+	}))
+	defer srv.Close()
+
+	wd := &remoteWD{urlPrefix: srv.URL, id: "1"}
+	s := &Session{WebDriver: wd}
+	s.Use(s.deadGuard())
+
+	// Commands sent with s.mu held must not wait for it.
+	done := make(chan error)
+	s.mu.Lock()
+	go func() { done <- wd.Refresh() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Refresh() of a live session returned error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Refresh() blocked on the session mutex")
+	}
+	s.mu.Unlock()
+
+	s.err = context.Canceled
+	if err := wd.Refresh(); err != context.Canceled {
+		t.Errorf("Refresh() of a dead session returned %v, want %v", err, context.Canceled)
+	}
+	if err := wd.Quit(); err != nil {
+		t.Errorf("Quit() of a dead session returned error: %v", err)
+	}
+}
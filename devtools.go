@@ -0,0 +1,62 @@
+package webdriver
+
+import (
+	"strings"
+	"time"
+)
+
+// pageConn returns a DevTools connection to the target of the current
+// window, opened once and closed with the session. Unlike the commands sent
+// through the driver, it receives the events of the target.
+func (s *Session) pageConn() (*cdpConn, error) {
+	handle, err := s.CurrentWindowHandle()
+	if err != nil {
+		return nil, err
+	}
+	// Older ChromeDriver versions prefix the target id.
+	target := strings.TrimPrefix(handle, "CDwindow-")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c := s.pageConns[target]; c != nil {
+		select {
+		case <-c.closed:
+		default:
+			return c, nil
+		}
+	}
+
+	c, err := s.dialPage(target)
+	if err != nil {
+		return nil, err
+	}
+	if s.pageConns == nil {
+		s.pageConns = map[string]*cdpConn{}
+	}
+	s.pageConns[target] = c
+	return c, nil
+}
+
+// closePageConns closes the connections opened by pageConn.
+func (s *Session) closePageConns() {
+	s.mu.Lock()
+	conns := s.pageConns
+	s.pageConns = nil
+	s.mu.Unlock()
+	for _, c := range conns {
+		c.Close()
+	}
+}
+
+// dialPage opens a new DevTools connection to a page target.
+func (s *Session) dialPage(target string) (*cdpConn, error) {
+	addr, err := s.debuggerAddress()
+	if err != nil {
+		return nil, err
+	}
+	ws, err := dialWebSocket("ws://"+addr+"/devtools/page/"+target, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return newCDPConn(ws), nil
+}
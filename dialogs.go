@@ -0,0 +1,133 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// The values of the unhandledPromptBehavior capability, see
+// WithUnhandledPromptBehavior.
+const (
+	PromptDismiss          = "dismiss"
+	PromptAccept           = "accept"
+	PromptDismissAndNotify = "dismiss and notify"
+	PromptAcceptAndNotify  = "accept and notify"
+	PromptIgnore           = "ignore"
+)
+
+// WithUnhandledPromptBehavior sets how the driver handles a JavaScript dialog
+// open when a command runs, one of PromptDismiss, PromptAccept,
+// PromptDismissAndNotify, PromptAcceptAndNotify or PromptIgnore. The notify
+// variants make the command fail after handling the dialog. It has no effect
+// with the CDPDriver, whose commands fail while a dialog is open.
+func WithUnhandledPromptBehavior(behavior string) Option {
+	return func(c *sessionConfig) {
+		c.promptBehavior = behavior
+	}
+}
+
+// DialogAction is the answer of a DialogPolicy to a kind of dialog.
+type DialogAction int
+
+const (
+	// DialogDefault shows the dialog, for the automation or the
+	// unhandledPromptBehavior to handle.
+	DialogDefault DialogAction = iota
+	// DialogAccept answers OK without showing the dialog. For beforeunload,
+	// the page is left without asking.
+	DialogAccept
+	// DialogDismiss answers Cancel without showing the dialog.
+	DialogDismiss
+)
+
+// DialogPolicy configures WithDialogPolicy.
+type DialogPolicy struct {
+	Alert   DialogAction
+	Confirm DialogAction
+	Prompt  DialogAction
+	// PromptText is the answer of accepted prompts. It defaults to the
+	// default value of the prompt.
+	PromptText string
+	// BeforeUnload supports DialogDefault and DialogAccept only.
+	BeforeUnload DialogAction
+}
+
+// WithDialogPolicy answers the JavaScript dialogs of the page as set by
+// policy, without showing them, so that crawls never hang on an unexpected
+// confirm or "Leave site?" prompt. The dialog functions are replaced by a
+// script evaluated before those of every document, which requires a
+// Chromium-based browser.
+func WithDialogPolicy(policy DialogPolicy) Option {
+	return func(c *sessionConfig) {
+		c.dialogs = &policy
+	}
+}
+
+// dialogScript replaces the dialog functions as set by the policy.
+const dialogScript = `(function(p) {
+if (p.alert) {
+	window.alert = function alert() {};
+}
+if (p.confirm) {
+	window.confirm = function confirm() {
+		return p.confirm === "accept";
+	};
+}
+if (p.prompt) {
+	window.prompt = function prompt(message, def) {
+		if (p.prompt !== "accept") {
+			return null;
+		}
+		return p.promptText !== "" ? p.promptText : (def === undefined ? "" : String(def));
+	};
+}
+if (p.beforeunload) {
+	var add = EventTarget.prototype.addEventListener;
+	EventTarget.prototype.addEventListener = function addEventListener(type) {
+		if (this === window && String(type).toLowerCase() === "beforeunload") {
+			return;
+		}
+		return add.apply(this, arguments);
+	};
+	Object.defineProperty(window, "onbeforeunload", {get: function() { return null; }, set: function() {}, configurable: true});
+}
+})(__POLICY__);`
+
+// script returns the init script implementing the policy, or "" if it
+// changes nothing.
+func (p *DialogPolicy) script() (string, error) {
+	name := func(a DialogAction) (string, error) {
+		switch a {
+		case DialogDefault:
+			return "", nil
+		case DialogAccept:
+			return "accept", nil
+		case DialogDismiss:
+			return "dismiss", nil
+		}
+		return "", fmt.Errorf("invalid dialog action %d", a)
+	}
+	if p.BeforeUnload == DialogDismiss {
+		return "", fmt.Errorf("beforeunload prompts cannot be dismissed")
+	}
+
+	cfg := map[string]interface{}{"promptText": p.PromptText}
+	changed := false
+	for key, a := range map[string]DialogAction{"alert": p.Alert, "confirm": p.Confirm, "prompt": p.Prompt, "beforeunload": p.BeforeUnload} {
+		n, err := name(a)
+		if err != nil {
+			return "", err
+		}
+		cfg[key] = n
+		changed = changed || n != ""
+	}
+	if !changed {
+		return "", nil
+	}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	return strings.Replace(dialogScript, "__POLICY__", string(data), 1), nil
+}
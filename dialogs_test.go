@@ -0,0 +1,26 @@
+package webdriver
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDialogPolicyScript(t *testing.T) {
+	if script, err := (&DialogPolicy{}).script(); err != nil || script != "" {
+		t.Errorf("script() of the default policy = %q, %v, want empty", script, err)
+	}
+
+	script, err := (&DialogPolicy{Confirm: DialogAccept, BeforeUnload: DialogAccept}).script()
+	if err != nil {
+		t.Fatalf("script() returned error: %v", err)
+	}
+	for _, want := range []string{`"confirm":"accept"`, `"beforeunload":"accept"`, `"alert":""`} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script() does not contain %s", want)
+		}
+	}
+
+	if _, err := (&DialogPolicy{BeforeUnload: DialogDismiss}).script(); err == nil {
+		t.Error("script() dismissing beforeunload returned no error")
+	}
+}
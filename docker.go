@@ -0,0 +1,274 @@
+package webdriver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultDockerImage is the container image started by WithDocker when
+// DockerOptions.Image is empty.
+const DefaultDockerImage = "selenium/standalone-chrome"
+
+// DockerOptions configures the browser container started by WithDocker.
+type DockerOptions struct {
+	// Image is the container image to run. It must serve the WebDriver
+	// protocol on port 4444, as the selenium/standalone-* images do. It
+	// defaults to DefaultDockerImage.
+	Image string
+	// Host is the address of the Docker daemon, e.g. "unix:///var/run/docker.sock"
+	// or "tcp://127.0.0.1:2375". It defaults to the DOCKER_HOST environment
+	// variable, falling back to the local unix socket.
+	Host string
+	// ShmSize is the size of /dev/shm in bytes. Chrome crashes on the Docker
+	// default of 64MB, so it defaults to 2GB.
+	ShmSize int64
+	// Env holds additional "KEY=value" environment variables for the
+	// container.
+	Env []string
+	// StartTimeout bounds the time spent waiting for the WebDriver server in
+	// the container to become ready, not including the image pull. It
+	// defaults to one minute.
+	StartTimeout time.Duration
+}
+
+// WithDocker makes Init run the browser and its WebDriver server in a Docker
+// container instead of starting a local driver binary. The container port is
+// published on the port passed to Init and the container is removed by
+// Shutdown.
+//
+// Note that paths passed to New, such as the profile directory, refer to the
+// container's filesystem.
+func WithDocker(opts DockerOptions) InitOption {
+	return func(c *initConfig) {
+		c.docker = &opts
+	}
+}
+
+// dockerContainer is a running container managed through the Docker Engine
+// API.
+type dockerContainer struct {
+	c  *dockerClient
+	id string
+}
+
+func (dc *dockerContainer) remove() error {
+	return dc.c.do("DELETE", "/containers/"+dc.id+"?force=true", nil, nil)
+}
+
+// startContainer starts a container serving the WebDriver protocol on the
+// given host port and waits for it to become ready.
+func startContainer(kind DriverKind, opts DockerOptions, port int) (*driver, error) {
+	if kind == SafariDriver {
+		return nil, fmt.Errorf("%v cannot be run in docker", kind)
+	}
+	if opts.Image == "" {
+		opts.Image = DefaultDockerImage
+	}
+	if opts.ShmSize == 0 {
+		opts.ShmSize = 2 << 30
+	}
+	if opts.StartTimeout == 0 {
+		opts.StartTimeout = time.Minute
+	}
+
+	c, err := newDockerClient(opts.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	config := map[string]interface{}{
+		"Image": opts.Image,
+		"Env":   opts.Env,
+		"ExposedPorts": map[string]interface{}{
+			"4444/tcp": struct{}{},
+		},
+		"HostConfig": map[string]interface{}{
+			"ShmSize": opts.ShmSize,
+			"PortBindings": map[string]interface{}{
+				"4444/tcp": []map[string]string{{"HostIp": "127.0.0.1", "HostPort": strconv.Itoa(port)}},
+			},
+		},
+	}
+
+	var created struct {
+		ID string `json:"Id"`
+	}
+	err = c.do("POST", "/containers/create", config, &created)
+	if isDockerNotFound(err) {
+		fmt.Printf("*** [webdriver] pulling image %v ***\n", opts.Image)
+		if err = c.pull(opts.Image); err == nil {
+			err = c.do("POST", "/containers/create", config, &created)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dc := &dockerContainer{c: c, id: created.ID}
+	fmt.Printf("*** [webdriver] starting container %v (%v) ***\n", shortID(dc.id), opts.Image)
+	if err := c.do("POST", "/containers/"+dc.id+"/start", nil, nil); err != nil {
+		dc.remove()
+		return nil, err
+	}
+
+	d := &driver{
+		port:      port,
+		addr:      fmt.Sprintf("http://localhost:%d/wd/hub", port),
+		container: dc,
+	}
+	deadline := time.Now().Add(opts.StartTimeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(time.Second)
+		if driverStatus(d.addr) == http.StatusOK {
+			return d, nil
+		}
+	}
+
+	dc.remove()
+	return nil, fmt.Errorf("container %v not ready after %v", shortID(dc.id), opts.StartTimeout)
+}
+
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// dockerClient is a minimal client of the Docker Engine HTTP API.
+type dockerClient struct {
+	client *http.Client
+	base   string
+}
+
+// dockerError is an error response from the Docker daemon.
+type dockerError struct {
+	code    int
+	Message string `json:"message"`
+}
+
+func (e *dockerError) Error() string {
+	return fmt.Sprintf("docker: %s (HTTP %d)", e.Message, e.code)
+}
+
+func isDockerNotFound(err error) bool {
+	de, ok := err.(*dockerError)
+	return ok && de.code == http.StatusNotFound
+}
+
+func newDockerClient(host string) (*dockerClient, error) {
+	if host == "" {
+		host = os.Getenv("DOCKER_HOST")
+	}
+	if host == "" {
+		host = "unix:///var/run/docker.sock"
+	}
+
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "unix":
+		socket := u.Path
+		return &dockerClient{
+			client: &http.Client{
+				Transport: &http.Transport{
+					DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+						var d net.Dialer
+						return d.DialContext(ctx, "unix", socket)
+					},
+				},
+			},
+			base: "http://docker",
+		}, nil
+	case "tcp", "http":
+		return &dockerClient{client: http.DefaultClient, base: "http://" + u.Host}, nil
+	}
+	return nil, fmt.Errorf("unsupported docker host %q", host)
+}
+
+// do sends a JSON request to the daemon and decodes the JSON reply into ret,
+// unless ret is nil.
+func (c *dockerClient) do(method, path string, params, ret interface{}) error {
+	var body io.Reader
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.base+path, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", jsonContentType)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		de := &dockerError{code: resp.StatusCode}
+		data, _ := ioutil.ReadAll(resp.Body)
+		if err := json.Unmarshal(data, de); err != nil || de.Message == "" {
+			de.Message = strings.TrimSpace(string(data))
+		}
+		return de
+	}
+	if ret == nil {
+		_, err := io.Copy(ioutil.Discard, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(ret)
+}
+
+// pull fetches the image from its registry. The daemon streams progress
+// messages until the pull completes; errors are reported in-band.
+func (c *dockerClient) pull(image string) error {
+	ref, tag := image, "latest"
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		ref, tag = image[:i], image[i+1:]
+	}
+
+	resp, err := c.client.Post(c.base+"/images/create?fromImage="+url.QueryEscape(ref)+"&tag="+url.QueryEscape(tag), "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return &dockerError{code: resp.StatusCode, Message: "failed to pull " + image}
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var msg struct {
+			Error string `json:"error"`
+		}
+		if err := dec.Decode(&msg); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if msg.Error != "" {
+			return fmt.Errorf("docker: pulling %v: %v", image, msg.Error)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// The states of a DownloadEvent.
+const (
+	DownloadStarted    = "started"
+	DownloadInProgress = "inProgress"
+	DownloadCompleted  = "completed"
+	DownloadCanceled   = "canceled"
+)
+
+// DownloadEvent reports the progress of a download, see WatchDownloads.
+type DownloadEvent struct {
+	// GUID identifies the download across its events.
+	GUID     string
+	URL      string
+	Filename string
+	// State is DownloadStarted, DownloadInProgress, DownloadCompleted or
+	// DownloadCanceled.
+	State         string
+	ReceivedBytes int64
+	// TotalBytes is zero if the size is unknown.
+	TotalBytes int64
+	// Path is the file the download was saved to, once completed.
+	Path string
+}
+
+// DownloadWatcher streams the download events of a session, see
+// WatchDownloads.
+type DownloadWatcher struct {
+	// C receives the events in order. It is closed when the watcher stops.
+	C <-chan DownloadEvent
+
+	conn *cdpConn
+	dir  string
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+
+	mu      sync.Mutex
+	names   map[string]DownloadEvent
+	queue   []DownloadEvent
+	pending chan struct{}
+}
+
+// WatchDownloads makes the browser save downloads to dir and streams their
+// progress, from start to completion, instead of polling the directory.
+// Completed files are named after the name suggested by the server, made
+// unique within dir. Stop the watcher to stop receiving events; downloads
+// keep going to dir. It requires a local Chromium-based browser.
+func (s *Session) WatchDownloads(dir string) (*DownloadWatcher, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	conn, err := s.browserConn()
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan DownloadEvent)
+	w := &DownloadWatcher{
+		C:       ch,
+		conn:    conn,
+		dir:     dir,
+		done:    make(chan struct{}),
+		names:   map[string]DownloadEvent{},
+		pending: make(chan struct{}, 1),
+	}
+	conn.on("Browser.downloadWillBegin", w.willBegin)
+	conn.on("Browser.downloadProgress", w.progress)
+	if _, err := conn.call("Browser.setDownloadBehavior", map[string]interface{}{
+		"behavior":      "allowAndName",
+		"downloadPath":  dir,
+		"eventsEnabled": true,
+	}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	w.wg.Add(1)
+	go w.pump(ch)
+	return w, nil
+}
+
+func (w *DownloadWatcher) push(ev DownloadEvent) {
+	w.mu.Lock()
+	w.queue = append(w.queue, ev)
+	w.mu.Unlock()
+	select {
+	case w.pending <- struct{}{}:
+	default:
+	}
+}
+
+// pump delivers the queued events, so that the read loop of the connection
+// never blocks on a slow reader.
+func (w *DownloadWatcher) pump(ch chan<- DownloadEvent) {
+	defer w.wg.Done()
+	defer close(ch)
+	for {
+		w.mu.Lock()
+		queue := w.queue
+		w.queue = nil
+		w.mu.Unlock()
+
+		for _, ev := range queue {
+			select {
+			case ch <- ev:
+			case <-w.done:
+				return
+			}
+		}
+
+		select {
+		case <-w.pending:
+		case <-w.conn.closed:
+			return
+		case <-w.done:
+			return
+		}
+	}
+}
+
+func (w *DownloadWatcher) willBegin(params json.RawMessage) {
+	ev := struct {
+		GUID              string `json:"guid"`
+		URL               string `json:"url"`
+		SuggestedFilename string `json:"suggestedFilename"`
+	}{}
+	if err := json.Unmarshal(params, &ev); err != nil {
+		return
+	}
+	d := DownloadEvent{GUID: ev.GUID, URL: ev.URL, Filename: ev.SuggestedFilename, State: DownloadStarted}
+	w.mu.Lock()
+	w.names[ev.GUID] = d
+	w.mu.Unlock()
+	w.push(d)
+}
+
+func (w *DownloadWatcher) progress(params json.RawMessage) {
+	ev := struct {
+		GUID          string  `json:"guid"`
+		TotalBytes    float64 `json:"totalBytes"`
+		ReceivedBytes float64 `json:"receivedBytes"`
+		State         string  `json:"state"`
+	}{}
+	if err := json.Unmarshal(params, &ev); err != nil {
+		return
+	}
+
+	w.mu.Lock()
+	d := w.names[ev.GUID]
+	if ev.State != DownloadInProgress {
+		delete(w.names, ev.GUID)
+	}
+	w.mu.Unlock()
+
+	d.GUID = ev.GUID
+	d.State = ev.State
+	d.ReceivedBytes = int64(ev.ReceivedBytes)
+	d.TotalBytes = int64(ev.TotalBytes)
+	if ev.State == DownloadCompleted {
+		d.Path = filepath.Join(w.dir, ev.GUID)
+		if name := uniqueFilename(w.dir, d.Filename); name != "" {
+			target := filepath.Join(w.dir, name)
+			if err := os.Rename(d.Path, target); err != nil {
+				debugLog("error renaming download %v: %v", d.Path, err)
+			} else {
+				d.Path = target
+			}
+		}
+	}
+	w.push(d)
+}
+
+// uniqueFilename returns name, sanitized and numbered to not exist in dir,
+// or "" if name is empty.
+func uniqueFilename(dir, name string) string {
+	name = filepath.Base(strings.Replace(name, "\\", "/", -1))
+	if name == "." || name == "/" || name == "" {
+		return ""
+	}
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 0; ; i++ {
+		candidate := name
+		if i > 0 {
+			candidate = fmt.Sprintf("%s (%d)%s", base, i, ext)
+		}
+		if _, err := os.Stat(filepath.Join(dir, candidate)); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
+
+// Stop stops streaming events and closes C.
+func (w *DownloadWatcher) Stop() error {
+	w.once.Do(func() { close(w.done) })
+	err := w.conn.Close()
+	w.wg.Wait()
+	return err
+}
@@ -0,0 +1,47 @@
+package webdriver
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUniqueFilename(t *testing.T) {
+	dir, err := ioutil.TempDir("", "downloads")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "report.csv"), nil, 0644)
+	ioutil.WriteFile(filepath.Join(dir, "report (1).csv"), nil, 0644)
+
+	tests := map[string]string{
+		"data.json":        "data.json",
+		"report.csv":       "report (2).csv",
+		"../../etc/passwd": "passwd",
+		`C:\Users\x\a.txt`: "a.txt",
+		"":                 "",
+	}
+	for name, want := range tests {
+		if got := uniqueFilename(dir, name); got != want {
+			t.Errorf("uniqueFilename(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestDownloadFilename(t *testing.T) {
+	tests := []struct {
+		disposition, url, want string
+	}{
+		{`attachment; filename="report 2024.csv"`, "https://example.com/export?id=1", "report 2024.csv"},
+		{`attachment; filename="../../evil.sh"`, "https://example.com/x", "evil.sh"},
+		{"", "https://example.com/files/data.zip?token=1", "data.zip"},
+		{"attachment", "https://example.com/", ""},
+	}
+	for _, test := range tests {
+		if got := downloadFilename(test.disposition, test.url); got != test.want {
+			t.Errorf("downloadFilename(%q, %q) = %q, want %q", test.disposition, test.url, got, test.want)
+		}
+	}
+}
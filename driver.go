@@ -0,0 +1,225 @@
+package webdriver
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// DriverKind identifies the WebDriver server implementation managed by Init.
+type DriverKind string
+
+// The supported WebDriver servers.
+const (
+	// ChromeDriver drives Chrome. The binary is located through the
+	// CHROME_DRIVER environment variable.
+	ChromeDriver DriverKind = "chromedriver"
+	// EdgeDriver drives Microsoft Edge. The binary is located through the
+	// EDGE_DRIVER environment variable.
+	EdgeDriver DriverKind = "msedgedriver"
+	// SafariDriver drives Safari on macOS. The binary is located through the
+	// SAFARI_DRIVER environment variable, defaulting to /usr/bin/safaridriver.
+	SafariDriver DriverKind = "safaridriver"
+	// AppiumDriver drives Chrome on an Android device through Appium. The
+	// binary is located through the APPIUM environment variable, defaulting
+	// to appium in PATH. See WithAppium for selecting the device.
+	AppiumDriver DriverKind = "appium"
+)
+
+// InitOption configures Init.
+type InitOption func(*initConfig)
+
+type initConfig struct {
+	kind   DriverKind
+	docker *DockerOptions
+	cloud  *CloudOptions
+	shards *ShardOptions
+
+	httpClient *http.Client
+	transport  *TransportOptions
+	driverLog  *DriverLogOptions
+
+	versionCheck  bool
+	browserBinary string
+
+	noSignalHandler bool
+	events          *Events
+}
+
+// WithDriver selects the WebDriver server started by Init. The default is
+// ChromeDriver.
+func WithDriver(kind DriverKind) InitOption {
+	return func(c *initConfig) {
+		c.kind = kind
+	}
+}
+
+// path returns the location of the driver binary.
+func (k DriverKind) path() (string, error) {
+	switch k {
+	case ChromeDriver:
+		path := strings.TrimSpace(os.Getenv("CHROME_DRIVER"))
+		if path == "" {
+			return "", fmt.Errorf("env CHROME_DRIVER is missing")
+		}
+		return path, nil
+	case EdgeDriver:
+		path := strings.TrimSpace(os.Getenv("EDGE_DRIVER"))
+		if path == "" {
+			return "", fmt.Errorf("env EDGE_DRIVER is missing")
+		}
+		return path, nil
+	case SafariDriver:
+		path := strings.TrimSpace(os.Getenv("SAFARI_DRIVER"))
+		if path == "" {
+			path = "/usr/bin/safaridriver"
+		}
+		return path, nil
+	case CDPDriver:
+		return "", nil
+	case AppiumDriver:
+		path := strings.TrimSpace(os.Getenv("APPIUM"))
+		if path == "" {
+			return exec.LookPath("appium")
+		}
+		return path, nil
+	}
+	return "", fmt.Errorf("unsupported driver %q", k)
+}
+
+// newDriver returns an unstarted driver listening on the given port.
+func (k DriverKind) newDriver(path string, port int, logOpts *DriverLogOptions) *driver {
+	switch k {
+	case SafariDriver:
+		return &driver{
+			port: port,
+			addr: fmt.Sprintf("http://localhost:%d", port),
+			cmd:  exec.Command(path, "-p", strconv.Itoa(port)),
+		}
+	case AppiumDriver:
+		return &driver{
+			port: port,
+			addr: fmt.Sprintf("http://localhost:%d/wd/hub", port),
+			cmd:  exec.Command(path, "--port", strconv.Itoa(port), "--base-path", "/wd/hub"),
+		}
+	default:
+		return &driver{
+			port:            port,
+			addr:            fmt.Sprintf("http://localhost:%d/wd/hub", port),
+			shutdownURLPath: "/shutdown",
+			cmd:             exec.Command(path, append([]string{"--port=" + strconv.Itoa(port), "--url-base=wd/hub"}, k.logArgs(logOpts)...)...),
+		}
+	}
+}
+
+// capabilities translates the session settings into the capabilities
+// understood by the driver.
+func (k DriverKind) capabilities(cfg *sessionConfig) (Capabilities, error) {
+	switch k {
+	case SafariDriver:
+		if cfg.headless {
+			return nil, fmt.Errorf("safari does not support headless mode")
+		}
+		if cfg.profile != "" {
+			return nil, fmt.Errorf("safari does not support custom profiles")
+		}
+		if cfg.chromeBinary != "" || len(cfg.extraChromeArgs()) > 0 || len(cfg.excludedSwitches) > 0 {
+			return nil, fmt.Errorf("safari does not support chrome options")
+		}
+		return Capabilities{"browserName": "safari"}, nil
+	case CDPDriver:
+		// Chrome is launched directly, see launchChrome.
+		return Capabilities{"browserName": "chrome"}, nil
+	case AppiumDriver:
+		return appiumCapabilities(cfg)
+	default:
+		caps := Capabilities{"browserName": "chrome"}
+		if k == EdgeDriver {
+			caps["browserName"] = "MicrosoftEdge"
+		}
+
+		chromeCfg := chromeCapabilities{
+			Path: cfg.chromeBinary,
+			Args: []string{
+				fmt.Sprintf("window-size=%v,%v", cfg.width, cfg.height),
+				"disable-notifications",
+			},
+		}
+		if cfg.headless {
+			chromeCfg.Args = append(chromeCfg.Args, "headless")
+		}
+		if cfg.profile != "" {
+			chromeCfg.Args = append(chromeCfg.Args, fmt.Sprintf("user-data-dir=%v", cfg.profile))
+		}
+		args, err := mergeChromeArgs(chromeCfg.Args, cfg.extraChromeArgs(), cfg.excludedSwitches)
+		if err != nil {
+			return nil, err
+		}
+		chromeCfg.Args = args
+		for _, sw := range cfg.excludedSwitches {
+			name, _ := splitFlag(sw)
+			chromeCfg.ExcludeSwitches = append(chromeCfg.ExcludeSwitches, name)
+		}
+
+		if cfg.artifactsDir != "" || cfg.sink != nil {
+			// Collect the console output for the failure artifacts.
+			caps.SetLogLevel(Browser, All)
+		}
+
+		if k == EdgeDriver {
+			caps.AddEdge(chromeCfg)
+		} else {
+			caps.AddChrome(chromeCfg)
+		}
+		return caps, nil
+	}
+}
+
+// setup applies the settings that cannot be expressed as capabilities to a
+// newly created session.
+func (k DriverKind) setup(wd WebDriver, cfg *sessionConfig) error {
+	switch k {
+	case SafariDriver:
+		if cfg.stealth != nil {
+			return fmt.Errorf("stealth mode requires a Chromium-based browser")
+		}
+		if cfg.dialogs != nil {
+			return fmt.Errorf("dialog policies require a Chromium-based browser")
+		}
+		if cfg.profileState != nil {
+			return fmt.Errorf("profile states require a Chromium-based browser")
+		}
+		return wd.ResizeWindow("", cfg.width, cfg.height)
+	}
+
+	var scripts []string
+	if cfg.stealth != nil {
+		script, err := cfg.stealth.script(cfg.profile)
+		if err != nil {
+			return err
+		}
+		scripts = append(scripts, script)
+	}
+	if cfg.dialogs != nil {
+		script, err := cfg.dialogs.script()
+		if err != nil {
+			return err
+		}
+		scripts = append(scripts, script)
+	}
+	for _, script := range scripts {
+		if script == "" {
+			continue
+		}
+		if err := addInitScript(wd, script); err != nil {
+			return err
+		}
+	}
+	if cfg.profileState != nil {
+		return cfg.profileState.restore(wd)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package webdriver
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// DriverLogOptions configures the log of the driver started by Init.
+type DriverLogOptions struct {
+	// Level is the verbosity of ChromeDriver and EdgeDriver. The default,
+	// without WithDriverLog, is All, as with --verbose.
+	Level LogLevel
+	// Path is the file the driver output is written to. When empty the
+	// output is discarded, unless debugging is enabled.
+	Path string
+	// MaxSize is the size in bytes above which the log file is rotated. Zero
+	// disables rotation.
+	MaxSize int64
+	// MaxBackups is the number of rotated files kept, named Path.1 for the
+	// most recent to Path.MaxBackups. It defaults to 3.
+	MaxBackups int
+}
+
+// WithDriverLog sets the log level and file of the driver started by Init.
+// The log is rotated by the package, so that long runs do not fill the disk.
+func WithDriverLog(opts DriverLogOptions) InitOption {
+	return func(c *initConfig) {
+		c.driverLog = &opts
+	}
+}
+
+// logArgs returns the driver flags setting the log level.
+func (k DriverKind) logArgs(opts *DriverLogOptions) []string {
+	switch k {
+	case SafariDriver, AppiumDriver:
+		return nil
+	}
+	if opts == nil || opts.Level == "" || opts.Level == All {
+		return []string{"--verbose"}
+	}
+	return []string{"--log-level=" + string(opts.Level)}
+}
+
+// rotatingFile is a log file rotated once it exceeds its maximum size.
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+func openRotatingFile(path string, maxSize int64, maxBackups int) (*rotatingFile, error) {
+	if maxBackups <= 0 {
+		maxBackups = 3
+	}
+	r := &rotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f = f
+	r.size = fi.Size()
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return 0, fmt.Errorf("log file %v is closed", r.path)
+	}
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the backups by one and starts a new file.
+func (r *rotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	r.f = nil
+	os.Remove(fmt.Sprintf("%s.%d", r.path, r.maxBackups))
+	for i := r.maxBackups - 1; i > 0; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	if err := os.Rename(r.path, r.path+".1"); err != nil {
+		return err
+	}
+	return r.open()
+}
+
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
@@ -0,0 +1,30 @@
+package webdriver
+
+import "fmt"
+
+// SetCPUThrottling slows down the page's CPU by the given factor, e.g. 4 runs
+// scripts four times slower, to reproduce the behavior of slow devices. A
+// rate of 1 disables throttling.
+func (s *Session) SetCPUThrottling(rate float64) error {
+	if rate < 1 {
+		return fmt.Errorf("invalid CPU throttling rate %v, must be >= 1", rate)
+	}
+	return s.cdp("Emulation.setCPUThrottlingRate", map[string]interface{}{
+		"rate": rate,
+	}, nil)
+}
+
+// SetOffline cuts the page off the network, or restores it, to test how a
+// site behaves without connectivity. Navigations and requests fail with
+// net::ERR_INTERNET_DISCONNECTED while offline.
+func (s *Session) SetOffline(offline bool) error {
+	if err := s.cdp("Network.enable", nil, nil); err != nil {
+		return err
+	}
+	return s.cdp("Network.emulateNetworkConditions", map[string]interface{}{
+		"offline":            offline,
+		"latency":            0,
+		"downloadThroughput": -1,
+		"uploadThroughput":   -1,
+	}, nil)
+}
@@ -0,0 +1,140 @@
+package webdriver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Driver error codes, as defined by the W3C specification, that indicate a
+// transient condition: retrying the operation shortly is expected to succeed.
+var retryableErrors = map[string]bool{
+	"stale element reference":   true,
+	"element click intercepted": true,
+	"element not interactable":  true,
+}
+
+// Legacy JSON wire protocol equivalents of retryableErrors.
+var retryableLegacyCodes = map[int]bool{
+	10: true, // stale element reference
+	11: true, // element not visible
+}
+
+// IsRetryable reports whether err is a transient failure worth retrying: a
+// stale element, a click intercepted by another element, an element that is
+// not yet interactable, or a server error from the driver without details.
+// GetDOM, GetDOMs and ClickDOM retry these errors until their timeout.
+func IsRetryable(err error) bool {
+	switch e := errors.Cause(err).(type) {
+	case nil:
+		return false
+	case *Error:
+		return retryableErrors[e.Err] || retryableLegacyCodes[e.LegacyCode]
+	case *statusError:
+		switch e.code {
+		case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+	}
+	return StaleElement(err)
+}
+
+// IsTimeout reports whether err is caused by a timeout: an expired wait of
+// this package, a driver-side page load or script timeout, or a network
+// timeout talking to the driver.
+func IsTimeout(err error) bool {
+	switch e := errors.Cause(err).(type) {
+	case nil:
+		return false
+	case *Error:
+		return e.Err == "timeout" || e.Err == "script timeout" || e.LegacyCode == 21 || e.LegacyCode == 28
+	case net.Error:
+		return e.Timeout()
+	default:
+		return e == ErrWaitTimeout || e == context.DeadlineExceeded
+	}
+}
+
+// IsSessionDead reports whether err indicates that the session cannot be used
+// anymore: the driver does not know the session, the browser crashed or was
+// closed, or the driver itself is unreachable.
+func IsSessionDead(err error) bool {
+	switch e := errors.Cause(err).(type) {
+	case nil:
+		return false
+	case *Error:
+		if e.Err == "invalid session id" || e.LegacyCode == 6 {
+			return true
+		}
+		msg := strings.ToLower(e.Message)
+		return strings.Contains(msg, "chrome not reachable") || strings.Contains(msg, "disconnected") || strings.Contains(msg, "session deleted")
+	case *net.OpError:
+		return e.Op == "dial"
+	default:
+		if ue, ok := e.(interface{ Unwrap() error }); ok {
+			return IsSessionDead(ue.Unwrap())
+		}
+		return false
+	}
+}
+
+// TimeoutError is returned by the waits of the package when they time out.
+// It matches ErrWaitTimeout via errors.Is and errors.Cause.
+type TimeoutError struct {
+	// Op is the operation that waited, e.g. "GetDOM", and Selector what it
+	// waited for. They are empty for waits outside of a session operation.
+	Op       string
+	Selector string
+	// Elapsed is the duration of the wait and Polls the number of times the
+	// condition was evaluated.
+	Elapsed time.Duration
+	Polls   int
+	// LastErr is the last transient error met while polling, e.g.
+	// ErrNotFound, if any.
+	LastErr error
+}
+
+func (e *TimeoutError) Error() string {
+	msg := ErrWaitTimeout.Error()
+	if e.Op != "" {
+		msg += ": " + e.Op
+		if e.Selector != "" {
+			msg += " " + e.Selector
+		}
+	}
+	msg += fmt.Sprintf(" after %v (%d polls)", e.Elapsed.Round(time.Millisecond), e.Polls)
+	if e.LastErr != nil {
+		msg += fmt.Sprintf(": last error: %v", e.LastErr)
+	}
+	return msg
+}
+
+// Is reports whether target is ErrWaitTimeout, for errors.Is.
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrWaitTimeout
+}
+
+// Cause returns ErrWaitTimeout, for errors.Cause.
+func (e *TimeoutError) Cause() error {
+	return ErrWaitTimeout
+}
+
+// retryError marks an error returned by a waitOn condition as transient.
+type retryError struct {
+	err error
+}
+
+func (e *retryError) Error() string {
+	return e.err.Error()
+}
+
+// retryWith makes a waitOn condition keep polling, recording err as the last
+// error of the wait.
+func retryWith(err error) error {
+	return &retryError{err}
+}
@@ -0,0 +1,55 @@
+package webdriver
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestErrorClassifiers(t *testing.T) {
+	dialErr := &url.Error{Op: "Post", URL: "http://localhost:9090", Err: &net.OpError{Op: "dial", Err: fmt.Errorf("connection refused")}}
+
+	for _, tc := range []struct {
+		err                      error
+		retryable, timeout, dead bool
+	}{
+		{nil, false, false, false},
+		{&Error{Err: "stale element reference"}, true, false, false},
+		{&Error{Err: "element click intercepted"}, true, false, false},
+		{&Error{Err: "no such element"}, false, false, false},
+		{&Error{LegacyCode: 10}, true, false, false},
+		{&statusError{503, "503 Service Unavailable"}, true, false, false},
+		{&statusError{404, "404 Not Found"}, false, false, false},
+		{&Error{Err: "script timeout"}, false, true, false},
+		{errors.Wrap(ErrWaitTimeout, "stack"), false, true, false},
+		{&TimeoutError{LastErr: ErrNotFound}, false, true, false},
+		{&Error{Err: "invalid session id"}, false, false, true},
+		{&Error{Err: "unknown error", Message: "unknown error: Chrome not reachable"}, false, false, true},
+		{dialErr, false, false, true},
+	} {
+		if got := IsRetryable(tc.err); got != tc.retryable {
+			t.Errorf("IsRetryable(%v) = %v, want %v", tc.err, got, tc.retryable)
+		}
+		if got := IsTimeout(tc.err); got != tc.timeout {
+			t.Errorf("IsTimeout(%v) = %v, want %v", tc.err, got, tc.timeout)
+		}
+		if got := IsSessionDead(tc.err); got != tc.dead {
+			t.Errorf("IsSessionDead(%v) = %v, want %v", tc.err, got, tc.dead)
+		}
+	}
+}
+
+func TestTimeoutError(t *testing.T) {
+	err := error(&TimeoutError{Op: "GetDOM", Selector: "//button", Elapsed: 10 * time.Second, Polls: 10, LastErr: ErrNotFound})
+	if !stderrors.Is(err, ErrWaitTimeout) {
+		t.Errorf("errors.Is(%v, ErrWaitTimeout) = false", err)
+	}
+	if want := "wait timed out: GetDOM //button after 10s (10 polls): last error: element not found"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
@@ -0,0 +1,48 @@
+//go:build go1.18
+
+package webdriver
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// EvalInto executes script in the page with args and decodes its result into
+// a T, e.g. a string, an int, a bool, a []string, a map or a struct with JSON
+// tags, sparing the type assertions of ExecuteScript. A null result is the
+// zero value for pointers, slices, maps and interfaces, and an error
+// otherwise.
+func EvalInto[T any](s *Session, script string, args ...any) (T, error) {
+	if args == nil {
+		args = []any{}
+	}
+	data, err := s.ExecuteScriptRaw(script, args)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return decodeScriptResult[T](data)
+}
+
+// decodeScriptResult decodes the value of a script reply into a T.
+func decodeScriptResult[T any](data []byte) (T, error) {
+	var reply struct {
+		Value json.RawMessage
+	}
+	var ret T
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return ret, err
+	}
+	if len(reply.Value) == 0 || string(reply.Value) == "null" {
+		switch reflect.TypeOf(&ret).Elem().Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+			return ret, nil
+		}
+		return ret, fmt.Errorf("script returned null, want %T", ret)
+	}
+	if err := json.Unmarshal(reply.Value, &ret); err != nil {
+		return ret, fmt.Errorf("error decoding script result: %v", err)
+	}
+	return ret, nil
+}
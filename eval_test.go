@@ -0,0 +1,29 @@
+//go:build go1.18
+
+package webdriver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeScriptResult(t *testing.T) {
+	if got, err := decodeScriptResult[int]([]byte(`{"value": 42}`)); err != nil || got != 42 {
+		t.Errorf("int = %v, %v", got, err)
+	}
+	if got, err := decodeScriptResult[[]string]([]byte(`{"value": ["a", "b"]}`)); err != nil || !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("[]string = %v, %v", got, err)
+	}
+	if got, err := decodeScriptResult[map[string]bool]([]byte(`{"value": {"ok": true}}`)); err != nil || !got["ok"] {
+		t.Errorf("map = %v, %v", got, err)
+	}
+	if got, err := decodeScriptResult[[]string]([]byte(`{"value": null}`)); err != nil || got != nil {
+		t.Errorf("null []string = %v, %v", got, err)
+	}
+	if _, err := decodeScriptResult[string]([]byte(`{"value": null}`)); err == nil {
+		t.Error("null string succeeded, want error")
+	}
+	if _, err := decodeScriptResult[int]([]byte(`{"value": "x"}`)); err == nil {
+		t.Error("string as int succeeded, want error")
+	}
+}
@@ -0,0 +1,82 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Events are callbacks notified of the lifecycle of the sessions, e.g. to
+// update dashboards or quota counters. Any of them may be nil. They run
+// synchronously on the goroutine causing the event.
+type Events struct {
+	// OnSessionCreated is called once New succeeded.
+	OnSessionCreated func(s *Session)
+	// OnSessionClosed is called when a session is closed, by Close or
+	// Shutdown.
+	OnSessionClosed func(s *Session)
+	// OnNavigation is called after the session navigated to url with Get.
+	OnNavigation func(s *Session, url string)
+	// OnCommandError is called when the driver fails a command.
+	OnCommandError func(s *Session, cmd *Command, err error)
+	// OnDriverRestart is called after the browser of a session was replaced
+	// by Restart, e.g. by the watchdog, with the error of the restart.
+	OnDriverRestart func(s *Session, err error)
+}
+
+// WithEvents makes Init register callbacks notified of the lifecycle of the
+// sessions created afterwards.
+func WithEvents(ev Events) InitOption {
+	return func(c *initConfig) {
+		c.events = &ev
+	}
+}
+
+// hook returns the command hook reporting the navigations and command
+// errors of s.
+func (ev *Events) hook(s *Session) Hook {
+	return Hook{
+		After: func(cmd *Command, result []byte, err error, elapsed time.Duration) {
+			if err != nil {
+				if ev.OnCommandError != nil {
+					ev.OnCommandError(s, cmd, err)
+				}
+				return
+			}
+			if ev.OnNavigation != nil && isNavigation(cmd) {
+				params := struct {
+					URL string `json:"url"`
+				}{}
+				if json.Unmarshal(cmd.Params, &params) == nil {
+					ev.OnNavigation(s, params.URL)
+				}
+			}
+		},
+	}
+}
+
+// isNavigation reports whether cmd navigates to the URL in its params.
+func isNavigation(cmd *Command) bool {
+	if cmd.Method == cdpCommand {
+		return cmd.URL == "Page.navigate"
+	}
+	return cmd.Method == "POST" && strings.HasSuffix(cmd.URL, "/url")
+}
+
+func (ev *Events) sessionCreated(s *Session) {
+	if ev != nil && ev.OnSessionCreated != nil {
+		ev.OnSessionCreated(s)
+	}
+}
+
+func (ev *Events) sessionClosed(s *Session) {
+	if ev != nil && ev.OnSessionClosed != nil {
+		ev.OnSessionClosed(s)
+	}
+}
+
+func (ev *Events) driverRestart(s *Session, err error) {
+	if ev != nil && ev.OnDriverRestart != nil {
+		ev.OnDriverRestart(s, err)
+	}
+}
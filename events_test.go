@@ -0,0 +1,43 @@
+package webdriver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEventsHook(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		if r.URL.Path == "/session/1/refresh" {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"value": {"error": "unknown error", "message": "boom"}}`))
+			return
+		}
+		w.Write([]byte(`{"value": null}`))
+	}))
+	defer srv.Close()
+
+	var navigated []string
+	var failed []string
+	ev := &Events{
+		OnNavigation:   func(s *Session, url string) { navigated = append(navigated, url) },
+		OnCommandError: func(s *Session, cmd *Command, err error) { failed = append(failed, cmd.URL) },
+	}
+	wd := &remoteWD{urlPrefix: srv.URL, id: "1"}
+	s := &Session{WebDriver: wd}
+	s.Use(ev.hook(s))
+
+	if err := wd.Get("https://example.com/"); err != nil {
+		t.Fatal(err)
+	}
+	if err := wd.Refresh(); err == nil {
+		t.Fatal("Refresh succeeded, want error")
+	}
+	if len(navigated) != 1 || navigated[0] != "https://example.com/" {
+		t.Errorf("navigations = %v", navigated)
+	}
+	if len(failed) != 1 || failed[0] != srv.URL+"/session/1/refresh" {
+		t.Errorf("command errors = %v", failed)
+	}
+}
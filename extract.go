@@ -0,0 +1,54 @@
+package webdriver
+
+import "encoding/json"
+
+const extractScript = `var items = document.evaluate(arguments[0], document, null, XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null);
+var fields = arguments[1], ret = [];
+function value(n) {
+	if (n.nodeType !== Node.ELEMENT_NODE) {
+		return n.nodeValue;
+	}
+	return (n.innerText === undefined ? n.textContent : n.innerText).trim();
+}
+for (var i = 0; i < items.snapshotLength; i++) {
+	var item = items.snapshotItem(i), row = {};
+	for (var name in fields) {
+		var n = document.evaluate(fields[name], item, null, XPathResult.FIRST_ORDERED_NODE_TYPE, null).singleNodeValue;
+		if (n) {
+			row[name] = value(n);
+		}
+	}
+	ret.push(row);
+}
+return ret;`
+
+// ExtractAll waits for elements matching xpath and returns, for each of them
+// in document order, the values of fields in a single round trip to the
+// driver. fields maps names to xpaths evaluated relative to each element,
+// e.g. "." for the element itself, ".//h2" or ".//a/@href". The value of an
+// element is its trimmed text, the value of an attribute or text node its
+// content. Fields that do not match are omitted from the element's map.
+func (s *Session) ExtractAll(xpath string, fields map[string]string) ([]map[string]string, error) {
+	var ret []map[string]string
+	err := s.waitOn(func() (bool, error) {
+		data, err := s.ExecuteScriptRaw(extractScript, []interface{}{xpath, fields})
+		if IsRetryable(err) {
+			return false, retryWith(err)
+		} else if err != nil {
+			return true, err
+		}
+		reply := struct {
+			Value []map[string]string
+		}{}
+		if err := json.Unmarshal(data, &reply); err != nil {
+			return true, err
+		}
+		if len(reply.Value) == 0 {
+			return false, retryWith(ErrNotFound)
+		}
+		ret = reply.Value
+		return true, nil
+	}, s.timeout)
+
+	return ret, s.fail("ExtractAll", xpath, err)
+}
@@ -0,0 +1,117 @@
+package webdriver
+
+import (
+	"strings"
+)
+
+// Locator locates elements by an xpath or a CSS selector. Lists of locators
+// form fallback chains for one logical element, e.g. to cope with A/B tested
+// variants of a page: see GetFirst.
+type Locator struct {
+	// By is ByXPATH or ByCSSSelector.
+	By    string
+	Value string
+}
+
+// XPathLocator locates elements by xpath.
+func XPathLocator(xpath string) Locator {
+	return Locator{By: ByXPATH, Value: xpath}
+}
+
+// CSSLocator locates elements by CSS selector. Elements located by CSS cannot
+// be relocated, see Element.Relocate.
+func CSSLocator(selector string) Locator {
+	return Locator{By: ByCSSSelector, Value: selector}
+}
+
+// TextLocator locates elements by their text, see TextXPath.
+func TextLocator(text string, opts TextOptions) Locator {
+	return XPathLocator(TextXPath(text, opts))
+}
+
+// ParseLocator parses the textual form of a locator: a CSS selector prefixed
+// with "css:", a text prefixed with "text:", or an xpath, optionally prefixed
+// with "xpath:".
+func ParseLocator(s string) Locator {
+	switch {
+	case strings.HasPrefix(s, "css:"):
+		return CSSLocator(strings.TrimSpace(s[len("css:"):]))
+	case strings.HasPrefix(s, "text:"):
+		return TextLocator(strings.TrimSpace(s[len("text:"):]), TextOptions{})
+	case strings.HasPrefix(s, "xpath:"):
+		return XPathLocator(strings.TrimSpace(s[len("xpath:"):]))
+	}
+	return XPathLocator(s)
+}
+
+func (l Locator) String() string {
+	if l.By == ByCSSSelector {
+		return "css:" + l.Value
+	}
+	return l.Value
+}
+
+func xpathLocators(xpaths []string) []Locator {
+	locators := make([]Locator, len(xpaths))
+	for i, xpath := range xpaths {
+		locators[i] = XPathLocator(xpath)
+	}
+	return locators
+}
+
+func locatorsString(locators []Locator) string {
+	var parts []string
+	for _, l := range locators {
+		parts = append(parts, l.String())
+	}
+	return strings.Join(parts, " | ")
+}
+
+// GetFirst waits until one of locators matches, trying them in order at each
+// poll within a single wait budget, and returns the element matched by the
+// first matching locator along with its index.
+func (s *Session) GetFirst(locators ...Locator) (*Element, int, error) {
+	return s.getFirst("GetFirst", locators)
+}
+
+// GetFirst is Session.GetFirst for the descendants of e.
+func (e *Element) GetFirst(locators ...Locator) (*Element, int, error) {
+	return e.getFirst("GetFirst", locators)
+}
+
+// xpath returns the xpath of l, or "" if l is not an xpath, for elements
+// located by l to relocate themselves.
+func (l Locator) xpath() string {
+	if l.By == ByXPATH {
+		return l.Value
+	}
+	return ""
+}
+
+// locate finds the first element matched by l.
+func (s *Session) locate(l Locator) (*Element, error) {
+	if l.By == ByXPATH {
+		return s.find(l.Value)
+	}
+	elem, err := s.FindElement(l.By, l.Value)
+	if notFound(err) || err == nil && elem == nil {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &Element{s: s, WebElement: elem}, nil
+}
+
+// locate finds the first descendant of e matched by l.
+func (e *Element) locate(l Locator) (*Element, error) {
+	if l.By == ByXPATH {
+		return e.find(l.Value)
+	}
+	elem, err := e.WebElement.FindElement(l.By, l.Value)
+	if notFound(err) || err == nil && elem == nil {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &Element{s: e.s, WebElement: elem, frame: e.frame, framed: e.framed}, nil
+}
@@ -0,0 +1,113 @@
+package webdriver
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// FetchOptions configures Session.Fetch, as the init argument of fetch().
+type FetchOptions struct {
+	// Method defaults to GET.
+	Method  string
+	Headers map[string]string
+	Body    []byte
+	// Credentials is "omit", "same-origin" or "include". It defaults to
+	// "same-origin", sending the cookies of the page to its origin only.
+	Credentials string
+}
+
+// FetchResponse is the response of Session.Fetch.
+type FetchResponse struct {
+	// URL is the final URL, after redirects.
+	URL        string
+	Status     int
+	StatusText string
+	Headers    map[string]string
+	Body       []byte
+}
+
+// JSON decodes the body into v.
+func (r *FetchResponse) JSON(v interface{}) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// Fetch runs fetch() in the page and returns the response, so that endpoints
+// rejecting non-browser clients can be queried with the cookies, origin and
+// headers of the page. url is resolved against the page URL. Requests to
+// other origins are subject to CORS. The request is bounded by the async
+// script timeout of the session.
+func (s *Session) Fetch(url string, opts *FetchOptions) (*FetchResponse, error) {
+	if opts == nil {
+		opts = &FetchOptions{}
+	}
+	init := map[string]interface{}{}
+	if opts.Method != "" {
+		init["method"] = opts.Method
+	}
+	if len(opts.Headers) > 0 {
+		init["headers"] = opts.Headers
+	}
+	if opts.Credentials != "" {
+		init["credentials"] = opts.Credentials
+	}
+	var body interface{}
+	if opts.Body != nil {
+		body = base64.StdEncoding.EncodeToString(opts.Body)
+	}
+
+	data, err := s.ExecuteScriptAsyncRaw(`var url = arguments[0], init = arguments[1], body = arguments[2], done = arguments[3];
+if (body !== null) {
+	var bin = atob(body), bytes = new Uint8Array(bin.length);
+	for (var i = 0; i < bin.length; i++) {
+		bytes[i] = bin.charCodeAt(i);
+	}
+	init.body = bytes;
+}
+fetch(url, init).then(function(resp) {
+	return resp.arrayBuffer().then(function(buf) {
+		var bytes = new Uint8Array(buf), bin = "";
+		for (var i = 0; i < bytes.length; i += 0x8000) {
+			bin += String.fromCharCode.apply(null, bytes.subarray(i, i + 0x8000));
+		}
+		var headers = {};
+		resp.headers.forEach(function(v, k) {
+			headers[k] = v;
+		});
+		done({url: resp.url, status: resp.status, statusText: resp.statusText, headers: headers, body: btoa(bin)});
+	});
+}).catch(function(e) {
+	done({error: String(e)});
+});`, []interface{}{url, init, body})
+	if err != nil {
+		return nil, err
+	}
+
+	reply := struct {
+		Value struct {
+			Error      string            `json:"error"`
+			URL        string            `json:"url"`
+			Status     int               `json:"status"`
+			StatusText string            `json:"statusText"`
+			Headers    map[string]string `json:"headers"`
+			Body       string            `json:"body"`
+		}
+	}{}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return nil, err
+	}
+	if reply.Value.Error != "" {
+		return nil, fmt.Errorf("fetch %v: %v", url, reply.Value.Error)
+	}
+	respBody, err := base64.StdEncoding.DecodeString(reply.Value.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &FetchResponse{
+		URL:        reply.Value.URL,
+		Status:     reply.Value.Status,
+		StatusText: reply.Value.StatusText,
+		Headers:    reply.Value.Headers,
+		Body:       respBody,
+	}, nil
+}
@@ -0,0 +1,371 @@
+package webdriver
+
+import (
+	"bufio"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// FilterList is a set of network filter rules in the syntax of EasyList and
+// other Adblock Plus style lists, e.g.
+//
+//	||ads.example.com^
+//	/banner/*/img^$image,third-party
+//	@@||example.com/ads/allowed.js$script,domain=example.com
+//
+// Supported are address patterns with the anchors "|" and "||", the
+// wildcard "*" and the separator "^", regular expressions between slashes,
+// exception rules starting with "@@", and the options match-case,
+// third-party, domain and the resource types script, image, stylesheet,
+// xmlhttprequest, subdocument, document, font, media, websocket, ping and
+// other, each optionally negated with "~". Comments, element hiding rules and
+// rules with other options are skipped, so that lists never block more than
+// intended.
+type FilterList struct {
+	block, allow filterIndex
+	rules        int
+}
+
+// filterIndex indexes rules by a token of their pattern, so that a request is
+// matched only against the rules sharing a token with its URL.
+type filterIndex struct {
+	hosts   map[string][]*filterRule
+	tokens  map[string][]*filterRule
+	generic []*filterRule
+}
+
+type filterRule struct {
+	pattern   string
+	matchCase bool
+	isRegexp  bool
+
+	// types maps the resource types the rule applies to, nil for all but
+	// the document.
+	types map[string]bool
+	// thirdParty is 1 for third-party requests only, -1 for first-party
+	// requests only.
+	thirdParty      int
+	domains, except []string
+
+	once sync.Once
+	re   *regexp.Regexp
+}
+
+// filterTypes maps the resource types of filter rules to the resource types
+// of DevTools requests. "subdocument" and "document" are told apart by the
+// frame of the request.
+var filterTypes = map[string][]string{
+	"script":         {"Script"},
+	"image":          {"Image"},
+	"stylesheet":     {"Stylesheet"},
+	"xmlhttprequest": {"XHR", "Fetch"},
+	"subdocument":    {"subdocument"},
+	"document":       {"Document"},
+	"font":           {"Font"},
+	"media":          {"Media"},
+	"websocket":      {"WebSocket"},
+	"ping":           {"Ping"},
+	"other":          {"Other", "EventSource", "Manifest", "TextTrack", "Prefetch", "CSPViolationReport"},
+}
+
+var filterTokenRe = regexp.MustCompile(`[a-zA-Z0-9%]{3,}`)
+
+// LoadFilterList reads a filter list from a file.
+func LoadFilterList(path string) (*FilterList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseFilterList(f)
+}
+
+// ParseFilterList reads a filter list, see FilterList. Several lists can be
+// read into one with Add.
+func ParseFilterList(r io.Reader) (*FilterList, error) {
+	l := &FilterList{}
+	return l, l.Add(r)
+}
+
+// Add adds the rules read from r to the list.
+func (l *FilterList) Add(r io.Reader) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '!' || line[0] == '[' || strings.Contains(line, "##") || strings.Contains(line, "#@#") || strings.Contains(line, "#?#") {
+			continue
+		}
+
+		idx := &l.block
+		if strings.HasPrefix(line, "@@") {
+			idx = &l.allow
+			line = line[2:]
+		}
+		rule, ok := parseFilterRule(line)
+		if !ok {
+			continue
+		}
+		idx.add(rule)
+		l.rules++
+	}
+	return sc.Err()
+}
+
+// Len returns the number of rules of the list.
+func (l *FilterList) Len() int {
+	return l.rules
+}
+
+func parseFilterRule(line string) (*filterRule, bool) {
+	r := &filterRule{pattern: line}
+	if i := strings.LastIndex(line, "$"); i >= 0 && !(strings.HasPrefix(line, "/") && strings.HasSuffix(line, "/")) {
+		r.pattern = line[:i]
+		for _, opt := range strings.Split(line[i+1:], ",") {
+			if !r.option(strings.TrimSpace(opt)) {
+				return nil, false
+			}
+		}
+	}
+
+	if len(r.pattern) > 2 && strings.HasPrefix(r.pattern, "/") && strings.HasSuffix(r.pattern, "/") {
+		r.isRegexp = true
+		r.pattern = r.pattern[1 : len(r.pattern)-1]
+		if _, err := regexp.Compile(r.pattern); err != nil {
+			return nil, false
+		}
+	}
+	return r, true
+}
+
+// option applies a rule option and reports whether it is supported.
+func (r *filterRule) option(opt string) bool {
+	switch {
+	case opt == "match-case":
+		r.matchCase = true
+	case opt == "third-party":
+		r.thirdParty = 1
+	case opt == "~third-party":
+		r.thirdParty = -1
+	case strings.HasPrefix(opt, "domain="):
+		for _, d := range strings.Split(opt[len("domain="):], "|") {
+			if strings.HasPrefix(d, "~") {
+				r.except = append(r.except, strings.ToLower(d[1:]))
+			} else if d != "" {
+				r.domains = append(r.domains, strings.ToLower(d))
+			}
+		}
+	default:
+		negated := strings.HasPrefix(opt, "~")
+		types, ok := filterTypes[strings.TrimPrefix(opt, "~")]
+		if !ok {
+			return false
+		}
+		if r.types == nil {
+			r.types = map[string]bool{}
+			if negated {
+				// Negated types start from all types but the document.
+				for name, t := range filterTypes {
+					for _, typ := range t {
+						r.types[typ] = name != "document"
+					}
+				}
+			}
+		}
+		for _, typ := range types {
+			r.types[typ] = !negated
+		}
+	}
+	return true
+}
+
+func (idx *filterIndex) add(r *filterRule) {
+	if !r.isRegexp && strings.HasPrefix(r.pattern, "||") {
+		// Most rules block a whole host, e.g. ||ads.example.com^.
+		host := strings.TrimSuffix(r.pattern[2:], "^")
+		if host != "" && !strings.ContainsAny(host, "/*^|:?") {
+			if idx.hosts == nil {
+				idx.hosts = map[string][]*filterRule{}
+			}
+			host = strings.ToLower(host)
+			idx.hosts[host] = append(idx.hosts[host], r)
+			return
+		}
+	}
+
+	if !r.isRegexp {
+		// The longest token of the pattern that is a whole token of the
+		// URLs matched: neither at an end of the pattern nor next to a
+		// wildcard, which could extend it.
+		best := ""
+		for _, loc := range filterTokenRe.FindAllStringIndex(r.pattern, -1) {
+			if loc[0] == 0 || loc[1] == len(r.pattern) || r.pattern[loc[0]-1] == '*' || r.pattern[loc[1]] == '*' {
+				continue
+			}
+			if tok := r.pattern[loc[0]:loc[1]]; len(tok) > len(best) {
+				best = tok
+			}
+		}
+		if best != "" {
+			if idx.tokens == nil {
+				idx.tokens = map[string][]*filterRule{}
+			}
+			best = strings.ToLower(best)
+			idx.tokens[best] = append(idx.tokens[best], r)
+			return
+		}
+	}
+	idx.generic = append(idx.generic, r)
+}
+
+// filterRequest is a request matched against a filter list.
+type filterRequest struct {
+	url, host string
+	// source is the host of the page issuing the request, if known.
+	source string
+	typ    string
+}
+
+func (idx *filterIndex) match(req *filterRequest) bool {
+	for h := req.host; h != ""; {
+		for _, r := range idx.hosts[h] {
+			if r.applies(req) {
+				return true
+			}
+		}
+		i := strings.IndexByte(h, '.')
+		if i < 0 {
+			break
+		}
+		h = h[i+1:]
+	}
+
+	for _, tok := range filterTokenRe.FindAllString(strings.ToLower(req.url), -1) {
+		for _, r := range idx.tokens[tok] {
+			if r.applies(req) && r.matches(req.url) {
+				return true
+			}
+		}
+	}
+	for _, r := range idx.generic {
+		if r.applies(req) && r.matches(req.url) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether the list blocks a request for rawURL of a resource
+// type of DevTools, e.g. "Script" or "Image", issued by the page at pageURL,
+// which may be empty if unknown.
+func (l *FilterList) Match(rawURL, resourceType, pageURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	req := &filterRequest{url: rawURL, host: strings.ToLower(u.Hostname()), typ: resourceType}
+	if p, err := url.Parse(pageURL); err == nil {
+		req.source = strings.ToLower(p.Hostname())
+	}
+	return l.block.match(req) && !l.allow.match(req)
+}
+
+// applies reports whether the options of the rule admit the request.
+func (r *filterRule) applies(req *filterRequest) bool {
+	if r.types == nil {
+		if req.typ == "Document" {
+			return false
+		}
+	} else if !r.types[req.typ] {
+		return false
+	}
+
+	if r.thirdParty != 0 {
+		third := req.source == "" || baseDomain(req.host) != baseDomain(req.source)
+		if third != (r.thirdParty > 0) {
+			return false
+		}
+	}
+
+	for _, d := range r.except {
+		if domainOf(req.source, d) {
+			return false
+		}
+	}
+	if len(r.domains) > 0 {
+		for _, d := range r.domains {
+			if domainOf(req.source, d) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func (r *filterRule) matches(u string) bool {
+	r.once.Do(func() {
+		expr := r.pattern
+		if !r.isRegexp {
+			expr = filterRegexp(r.pattern)
+		}
+		if !r.matchCase {
+			expr = "(?i)" + expr
+		}
+		r.re = regexp.MustCompile(expr)
+	})
+	return r.re.MatchString(u)
+}
+
+// filterRegexp translates an address pattern into a regular expression.
+func filterRegexp(pattern string) string {
+	var b strings.Builder
+	switch {
+	case strings.HasPrefix(pattern, "||"):
+		b.WriteString(`^[a-zA-Z][a-zA-Z0-9+.-]*://([^/?#]*\.)?`)
+		pattern = pattern[2:]
+	case strings.HasPrefix(pattern, "|"):
+		b.WriteString("^")
+		pattern = pattern[1:]
+	}
+	end := strings.HasSuffix(pattern, "|")
+	pattern = strings.TrimSuffix(pattern, "|")
+
+	for _, c := range pattern {
+		switch c {
+		case '*':
+			b.WriteString(".*")
+		case '^':
+			b.WriteString(`(?:[^a-zA-Z0-9_.%-]|$)`)
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if end {
+		b.WriteString("$")
+	}
+	return b.String()
+}
+
+// baseDomain approximates the registrable domain of host by its last two
+// labels, or three if the second to last is short, as in example.co.uk.
+func baseDomain(host string) string {
+	labels := strings.Split(host, ".")
+	n := 2
+	if len(labels) > 2 && len(labels[len(labels)-2]) <= 3 && len(labels[len(labels)-1]) == 2 {
+		n = 3
+	}
+	if len(labels) <= n {
+		return host
+	}
+	return strings.Join(labels[len(labels)-n:], ".")
+}
+
+// domainOf reports whether host is domain or one of its subdomains.
+func domainOf(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
@@ -0,0 +1,72 @@
+package webdriver
+
+import (
+	"strings"
+	"testing"
+)
+
+const testFilters = `[Adblock Plus 2.0]
+! Title: test list
+||ads.example.net^
+/banner/*/img^$image
+-ad-frame.$subdocument
+||tracker.io/pixel.gif$third-party
+|https://cdn.example.org/ads.js|
+/\/pop[0-9]+\.js/$script
+@@||ads.example.net/allowed^
+||video.example.com^$media,domain=news.example.com|~sport.news.example.com
+example.com##.ad-banner
+||unsupported.example.com^$csp=script-src
+`
+
+func TestFilterList(t *testing.T) {
+	l, err := ParseFilterList(strings.NewReader(testFilters))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Len() != 8 {
+		t.Errorf("Len = %d, want 8", l.Len())
+	}
+
+	page := "https://news.example.com/article"
+	for _, c := range []struct {
+		url, typ, page string
+		want           bool
+	}{
+		{"https://ads.example.net/x.js", "Script", page, true},
+		{"https://a.ads.example.net/x.js", "Image", page, true},
+		{"https://ads.example.net/x.js", "Document", page, false},
+		{"https://ads.example.net/allowed/x.js", "Script", page, false},
+		{"https://badads.example.net/x.js", "Script", page, false},
+		{"https://example.com/banner/1/img?id=2", "Image", page, true},
+		{"https://example.com/banner/1/img.png", "Script", page, false},
+		{"https://example.com/x-ad-frame.html", "subdocument", page, true},
+		{"https://tracker.io/pixel.gif", "Image", page, true},
+		{"https://tracker.io/pixel.gif", "Image", "https://www.tracker.io/", false},
+		{"https://cdn.example.org/ads.js", "Script", page, true},
+		{"https://cdn.example.org/ads.js?v=1", "Script", page, false},
+		{"https://example.com/pop12.js", "Script", page, true},
+		{"https://example.com/popup.js", "Script", page, false},
+		{"https://video.example.com/clip.mp4", "Media", page, true},
+		{"https://video.example.com/clip.mp4", "Media", "https://sport.news.example.com/", false},
+		{"https://video.example.com/clip.mp4", "Media", "https://other.org/", false},
+		{"https://unsupported.example.com/", "Script", page, false},
+	} {
+		if got := l.Match(c.url, c.typ, c.page); got != c.want {
+			t.Errorf("Match(%q, %q, %q) = %v, want %v", c.url, c.typ, c.page, got, c.want)
+		}
+	}
+}
+
+func TestBaseDomain(t *testing.T) {
+	for host, want := range map[string]string{
+		"example.com":       "example.com",
+		"a.b.example.com":   "example.com",
+		"www.example.co.uk": "example.co.uk",
+		"localhost":         "localhost",
+	} {
+		if got := baseDomain(host); got != want {
+			t.Errorf("baseDomain(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
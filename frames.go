@@ -0,0 +1,163 @@
+package webdriver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// frameXPath matches the frames of a document.
+const frameXPath = "//iframe | //frame"
+
+// WithFrameSearch makes GetDOM, ClickDOM, WaitElement and the other single
+// element lookups by xpath of the session search the top-level document first
+// and then the document of each frame, depth-first in document order,
+// descending at most depth levels of nested frames. An element found in a frame remembers its
+// frame path, see Element.FramePath, and the session switches to that frame
+// before each operation on the element, so callers need not switch frames
+// themselves. Lookups start at the top-level document again. Frame switches
+// are serialized with the operations depending on them, so that elements of
+// different frames can be used from different goroutines.
+//
+// Switching frames directly through the WebDriver of the session confuses the
+// frame tracking of the session; use SwitchFrame(nil) to return to the top
+// first.
+func WithFrameSearch(depth int) Option {
+	return func(c *sessionConfig) {
+		c.frameDepth = depth
+	}
+}
+
+// FramePath returns the indices of the frames leading from the top-level
+// document to the document of the element, as found by a frame search, see
+// WithFrameSearch. An index counts the frames matched by "//iframe | //frame"
+// in the document of the parent frame. The path is empty for elements of the
+// top-level document.
+func (e *Element) FramePath() []int {
+	return append([]int(nil), e.frame...)
+}
+
+// inFrame runs fn in the frame of e if it was found by a frame search. No
+// other frame switch of the session happens while fn runs, so fn must not
+// switch frames itself, e.g. through the methods of Element.
+func (e *Element) inFrame(fn func() error) error {
+	if !e.framed || e.s == nil {
+		return fn()
+	}
+	return e.s.inFrame(e.frame, fn)
+}
+
+// inFrame runs fn in the frame at path, see Element.inFrame.
+func (s *Session) inFrame(path []int, fn func() error) error {
+	s.frameMu.Lock()
+	defer s.frameMu.Unlock()
+	if err := s.enterFrame(path); err != nil {
+		return err
+	}
+	return fn()
+}
+
+// enterFrame switches to the frame at path unless the session is there
+// already. The caller holds frameMu.
+func (s *Session) enterFrame(path []int) error {
+	if samePath(s.frame, path) {
+		return nil
+	}
+	return s.switchFrame(path)
+}
+
+// switchFrame switches to the frame at path, starting from the top-level
+// document. The caller holds frameMu.
+func (s *Session) switchFrame(path []int) error {
+	// Whatever fails below, the current frame is unknown until the switch to
+	// the top succeeds.
+	s.frame = []int{-1}
+	if err := s.SwitchFrame(nil); err != nil {
+		return err
+	}
+	s.frame = nil
+	for depth, idx := range path {
+		frames, err := s.FindElements(ByXPATH, frameXPath)
+		if err != nil {
+			return err
+		} else if idx >= len(frames) {
+			return fmt.Errorf("no such frame %v", path[:depth+1])
+		}
+		if err := s.SwitchFrame(frames[idx]); err != nil {
+			return err
+		}
+		s.frame = append(s.frame, idx)
+	}
+	return nil
+}
+
+// findInFrames looks up xpath in the top-level document and then in its
+// frames, up to the frame search depth of the session. The session is left in
+// the frame of the element found, or at the top-level document.
+func (s *Session) findInFrames(xpath string) (*Element, error) {
+	return s.searchInFrames(func() (*Element, error) { return s.findHere(xpath) })
+}
+
+// searchInFrames runs find, which returns ErrNotFound if it finds nothing, in
+// the top-level document and then in its frames, as findInFrames.
+func (s *Session) searchInFrames(find func() (*Element, error)) (*Element, error) {
+	s.frameMu.Lock()
+	defer s.frameMu.Unlock()
+	elem, err := s.searchFrames(find, nil, s.frameDepth)
+	if elem == nil {
+		if err := s.enterFrame(nil); err != nil {
+			debugLog("error switching to top-level document: %v", err)
+		}
+	}
+	return elem, err
+}
+
+func (s *Session) searchFrames(find func() (*Element, error), path []int, depth int) (*Element, error) {
+	if err := s.switchFrame(path); err != nil {
+		return nil, err
+	}
+	elem, err := find()
+	if err == nil {
+		elem.frame, elem.framed = path, true
+	}
+	if err != ErrNotFound || depth == 0 {
+		return elem, err
+	}
+
+	frames, err := s.FindElements(ByXPATH, frameXPath)
+	if err != nil {
+		return nil, err
+	}
+	for i := range frames {
+		elem, err := s.searchFrames(find, append(path[:len(path):len(path)], i), depth-1)
+		if err == ErrNotFound || goneFrame(err) {
+			// Frames come and go while the page loads.
+			continue
+		}
+		return elem, err
+	}
+	return nil, ErrNotFound
+}
+
+// topFrame returns a session searching frames to the top-level document.
+func (s *Session) topFrame() error {
+	if s.frameDepth <= 0 {
+		return nil
+	}
+	return s.inFrame(nil, func() error { return nil })
+}
+
+func goneFrame(err error) bool {
+	return err != nil && (StaleElement(err) || strings.Contains(err.Error(), "no such frame"))
+}
+
+func samePath(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
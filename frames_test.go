@@ -0,0 +1,118 @@
+package webdriver
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// fakeDoc is a document with frames for fakeFrameWD.
+type fakeDoc struct {
+	WebElement
+	frames []*fakeDoc
+	target *fakeWE
+}
+
+// fakeFrameWD is a WebDriver switching between nested fake documents.
+type fakeFrameWD struct {
+	WebDriver
+	top, cur *fakeDoc
+	switches int
+	// scripted is the document the last script ran in.
+	scripted *fakeDoc
+}
+
+func (f *fakeFrameWD) ExecuteScript(script string, args []interface{}) (interface{}, error) {
+	f.scripted = f.cur
+	return nil, nil
+}
+
+func (f *fakeFrameWD) SwitchFrame(frame interface{}) error {
+	f.switches++
+	if frame == nil {
+		f.cur = f.top
+		return nil
+	}
+	doc, ok := frame.(*fakeDoc)
+	if !ok {
+		return errors.New("no such frame")
+	}
+	f.cur = doc
+	return nil
+}
+
+func (f *fakeFrameWD) FindElement(by, value string) (WebElement, error) {
+	if f.cur.target == nil {
+		return nil, errors.New("no such element")
+	}
+	return f.cur.target, nil
+}
+
+func (f *fakeFrameWD) FindElements(by, value string) ([]WebElement, error) {
+	var ret []WebElement
+	for _, doc := range f.cur.frames {
+		ret = append(ret, doc)
+	}
+	return ret, nil
+}
+
+func TestFrameSearch(t *testing.T) {
+	target := &fakeWE{text: "found"}
+	inner := &fakeDoc{target: target}
+	top := &fakeDoc{frames: []*fakeDoc{{}, {frames: []*fakeDoc{inner}}}}
+	wd := &fakeFrameWD{top: top, cur: top}
+	s := &Session{WebDriver: wd, frameDepth: 1}
+
+	if _, err := s.find("//a"); err != ErrNotFound {
+		t.Fatalf("find beyond depth = %v, want ErrNotFound", err)
+	}
+	if wd.cur != top {
+		t.Errorf("session not returned to the top-level document")
+	}
+
+	s.frameDepth = 2
+	elem, err := s.find("//a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := elem.FramePath(), []int{1, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FramePath = %v, want %v", got, want)
+	}
+
+	wd.cur = top
+	s.frame = nil
+	if got, err := elem.Text(); err != nil || got != "found" {
+		t.Errorf("Text = %q, %v, want found", got, err)
+	}
+	if wd.cur != inner {
+		t.Errorf("element operation did not switch to its frame")
+	}
+
+	switches := wd.switches
+	elem.Text()
+	if wd.switches != switches {
+		t.Errorf("switched frames again while in the frame of the element")
+	}
+}
+
+func TestFrameScripts(t *testing.T) {
+	inner := &fakeDoc{}
+	top := &fakeDoc{frames: []*fakeDoc{{}, {frames: []*fakeDoc{inner}}}}
+	wd := &fakeFrameWD{top: top, cur: top}
+	s := &Session{WebDriver: wd, frameDepth: 2}
+	elem := &Element{s: s, WebElement: &fakeWE{}, frame: []int{1, 0}, framed: true}
+
+	if err := elem.SetAttribute("class", "x"); err != nil {
+		t.Fatal(err)
+	}
+	if wd.scripted != inner {
+		t.Errorf("script did not run in the frame of the element")
+	}
+
+	if err := s.topFrame(); err != nil {
+		t.Fatal(err)
+	}
+	if wd.cur != top {
+		t.Errorf("topFrame did not return to the top-level document")
+	}
+}
@@ -5,7 +5,7 @@ go 1.12
 require (
 	github.com/blang/semver v3.5.1+incompatible
 	github.com/iamjinlei/memfs v0.0.0-20200326044402-99b37a2ca086
-	github.com/phayes/freeport v0.0.0-20180830031419-95f893ade6f2
 	github.com/pkg/errors v0.9.1
 	github.com/stretchr/testify v1.5.1
+	gopkg.in/yaml.v2 v2.4.0
 )
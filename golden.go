@@ -0,0 +1,143 @@
+package webdriver
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// GoldenDir is the directory where MatchGolden keeps golden files, relative
+// to the working directory of the test.
+var GoldenDir = filepath.Join("testdata", "golden")
+
+// UpdateGolden causes MatchGolden to rewrite the golden files instead of
+// comparing against them. It is initialized from the UPDATE_GOLDEN environment
+// variable; tests may also bind it to their own -update flag.
+var UpdateGolden = os.Getenv("UPDATE_GOLDEN") != ""
+
+// GoldenT is the subset of testing.TB used by MatchGolden.
+type GoldenT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+// Normalizer rewrites serialized HTML before it is compared against a golden
+// file, typically to remove content that changes between page loads.
+type Normalizer func(html string) string
+
+// StripAttributes returns a Normalizer removing the attributes whose names
+// match any of the given regular expressions, e.g. "nonce" or "data-v-.*".
+func StripAttributes(names ...string) Normalizer {
+	re := regexp.MustCompile(`\s(?:` + strings.Join(names, "|") + `)(?:="[^"]*"|='[^']*'|=[^\s>]+)?(?:\s|(/?>))`)
+	return func(html string) string {
+		// Matches consume the following separator, so adjacent attributes need
+		// another pass.
+		for {
+			next := re.ReplaceAllString(html, " $1")
+			next = strings.Replace(next, " >", ">", -1)
+			next = strings.Replace(next, " />", "/>", -1)
+			if next == html {
+				return html
+			}
+			html = next
+		}
+	}
+}
+
+// VolatileAttributes strips attributes that commonly differ between loads of
+// the same page: nonces, framework-generated ids and inline styles.
+var VolatileAttributes = StripAttributes("nonce", "style", "data-reactid", "data-react-checksum", `data-v-[\w-]+`, `jsaction`, `jscontroller`)
+
+// CollapseWhitespace is a Normalizer that replaces runs of whitespace with a
+// single space and trims whitespace between tags.
+func CollapseWhitespace(html string) string {
+	html = regexp.MustCompile(`\s+`).ReplaceAllString(html, " ")
+	return strings.TrimSpace(regexp.MustCompile(`>\s+<`).ReplaceAllString(html, "><"))
+}
+
+// MatchGolden serializes the element's outer HTML, applies the normalizers
+// (VolatileAttributes and CollapseWhitespace if none are given) and compares
+// the result against the golden file GoldenDir/name.html. If UpdateGolden is
+// set, the golden file is written instead.
+func (e *Element) MatchGolden(t GoldenT, name string, normalizers ...Normalizer) {
+	t.Helper()
+
+	html, err := e.OuterHTML()
+	if err != nil {
+		t.Fatalf("reading outer HTML for golden %q: %v", name, err)
+		return
+	}
+	if len(normalizers) == 0 {
+		normalizers = []Normalizer{VolatileAttributes, CollapseWhitespace}
+	}
+	for _, n := range normalizers {
+		html = n(html)
+	}
+
+	path := filepath.Join(GoldenDir, name+".html")
+	if UpdateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("creating golden directory: %v", err)
+			return
+		}
+		if err := ioutil.WriteFile(path, []byte(html), 0644); err != nil {
+			t.Fatalf("writing golden %q: %v", path, err)
+		}
+		return
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden %q (set UPDATE_GOLDEN=1 to create it): %v", path, err)
+		return
+	}
+	if diff := goldenDiff(string(want), html); diff != "" {
+		t.Errorf("element does not match golden %q (set UPDATE_GOLDEN=1 to update it):\n%s", path, diff)
+	}
+}
+
+// OuterHTML returns the serialized HTML of the element and its descendants.
+func (e *Element) OuterHTML() (string, error) {
+	var ret interface{}
+	err := e.inFrame(func() (err error) {
+		ret, err = e.s.ExecuteScript("return arguments[0].outerHTML;", []interface{}{e.WebElement})
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	html, ok := ret.(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected outer HTML %v", ret)
+	}
+	return html, nil
+}
+
+// goldenDiff describes the first difference between want and got, split at tag
+// boundaries so that long single-line documents remain readable.
+func goldenDiff(want, got string) string {
+	if want == got {
+		return ""
+	}
+	split := func(s string) []string {
+		return strings.SplitAfter(s, ">")
+	}
+	w, g := split(want), split(got)
+	for i := 0; i < len(w) || i < len(g); i++ {
+		var wl, gl string
+		if i < len(w) {
+			wl = w[i]
+		}
+		if i < len(g) {
+			gl = g[i]
+		}
+		if wl != gl {
+			return fmt.Sprintf("at tag %d:\n- %s\n+ %s", i, wl, gl)
+		}
+	}
+	return ""
+}
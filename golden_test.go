@@ -0,0 +1,28 @@
+package webdriver
+
+import "testing"
+
+func TestNormalizers(t *testing.T) {
+	for _, tc := range []struct {
+		n        Normalizer
+		in, want string
+	}{
+		{VolatileAttributes, `<div nonce="abc" class="x" style='color: red' data-v-1a2b>text</div>`, `<div class="x">text</div>`},
+		{VolatileAttributes, `<input style="a" nonce="b" type="text">`, `<input type="text">`},
+		{StripAttributes("id"), `<p id="1" data-id="2">x</p>`, `<p data-id="2">x</p>`},
+		{CollapseWhitespace, "<ul>\n  <li>a  b</li>\n</ul>\n", "<ul><li>a b</li></ul>"},
+	} {
+		if got := tc.n(tc.in); got != tc.want {
+			t.Errorf("normalize(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestGoldenDiff(t *testing.T) {
+	if diff := goldenDiff("<a>x</a>", "<a>x</a>"); diff != "" {
+		t.Errorf("goldenDiff() of equal strings = %q, want empty", diff)
+	}
+	if diff := goldenDiff("<a>x</a>", "<a>y</a>"); diff == "" {
+		t.Errorf("goldenDiff() of different strings returned empty diff")
+	}
+}
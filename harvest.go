@@ -0,0 +1,158 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// HarvestOptions configures HarvestInfinite.
+type HarvestOptions struct {
+	// KeyAttr is the attribute identifying an item, e.g. "data-id", to skip
+	// items seen before. Items without it, or all items if it is empty, are
+	// identified by their text.
+	KeyAttr string
+	// MaxItems stops the harvest after as many items, if positive.
+	MaxItems int
+	// Wait bounds the wait for a new batch of items after scrolling. It
+	// defaults to the timeout of the session.
+	Wait time.Duration
+	// OnError, if set, is called with the error stopping a harvest early.
+	// Errors are logged otherwise.
+	OnError func(err error)
+}
+
+// harvestScript returns the items matched by the xpath in arguments[0] along
+// with their keys, see HarvestOptions.KeyAttr in arguments[1].
+const harvestScript = `var res = document.evaluate(arguments[0], document, null, XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null);
+var ret = [];
+for (var i = 0; i < res.snapshotLength; i++) {
+	var n = res.snapshotItem(i);
+	var key = arguments[1] && n.getAttribute && n.getAttribute(arguments[1]);
+	ret.push([n, key || n.textContent.trim()]);
+}
+return ret;`
+
+// harvestMarkScript returns a mark of the items matched by the xpath in
+// arguments[0] that changes when a batch of items is added: their count and
+// the text of the last one.
+const harvestMarkScript = `var res = document.evaluate(arguments[0], document, null, XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null);
+var last = res.snapshotLength > 0 ? res.snapshotItem(res.snapshotLength - 1) : null;
+return res.snapshotLength + ":" + (last ? last.textContent.trim() : "");`
+
+// HarvestInfinite returns an iterator over the items of an infinitely
+// scrolling list, e.g. a feed, matched by itemXPath. It yields the items
+// loaded, scrolls the last of them to the top of the view to load the next
+// batch, waits for it and repeats, yielding each item once. The first batch is
+// waited for as well. The harvest stops when yield returns false, after
+// opts.MaxItems items, or when no new items load within opts.Wait.
+//
+//	for item := range s.HarvestInfinite("//article", webdriver.HarvestOptions{KeyAttr: "data-id"}) {
+//		...
+//	}
+func (s *Session) HarvestInfinite(itemXPath string, opts HarvestOptions) func(yield func(*Element) bool) {
+	if opts.Wait <= 0 {
+		opts.Wait = s.timeout
+	}
+	fail := func(err error) {
+		if opts.OnError != nil {
+			opts.OnError(err)
+		} else {
+			debugLog("error harvesting %v: %v", itemXPath, err)
+		}
+	}
+
+	return func(yield func(*Element) bool) {
+		err := s.waitOn(func() (bool, error) {
+			mark, err := s.ExecuteScript(harvestMarkScript, []interface{}{itemXPath})
+			if err != nil {
+				return true, err
+			}
+			m, _ := mark.(string)
+			return !strings.HasPrefix(m, "0:"), nil
+		}, opts.Wait)
+		if _, ok := err.(*TimeoutError); ok {
+			// No items at all.
+			return
+		} else if err != nil {
+			fail(err)
+			return
+		}
+
+		seen := map[string]bool{}
+		for {
+			items, keys, err := s.harvestItems(itemXPath, opts.KeyAttr)
+			if err != nil {
+				fail(err)
+				return
+			}
+			for i, item := range items {
+				if seen[keys[i]] {
+					continue
+				}
+				seen[keys[i]] = true
+				if !yield(item) || opts.MaxItems > 0 && len(seen) >= opts.MaxItems {
+					return
+				}
+			}
+			if len(items) == 0 {
+				return
+			}
+
+			mark, err := s.ExecuteScript(harvestMarkScript, []interface{}{itemXPath})
+			if err != nil {
+				fail(err)
+				return
+			}
+			if _, err := s.ExecuteScript("arguments[0].scrollIntoView({block: \"start\"});", []interface{}{items[len(items)-1].WebElement}); err != nil {
+				fail(err)
+				return
+			}
+			err = s.waitOn(func() (bool, error) {
+				now, err := s.ExecuteScript(harvestMarkScript, []interface{}{itemXPath})
+				if err != nil {
+					return true, err
+				}
+				return now != mark, nil
+			}, opts.Wait)
+			if _, ok := err.(*TimeoutError); ok {
+				// No more content.
+				return
+			} else if err != nil {
+				fail(err)
+				return
+			}
+		}
+	}
+}
+
+// harvestItems returns the items matched by xpath and their keys.
+func (s *Session) harvestItems(xpath, keyAttr string) ([]*Element, []string, error) {
+	data, err := s.ExecuteScriptRaw(harvestScript, []interface{}{xpath, keyAttr})
+	if err != nil {
+		return nil, nil, err
+	}
+	reply := struct {
+		Value [][2]json.RawMessage
+	}{}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return nil, nil, err
+	}
+
+	items := make([]*Element, 0, len(reply.Value))
+	keys := make([]string, 0, len(reply.Value))
+	for i, pair := range reply.Value {
+		we, err := s.DecodeElement(rawValue(pair[0]))
+		if err != nil {
+			return nil, nil, fmt.Errorf("error decoding item %d: %v", i, err)
+		}
+		var key string
+		if err := json.Unmarshal(pair[1], &key); err != nil {
+			return nil, nil, err
+		}
+		items = append(items, &Element{s: s, WebElement: we, xpath: nthXPath(xpath, i)})
+		keys = append(keys, key)
+	}
+	return items, keys, nil
+}
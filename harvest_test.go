@@ -0,0 +1,44 @@
+package webdriver
+
+import (
+	"testing"
+	"time"
+)
+
+// lateFeedWD is a feed whose first item shows up after a few polls.
+type lateFeedWD struct {
+	WebDriver
+	polls int
+}
+
+func (wd *lateFeedWD) ExecuteScript(script string, args []interface{}) (interface{}, error) {
+	if wd.polls++; wd.polls < 3 {
+		return "0:", nil
+	}
+	return "1:a", nil
+}
+
+func (wd *lateFeedWD) ExecuteScriptRaw(script string, args []interface{}) ([]byte, error) {
+	if wd.polls < 3 {
+		return []byte(`{"value": []}`), nil
+	}
+	return []byte(`{"value": [[{"` + webElementIdentifier + `": "e1"}, "a"]]}`), nil
+}
+
+func (wd *lateFeedWD) DecodeElement(data []byte) (WebElement, error) {
+	return (&remoteWD{}).DecodeElement(data)
+}
+
+func TestHarvestWaitsForFirstBatch(t *testing.T) {
+	wd := &lateFeedWD{}
+	s := &Session{WebDriver: wd, timeout: 10 * time.Second, poll: &PollStrategy{}}
+
+	var got []*Element
+	s.HarvestInfinite("//article", HarvestOptions{MaxItems: 1})(func(e *Element) bool {
+		got = append(got, e)
+		return true
+	})
+	if len(got) != 1 {
+		t.Errorf("harvested %d items, want 1", len(got))
+	}
+}
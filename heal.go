@@ -0,0 +1,169 @@
+package webdriver
+
+// WithSelfHealing makes the elements of the session heal stale references:
+// when a WebElement method of an Element fails with a stale element
+// reference, the element is located again through the chain of xpaths it was
+// found with, see Relocate, and the method is retried, up to limit times per
+// call. Elements not located by xpath, e.g. from FindElement, do not heal.
+func WithSelfHealing(limit int) Option {
+	return func(c *sessionConfig) {
+		c.healLimit = limit
+	}
+}
+
+// heal runs fn, relocating the element and retrying fn while it fails with a
+// stale element reference, up to the heal limit of the session. Elements found
+// in a frame switch to their frame first.
+func (e *Element) heal(fn func() error) error {
+	err := e.inFrame(fn)
+	if e.s == nil {
+		return err
+	}
+	for i := 0; i < e.s.healLimit && StaleElement(err); i++ {
+		if rerr := e.Relocate(); rerr != nil {
+			debugLog("error healing stale element %v: %v", e.xpath, rerr)
+			return err
+		}
+		err = e.inFrame(fn)
+	}
+	return err
+}
+
+// The methods below wrap those of WebElement with heal.
+
+func (e *Element) Click() error {
+	return e.heal(func() error { return e.WebElement.Click() })
+}
+
+func (e *Element) SendKeys(keys string) error {
+	return e.heal(func() error { return e.WebElement.SendKeys(keys) })
+}
+
+func (e *Element) Submit() error {
+	return e.heal(func() error { return e.WebElement.Submit() })
+}
+
+func (e *Element) Clear() error {
+	return e.heal(func() error { return e.WebElement.Clear() })
+}
+
+func (e *Element) MoveTo(xOffset, yOffset int) error {
+	return e.heal(func() error { return e.WebElement.MoveTo(xOffset, yOffset) })
+}
+
+func (e *Element) FindElement(by, value string) (WebElement, error) {
+	var ret WebElement
+	err := e.heal(func() (err error) {
+		ret, err = e.WebElement.FindElement(by, value)
+		return err
+	})
+	return ret, err
+}
+
+func (e *Element) FindElements(by, value string) ([]WebElement, error) {
+	var ret []WebElement
+	err := e.heal(func() (err error) {
+		ret, err = e.WebElement.FindElements(by, value)
+		return err
+	})
+	return ret, err
+}
+
+func (e *Element) TagName() (string, error) {
+	var ret string
+	err := e.heal(func() (err error) {
+		ret, err = e.WebElement.TagName()
+		return err
+	})
+	return ret, err
+}
+
+func (e *Element) Text() (string, error) {
+	var ret string
+	err := e.heal(func() (err error) {
+		ret, err = e.WebElement.Text()
+		return err
+	})
+	return ret, err
+}
+
+func (e *Element) IsSelected() (bool, error) {
+	var ret bool
+	err := e.heal(func() (err error) {
+		ret, err = e.WebElement.IsSelected()
+		return err
+	})
+	return ret, err
+}
+
+func (e *Element) IsEnabled() (bool, error) {
+	var ret bool
+	err := e.heal(func() (err error) {
+		ret, err = e.WebElement.IsEnabled()
+		return err
+	})
+	return ret, err
+}
+
+func (e *Element) IsDisplayed() (bool, error) {
+	var ret bool
+	err := e.heal(func() (err error) {
+		ret, err = e.WebElement.IsDisplayed()
+		return err
+	})
+	return ret, err
+}
+
+func (e *Element) GetAttribute(name string) (string, error) {
+	var ret string
+	err := e.heal(func() (err error) {
+		ret, err = e.WebElement.GetAttribute(name)
+		return err
+	})
+	return ret, err
+}
+
+func (e *Element) Location() (*Point, error) {
+	var ret *Point
+	err := e.heal(func() (err error) {
+		ret, err = e.WebElement.Location()
+		return err
+	})
+	return ret, err
+}
+
+func (e *Element) LocationInView() (*Point, error) {
+	var ret *Point
+	err := e.heal(func() (err error) {
+		ret, err = e.WebElement.LocationInView()
+		return err
+	})
+	return ret, err
+}
+
+func (e *Element) Size() (*Size, error) {
+	var ret *Size
+	err := e.heal(func() (err error) {
+		ret, err = e.WebElement.Size()
+		return err
+	})
+	return ret, err
+}
+
+func (e *Element) CSSProperty(name string) (string, error) {
+	var ret string
+	err := e.heal(func() (err error) {
+		ret, err = e.WebElement.CSSProperty(name)
+		return err
+	})
+	return ret, err
+}
+
+func (e *Element) Screenshot(scroll bool) ([]byte, error) {
+	var ret []byte
+	err := e.heal(func() (err error) {
+		ret, err = e.WebElement.Screenshot(scroll)
+		return err
+	})
+	return ret, err
+}
@@ -0,0 +1,45 @@
+package webdriver
+
+import (
+	"errors"
+	"testing"
+)
+
+type fakeWE struct {
+	WebElement
+	text  string
+	stale bool
+	child *fakeWE
+}
+
+var errStale = errors.New("stale element reference: element is not attached to the page document")
+
+func (f *fakeWE) Text() (string, error) {
+	if f.stale {
+		return "", errStale
+	}
+	return f.text, nil
+}
+
+func (f *fakeWE) FindElement(by, value string) (WebElement, error) {
+	return f.child, nil
+}
+
+func TestSelfHealing(t *testing.T) {
+	fresh := &fakeWE{text: "fresh"}
+	parent := &Element{WebElement: &fakeWE{child: fresh}}
+
+	e := &Element{s: &Session{}, WebElement: &fakeWE{stale: true}, parent: parent, xpath: "./a"}
+	if _, err := e.Text(); err != errStale {
+		t.Errorf("Text without healing = %v, want %v", err, errStale)
+	}
+
+	e.s.healLimit = 1
+	got, err := e.Text()
+	if err != nil || got != "fresh" {
+		t.Errorf("Text with healing = %q, %v, want fresh", got, err)
+	}
+	if e.WebElement != fresh {
+		t.Error("element reference was not replaced")
+	}
+}
@@ -0,0 +1,109 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Command is a wire command about to be sent to the driver. Sessions of
+// CDPDriver have no driver and send DevTools calls instead: their Method is
+// "CDP" and their URL the DevTools method, e.g. Runtime.evaluate.
+type Command struct {
+	// Method is the HTTP method of the command.
+	Method string
+	// URL is the full URL of the command, e.g.
+	// http://localhost:9515/session/<id>/element.
+	URL string
+	// Params is the JSON encoded body of the command, nil for commands
+	// without a body. Hooks may replace it, e.g. to rewrite selectors.
+	Params []byte
+}
+
+// cdpCommand is the Method of the commands of CDPDriver sessions.
+const cdpCommand = "CDP"
+
+// Hook intercepts the wire commands of a session. Either function may be nil.
+type Hook struct {
+	// Before is called before the command is sent and may modify it. A non
+	// nil error is returned to the caller instead of executing the command,
+	// which allows injecting failures.
+	Before func(cmd *Command) error
+	// After is called with the outcome of the command and the time it took.
+	// If a Before hook aborts the command, only the hooks up to and including
+	// the aborting one see it.
+	After func(cmd *Command, result []byte, err error, elapsed time.Duration)
+}
+
+// Use adds a hook to the session. Hooks run in the order they were added for
+// Before and in reverse order for After, so that a hook wraps all the hooks
+// added after it.
+func (s *Session) Use(hook Hook) {
+	if wd, ok := s.WebDriver.(interface{ use(Hook) }); ok {
+		wd.use(hook)
+	}
+}
+
+// hookSet holds the hooks of a backend.
+type hookSet struct {
+	hmu   sync.Mutex
+	hooks []Hook
+}
+
+func (h *hookSet) use(hook Hook) {
+	h.hmu.Lock()
+	defer h.hmu.Unlock()
+
+	// Copy on write so that running commands keep a consistent list.
+	hooks := make([]Hook, len(h.hooks), len(h.hooks)+1)
+	copy(hooks, h.hooks)
+	h.hooks = append(hooks, hook)
+}
+
+func (h *hookSet) list() []Hook {
+	h.hmu.Lock()
+	defer h.hmu.Unlock()
+	return h.hooks
+}
+
+// executeHooked runs the command through the hooks of the session.
+func (wd *remoteWD) executeHooked(method, url string, data []byte) (json.RawMessage, error) {
+	hooks := wd.list()
+	if len(hooks) == 0 {
+		return executeCommand(method, url, data)
+	}
+	return runHooks(hooks, &Command{Method: method, URL: url, Params: data}, func(cmd *Command) (json.RawMessage, error) {
+		return executeCommand(cmd.Method, cmd.URL, cmd.Params)
+	})
+}
+
+// runHooks runs cmd through hooks, executing it with exec unless a Before hook
+// aborts it.
+func runHooks(hooks []Hook, cmd *Command, exec func(cmd *Command) (json.RawMessage, error)) (json.RawMessage, error) {
+	start := time.Now()
+	var (
+		result json.RawMessage
+		err    error
+		ran    = len(hooks)
+	)
+	for i, h := range hooks {
+		if h.Before == nil {
+			continue
+		}
+		if err = h.Before(cmd); err != nil {
+			ran = i + 1
+			break
+		}
+	}
+	if err == nil {
+		result, err = exec(cmd)
+	}
+	elapsed := time.Since(start)
+	for i := ran - 1; i >= 0; i-- {
+		if hooks[i].After != nil {
+			hooks[i].After(cmd, result, err, elapsed)
+		}
+	}
+
+	return result, err
+}
@@ -0,0 +1,102 @@
+package webdriver
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHooks(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		got = string(b)
+		w.Header().Set("Content-Type", jsonContentType)
+		w.Write([]byte(`{"value": null}`))
+	}))
+	defer srv.Close()
+
+	wd := &remoteWD{urlPrefix: srv.URL, id: "1"}
+	var order []string
+	wd.use(Hook{
+		Before: func(cmd *Command) error {
+			order = append(order, "before1")
+			cmd.Params = []byte(`{"rewritten":true}`)
+			return nil
+		},
+		After: func(cmd *Command, result []byte, err error, elapsed time.Duration) {
+			order = append(order, "after1")
+		},
+	})
+	wd.use(Hook{
+		After: func(cmd *Command, result []byte, err error, elapsed time.Duration) {
+			order = append(order, "after2")
+		},
+	})
+
+	if err := wd.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if got != `{"rewritten":true}` {
+		t.Errorf("params = %s, want rewritten", got)
+	}
+	if want := "[before1 after2 after1]"; fmt.Sprint(order) != want {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+
+	chaos := errors.New("chaos")
+	order = nil
+	wd.use(Hook{Before: func(*Command) error { return chaos }})
+	if err := wd.Refresh(); err != chaos {
+		t.Errorf("err = %v, want %v", err, chaos)
+	}
+	if want := "[before1 after2 after1]"; fmt.Sprint(order) != want {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+}
+
+func TestCDPHooks(t *testing.T) {
+	srv := fakeDevTools(t)
+	defer srv.Close()
+
+	ws, err := dialWebSocket("ws"+strings.TrimPrefix(srv.URL, "http")+"/devtools/page/1", 5*time.Second)
+	if err != nil {
+		t.Fatalf("dialWebSocket() returned error: %v", err)
+	}
+	wd := &cdpWD{conn: newCDPConn(ws)}
+	defer wd.conn.Close()
+
+	var seen []string
+	wd.use(Hook{
+		Before: func(cmd *Command) error {
+			if cmd.URL == "Test.abort" {
+				return errors.New("aborted")
+			}
+			cmd.Params = []byte(`{"rewritten":true}`)
+			return nil
+		},
+		After: func(cmd *Command, result []byte, err error, elapsed time.Duration) {
+			seen = append(seen, fmt.Sprintf("%v %v %s %v", cmd.Method, cmd.URL, result, err))
+		},
+	})
+
+	got := struct{ Rewritten bool }{}
+	if err := wd.call("Test.echo", map[string]bool{"rewritten": false}, &got); err != nil {
+		t.Fatalf("call() returned error: %v", err)
+	}
+	if !got.Rewritten {
+		t.Error("call() did not send the params rewritten by the hook")
+	}
+	if err := wd.call("Test.abort", nil, nil); err == nil || err.Error() != "aborted" {
+		t.Errorf("call(Test.abort) returned error %v, want aborted", err)
+	}
+	want := []string{`CDP Test.echo {"rewritten":true} <nil>`, `CDP Test.abort  aborted`}
+	if fmt.Sprint(seen) != fmt.Sprint(want) {
+		t.Errorf("hooks saw %q, want %q", seen, want)
+	}
+}
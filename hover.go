@@ -0,0 +1,102 @@
+package webdriver
+
+import "time"
+
+// hoverDelay is the time HoverPath dwells on each element, as menus opening
+// on hover often wait for the pointer to rest to tell intent from passing by.
+const hoverDelay = 300 * time.Millisecond
+
+// hoverer is implemented by the elements of drivers that can move the pointer
+// over the center of an element.
+type hoverer interface {
+	hover() error
+}
+
+// hover moves the pointer over the center of the element with a W3C pointer
+// action, or the legacy moveto command, which centers without offsets.
+func (elem *remoteWE) hover() error {
+	if !elem.parent.w3cCompatible {
+		return elem.parent.voidCommand("/session/%s/moveto", map[string]interface{}{
+			"element": elem.id,
+		})
+	}
+	return elem.parent.voidCommand("/session/%s/actions", map[string]interface{}{
+		"actions": []interface{}{
+			map[string]interface{}{
+				"type":       "pointer",
+				"id":         "default mouse",
+				"parameters": map[string]string{"pointerType": "mouse"},
+				"actions": []interface{}{
+					map[string]interface{}{
+						"type":     "pointerMove",
+						"duration": 100,
+						"origin":   map[string]string{webElementIdentifier: elem.id},
+						"x":        0,
+						"y":        0,
+					},
+				},
+			}},
+	})
+}
+
+func (elem *cdpWE) hover() error {
+	var p []float64
+	if err := elem.eval(`var r = arguments[0].getBoundingClientRect();
+return [r.left + r.width / 2, r.top + r.height / 2];`, &p); err != nil {
+		return err
+	}
+	return elem.parent.moveMouse(p[0], p[1])
+}
+
+// Hover moves the pointer over the center of the element, without scrolling,
+// which fires the mouse events opening hover menus and tooltips.
+func (e *Element) Hover() error {
+	return e.heal(func() error {
+		if h, ok := e.WebElement.(hoverer); ok {
+			return h.hover()
+		}
+		return e.WebElement.MoveTo(0, 0)
+	})
+}
+
+// HoverPath navigates a hover menu: it hovers the elements of xpaths but the
+// last in turn, waiting for each to be displayed and dwelling on it so that
+// the menu it opens stays open, and clicks the last one. Only the first
+// element is scrolled into view, since scrolling moves menus away from the
+// pointer.
+func (s *Session) HoverPath(xpaths ...string) error {
+	for i, xpath := range xpaths {
+		elem, err := s.GetDOM(xpath)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			err = elem.ScrollIntoView()
+		} else {
+			err = elem.waitDisplayed()
+		}
+		if err != nil {
+			return s.fail("HoverPath", xpath, err)
+		}
+
+		if i == len(xpaths)-1 {
+			return s.fail("HoverPath", xpath, elem.Click())
+		}
+		if err := elem.Hover(); err != nil {
+			return s.fail("HoverPath", xpath, err)
+		}
+		time.Sleep(hoverDelay)
+	}
+	return nil
+}
+
+// waitDisplayed waits until the element is displayed.
+func (e *Element) waitDisplayed() error {
+	return e.s.waitOn(func() (bool, error) {
+		displayed, err := e.IsDisplayed()
+		if IsRetryable(err) {
+			return false, retryWith(err)
+		}
+		return displayed, err
+	}, e.s.timeout)
+}
@@ -0,0 +1,45 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRemoteHover(t *testing.T) {
+	var path string
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		data, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(data, &body)
+		w.Header().Set("Content-Type", jsonContentType)
+		w.Write([]byte(`{"value": null}`))
+	}))
+	defer srv.Close()
+
+	wd := &remoteWD{urlPrefix: srv.URL, id: "1", w3cCompatible: true}
+	e := &Element{s: &Session{WebDriver: wd}, WebElement: &remoteWE{parent: wd, id: "e1"}}
+	if err := e.Hover(); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/session/1/actions" {
+		t.Fatalf("Hover sent %v, want actions", path)
+	}
+	source := body["actions"].([]interface{})[0].(map[string]interface{})
+	move := source["actions"].([]interface{})[0].(map[string]interface{})
+	origin := move["origin"].(map[string]interface{})
+	if source["type"] != "pointer" || move["type"] != "pointerMove" || origin[webElementIdentifier] != "e1" {
+		t.Errorf("Hover actions = %v", body)
+	}
+
+	wd.w3cCompatible = false
+	if err := e.Hover(); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/session/1/moveto" || body["element"] != "e1" {
+		t.Errorf("legacy Hover sent %v %v", path, body)
+	}
+}
@@ -0,0 +1,164 @@
+package webdriver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// TransportOptions configures the HTTP client used for the wire commands.
+type TransportOptions struct {
+	// Timeout limits the duration of a single command, including reading
+	// the reply. Zero means no limit. Note that navigation and scripts may
+	// legitimately take as long as the page load and script timeouts.
+	Timeout time.Duration
+	// DialTimeout limits the time to establish a connection. It defaults to
+	// 30 seconds.
+	DialTimeout time.Duration
+	// MaxIdleConnsPerHost is the size of the keep-alive pool per driver
+	// host. It defaults to http.DefaultMaxIdleConnsPerHost.
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout closes keep-alive connections idle for longer. It
+	// defaults to 90 seconds.
+	IdleConnTimeout time.Duration
+	// DisableKeepAlives opens a new connection for every command.
+	DisableKeepAlives bool
+	// Proxy is the URL of the HTTP proxy to reach the driver through. When
+	// empty, the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+	// are honored.
+	Proxy string
+	// TLS configures https:// driver endpoints. When nil the system roots
+	// are trusted and no client certificate is sent.
+	TLS *TLSOptions
+}
+
+// TLSOptions configures the TLS connections to a driver endpoint, e.g. a
+// Selenium Grid behind a TLS terminating gateway.
+type TLSOptions struct {
+	// CAFile is a PEM bundle of the certificate authorities to trust in
+	// addition to the system roots.
+	CAFile string
+	// CertFile and KeyFile are the PEM encoded client certificate and key
+	// presented for mutual TLS.
+	CertFile, KeyFile string
+	// ServerName overrides the name verified in the server certificate.
+	ServerName string
+	// InsecureSkipVerify disables the verification of the server
+	// certificate. Only use it for testing.
+	InsecureSkipVerify bool
+}
+
+func (o *TLSOptions) config() (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         o.ServerName,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+
+	if o.CAFile != "" {
+		pem, err := ioutil.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %v", o.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if o.CertFile != "" || o.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// NewHTTPClient returns a client configured by opts, suitable for assigning
+// to HTTPClient before calling NewRemote.
+func NewHTTPClient(opts TransportOptions) (*http.Client, error) {
+	proxy := http.ProxyFromEnvironment
+	if opts.Proxy != "" {
+		u, err := url.Parse(opts.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		proxy = http.ProxyURL(u)
+	}
+
+	dialTimeout := opts.DialTimeout
+	if dialTimeout <= 0 {
+		dialTimeout = 30 * time.Second
+	}
+	var tlsCfg *tls.Config
+	if opts.TLS != nil {
+		var err error
+		if tlsCfg, err = opts.TLS.config(); err != nil {
+			return nil, err
+		}
+	}
+
+	idleTimeout := opts.IdleConnTimeout
+	if idleTimeout <= 0 {
+		idleTimeout = 90 * time.Second
+	}
+
+	return &http.Client{
+		Timeout: opts.Timeout,
+		Transport: &http.Transport{
+			Proxy: proxy,
+			DialContext: (&net.Dialer{
+				Timeout:   dialTimeout,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			MaxIdleConns:        100,
+			MaxIdleConnsPerHost: opts.MaxIdleConnsPerHost,
+			IdleConnTimeout:     idleTimeout,
+			DisableKeepAlives:   opts.DisableKeepAlives,
+			TLSClientConfig:     tlsCfg,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+	}, nil
+}
+
+// WithHTTPClient makes Init replace HTTPClient, the client used for the wire
+// commands of all the sessions. It cannot be combined with WithTransport or
+// WithTLS, which configure a client of their own.
+func WithHTTPClient(c *http.Client) InitOption {
+	return func(cfg *initConfig) {
+		cfg.httpClient = c
+	}
+}
+
+// WithTransport makes Init replace HTTPClient with a client configured by
+// opts. It cannot be combined with WithHTTPClient.
+func WithTransport(opts TransportOptions) InitOption {
+	return func(cfg *initConfig) {
+		if opts.TLS == nil && cfg.transport != nil {
+			opts.TLS = cfg.transport.TLS
+		}
+		cfg.transport = &opts
+	}
+}
+
+// WithTLS makes Init talk to the driver over TLS configured by opts. It
+// combines with WithTransport.
+func WithTLS(opts TLSOptions) InitOption {
+	return func(cfg *initConfig) {
+		if cfg.transport == nil {
+			cfg.transport = &TransportOptions{}
+		}
+		cfg.transport.TLS = &opts
+	}
+}
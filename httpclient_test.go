@@ -0,0 +1,59 @@
+package webdriver
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewHTTPClientCA(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "webdriver-test-")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir() returned error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := filepath.Join(dir, "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := ioutil.WriteFile(ca, cert, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewHTTPClient(TransportOptions{TLS: &TLSOptions{CAFile: ca}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get with the CA bundle: %v", err)
+	}
+	resp.Body.Close()
+
+	c, err = NewHTTPClient(TransportOptions{TLS: &TLSOptions{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(srv.URL); err == nil {
+		t.Error("Get without the CA bundle succeeded")
+	}
+
+	if _, err := NewHTTPClient(TransportOptions{TLS: &TLSOptions{CertFile: ca, KeyFile: ca}}); err == nil {
+		t.Error("NewHTTPClient with an invalid key pair succeeded")
+	}
+}
+
+func TestInitRejectsHTTPClientWithTransport(t *testing.T) {
+	if err := Init(9515, false, WithHTTPClient(&http.Client{}), WithTransport(TransportOptions{})); err == nil {
+		t.Error("Init(WithHTTPClient, WithTransport) returned nil error")
+	}
+	if err := Init(9515, false, WithTLS(TLSOptions{}), WithHTTPClient(&http.Client{})); err == nil {
+		t.Error("Init(WithTLS, WithHTTPClient) returned nil error")
+	}
+}
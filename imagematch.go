@@ -0,0 +1,242 @@
+package webdriver
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"sort"
+)
+
+// grayImage is an image as a grid of luminance values.
+type grayImage struct {
+	w, h int
+	pix  []float64
+}
+
+func toGray(img image.Image) *grayImage {
+	b := img.Bounds()
+	g := &grayImage{w: b.Dx(), h: b.Dy(), pix: make([]float64, b.Dx()*b.Dy())}
+	for y := 0; y < g.h; y++ {
+		for x := 0; x < g.w; x++ {
+			g.pix[y*g.w+x] = float64(color.GrayModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.Gray).Y)
+		}
+	}
+	return g
+}
+
+// shrink averages the k×k blocks of the image.
+func (g *grayImage) shrink(k int) *grayImage {
+	if k <= 1 {
+		return g
+	}
+	s := &grayImage{w: g.w / k, h: g.h / k}
+	s.pix = make([]float64, s.w*s.h)
+	for y := 0; y < s.h; y++ {
+		for x := 0; x < s.w; x++ {
+			var sum float64
+			for j := 0; j < k; j++ {
+				for i := 0; i < k; i++ {
+					sum += g.pix[(y*k+j)*g.w+x*k+i]
+				}
+			}
+			s.pix[y*s.w+x] = sum / float64(k*k)
+		}
+	}
+	return s
+}
+
+// matcher computes the normalized cross-correlation of a template at the
+// positions of an image.
+type matcher struct {
+	img, tpl *grayImage
+	// zt is the zero-mean template and tnorm its sum of squares.
+	zt    []float64
+	tnorm float64
+	// sum and sq are the integral images of the pixels and their squares.
+	sum, sq []float64
+}
+
+func newMatcher(img, tpl *grayImage) *matcher {
+	m := &matcher{img: img, tpl: tpl, zt: make([]float64, len(tpl.pix))}
+	var mean float64
+	for _, v := range tpl.pix {
+		mean += v
+	}
+	mean /= float64(len(tpl.pix))
+	for i, v := range tpl.pix {
+		m.zt[i] = v - mean
+		m.tnorm += m.zt[i] * m.zt[i]
+	}
+
+	w := img.w + 1
+	m.sum = make([]float64, w*(img.h+1))
+	m.sq = make([]float64, w*(img.h+1))
+	for y := 0; y < img.h; y++ {
+		for x := 0; x < img.w; x++ {
+			v := img.pix[y*img.w+x]
+			i := (y+1)*w + x + 1
+			m.sum[i] = v + m.sum[i-1] + m.sum[i-w] - m.sum[i-w-1]
+			m.sq[i] = v*v + m.sq[i-1] + m.sq[i-w] - m.sq[i-w-1]
+		}
+	}
+	return m
+}
+
+// score returns the correlation, between -1 and 1, of the template with the
+// image at x, y.
+func (m *matcher) score(x, y int) float64 {
+	tw, th := m.tpl.w, m.tpl.h
+	w := m.img.w + 1
+	rect := func(t []float64) float64 {
+		return t[(y+th)*w+x+tw] - t[y*w+x+tw] - t[(y+th)*w+x] + t[y*w+x]
+	}
+	n := float64(tw * th)
+	s := rect(m.sum)
+	variance := rect(m.sq) - s*s/n
+	if variance <= 1e-9 || m.tnorm <= 1e-9 {
+		return 0
+	}
+
+	var cross float64
+	for j := 0; j < th; j++ {
+		row := m.img.pix[(y+j)*m.img.w+x : (y+j)*m.img.w+x+tw]
+		zt := m.zt[j*tw : (j+1)*tw]
+		for i, v := range row {
+			cross += v * zt[i]
+		}
+	}
+	return cross / math.Sqrt(variance*m.tnorm)
+}
+
+type imageMatch struct {
+	x, y  int
+	score float64
+}
+
+// maxImageMatches bounds the candidates refined by matchTemplate.
+const maxImageMatches = 100
+
+// matchTemplate returns the non-overlapping positions of tpl in img scoring
+// at least threshold, best first. The search runs on downscaled images, then
+// is refined at full resolution around the candidates.
+func matchTemplate(img, tpl *grayImage, threshold float64) []imageMatch {
+	if tpl.w > img.w || tpl.h > img.h || tpl.w == 0 || tpl.h == 0 {
+		return nil
+	}
+	k := tpl.w
+	if tpl.h < k {
+		k = tpl.h
+	}
+	if k /= 12; k < 1 {
+		k = 1
+	} else if k > 8 {
+		k = 8
+	}
+
+	// Downscaling blurs the images, so the coarse threshold is lower.
+	coarse := newMatcher(img.shrink(k), tpl.shrink(k))
+	cthreshold := threshold
+	if k > 1 {
+		cthreshold -= 0.2
+	}
+	var cands []imageMatch
+	for y := 0; y+coarse.tpl.h <= coarse.img.h; y++ {
+		for x := 0; x+coarse.tpl.w <= coarse.img.w; x++ {
+			if sc := coarse.score(x, y); sc >= cthreshold {
+				cands = append(cands, imageMatch{x * k, y * k, sc})
+			}
+		}
+	}
+	sort.Slice(cands, func(i, j int) bool { return cands[i].score > cands[j].score })
+
+	overlaps := func(ms []imageMatch, x, y int) bool {
+		for _, m := range ms {
+			if abs(m.x-x) < tpl.w/2+1 && abs(m.y-y) < tpl.h/2+1 {
+				return true
+			}
+		}
+		return false
+	}
+
+	fine := newMatcher(img, tpl)
+	var refined, matches []imageMatch
+	for _, c := range cands {
+		if len(refined) >= maxImageMatches {
+			break
+		}
+		if overlaps(refined, c.x, c.y) {
+			continue
+		}
+		best := imageMatch{score: -2}
+		for y := c.y - k; y <= c.y+k; y++ {
+			for x := c.x - k; x <= c.x+k; x++ {
+				if x < 0 || y < 0 || x+tpl.w > img.w || y+tpl.h > img.h {
+					continue
+				}
+				if sc := fine.score(x, y); sc > best.score {
+					best = imageMatch{x, y, sc}
+				}
+			}
+		}
+		refined = append(refined, c)
+		if best.score >= threshold && !overlaps(matches, best.x, best.y) {
+			matches = append(matches, best)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+	return matches
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// FindByImage locates a template PNG image on a screenshot of the viewport
+// and returns the matching regions, best first, for widgets without a DOM to
+// query such as canvas charts. The template must be captured at the device
+// pixel ratio of the session, e.g. cropped out of a Screenshot. threshold is
+// the minimum normalized cross-correlation of a match, between 0 and 1; 0.9
+// is a good start. It returns ErrNotFound if nothing matches.
+func (s *Session) FindByImage(pngData []byte, threshold float64) ([]*ScreenRegion, error) {
+	timg, err := png.Decode(bytes.NewReader(pngData))
+	if err != nil {
+		return nil, fmt.Errorf("invalid template image: %v", err)
+	}
+	tpl := toGray(timg)
+
+	ratio, err := s.devicePixelRatio()
+	if err != nil {
+		return nil, err
+	}
+	shot, err := s.Screenshot()
+	if err != nil {
+		return nil, err
+	}
+	simg, err := png.Decode(bytes.NewReader(shot))
+	if err != nil {
+		return nil, err
+	}
+
+	matches := matchTemplate(toGray(simg), tpl, threshold)
+	if len(matches) == 0 {
+		return nil, ErrNotFound
+	}
+	regions := make([]*ScreenRegion, 0, len(matches))
+	for _, m := range matches {
+		regions = append(regions, &ScreenRegion{
+			s:      s,
+			X:      float64(m.x) / ratio,
+			Y:      float64(m.y) / ratio,
+			Width:  float64(tpl.w) / ratio,
+			Height: float64(tpl.h) / ratio,
+			Score:  m.score,
+		})
+	}
+	return regions, nil
+}
@@ -0,0 +1,44 @@
+package webdriver
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestMatchTemplate(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	img := &grayImage{w: 400, h: 300, pix: make([]float64, 400*300)}
+	for i := range img.pix {
+		img.pix[i] = float64(r.Intn(256))
+	}
+
+	// A smooth pattern, so that it survives downscaling.
+	tpl := &grayImage{w: 48, h: 32, pix: make([]float64, 48*32)}
+	for y := 0; y < tpl.h; y++ {
+		for x := 0; x < tpl.w; x++ {
+			tpl.pix[y*tpl.w+x] = float64((x/8+y/8)%2) * 255
+		}
+	}
+	paste := func(px, py int) {
+		for y := 0; y < tpl.h; y++ {
+			copy(img.pix[(py+y)*img.w+px:], tpl.pix[y*tpl.w:(y+1)*tpl.w])
+		}
+	}
+	paste(123, 57)
+	paste(301, 211)
+
+	matches := matchTemplate(img, tpl, 0.9)
+	if len(matches) != 2 {
+		t.Fatalf("matchTemplate() returned %d matches, want 2: %v", len(matches), matches)
+	}
+	found := map[[2]int]bool{}
+	for _, m := range matches {
+		found[[2]int{m.x, m.y}] = true
+		if m.score < 0.99 {
+			t.Errorf("match at %d, %d scored %v", m.x, m.y, m.score)
+		}
+	}
+	if !found[[2]int{123, 57}] || !found[[2]int{301, 211}] {
+		t.Errorf("matchTemplate() = %v, want matches at 123,57 and 301,211", matches)
+	}
+}
@@ -0,0 +1,173 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode"
+	"unicode/utf16"
+)
+
+// fieldValueScript focuses the element in arguments[0] and returns its state,
+// see fieldState: its value, or its text for contenteditable elements, and
+// the selection in UTF-16 code units, at the end if it is not in the element.
+const fieldValueScript = `var el = arguments[0];
+if (document.activeElement !== el) {
+	el.focus();
+}
+if ("value" in el && !el.isContentEditable) {
+	var start = el.value.length, end = start;
+	try {
+		if (typeof el.selectionStart === "number") {
+			start = el.selectionStart;
+			end = el.selectionEnd;
+		}
+	} catch (e) {}
+	return {field: true, value: el.value, start: start, end: end};
+}
+var text = el.textContent, start = text.length, end = start;
+var sel = window.getSelection();
+if (sel.rangeCount > 0) {
+	var sr = sel.getRangeAt(0);
+	if (el.contains(sr.startContainer) && el.contains(sr.endContainer)) {
+		var r = document.createRange();
+		r.selectNodeContents(el);
+		r.setEnd(sr.startContainer, sr.startOffset);
+		start = r.toString().length;
+		r.setEnd(sr.endContainer, sr.endOffset);
+		end = r.toString().length;
+	}
+}
+return {field: false, value: text, start: start, end: end};`
+
+// restoreValueScript sets the value of the form field in arguments[0] to
+// arguments[1] the way frameworks notice, through the native setter, and
+// selects from arguments[2] to arguments[3] again.
+const restoreValueScript = `var el = arguments[0];
+var proto = Object.getPrototypeOf(el);
+var desc = Object.getOwnPropertyDescriptor(proto, "value");
+desc && desc.set ? desc.set.call(el, arguments[1]) : el.value = arguments[1];
+el.dispatchEvent(new Event("input", {bubbles: true}));
+el.focus();
+try {
+	el.setSelectionRange(arguments[2], arguments[3]);
+} catch (e) {}`
+
+// fieldState is the state of an element typed into, see fieldValueScript.
+type fieldState struct {
+	// Field is set for form fields, as opposed to contenteditable elements.
+	Field bool   `json:"field"`
+	Value string `json:"value"`
+	// Start and End delimit the selection, in UTF-16 code units as in the
+	// page.
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// insert returns the value with text typed over the selection.
+func (f *fieldState) insert(text string) string {
+	u := utf16.Encode([]rune(f.Value))
+	start, end := f.Start, f.End
+	if start < 0 || start > end || end > len(u) {
+		start, end = len(u), len(u)
+	}
+	return string(utf16.Decode(u[:start])) + text + string(utf16.Decode(u[end:]))
+}
+
+// InsertText inserts text at the caret of the focused element, as an input
+// method commits composed text, firing beforeinput and input events but no key
+// events. It uses Input.insertText of DevTools where available, and
+// document.execCommand otherwise.
+func (s *Session) InsertText(text string) error {
+	err := s.cdp("Input.insertText", map[string]interface{}{"text": text}, nil)
+	if err == nil {
+		return nil
+	}
+	debugLog("error inserting text over DevTools, falling back to execCommand: %v", err)
+	ret, err := s.ExecuteScript(`return document.execCommand("insertText", false, arguments[0]);`, []interface{}{text})
+	if err != nil {
+		return err
+	}
+	if ok, _ := ret.(bool); !ok {
+		return fmt.Errorf("browser refused to insert text")
+	}
+	return nil
+}
+
+// TypeText types text into the element, reliably for any script. ASCII text
+// is typed with SendKeys. Other text, e.g. Chinese, Japanese or emoji, which
+// some drivers and inputs mangle when sent as key events, is typed with
+// SendKeys into form fields and checked; if the field did not receive the
+// text, its previous value is restored and the text inserted as input methods
+// do, see InsertText. Text is inserted into contenteditable elements right
+// away.
+func (e *Element) TypeText(text string) error {
+	if isASCII(text) {
+		return e.SendKeys(text)
+	}
+
+	before, err := e.fieldValue()
+	if err != nil {
+		return err
+	}
+	want := before.insert(text)
+	if before.Field {
+		if err := e.SendKeys(text); err != nil {
+			return err
+		}
+		after, err := e.fieldValue()
+		if err != nil {
+			return err
+		}
+		if after.Value == want {
+			return nil
+		}
+		debugLog("SendKeys mangled %q into %q, inserting it instead", text, after.Value)
+		err = e.inFrame(func() error {
+			_, err := e.s.ExecuteScript(restoreValueScript, []interface{}{e.WebElement, before.Value, before.Start, before.End})
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := e.s.InsertText(text); err != nil {
+		return err
+	}
+	after, err := e.fieldValue()
+	if err != nil {
+		return err
+	}
+	if after.Value != want {
+		return fmt.Errorf("element did not receive text %q, has %q", text, after.Value)
+	}
+	return nil
+}
+
+// fieldValue focuses the element and returns its state, see fieldValueScript.
+func (e *Element) fieldValue() (*fieldState, error) {
+	var data []byte
+	err := e.inFrame(func() (err error) {
+		data, err = e.s.ExecuteScriptRaw(fieldValueScript, []interface{}{e.WebElement})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	reply := struct{ Value *fieldState }{}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return nil, err
+	} else if reply.Value == nil {
+		return nil, fmt.Errorf("unexpected field state %s", data)
+	}
+	return reply.Value, nil
+}
+
+func isASCII(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
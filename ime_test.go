@@ -0,0 +1,35 @@
+package webdriver
+
+import "testing"
+
+func TestIsASCII(t *testing.T) {
+	for s, want := range map[string]bool{
+		"":              true,
+		"hello, world~": true,
+		"北京天气":          false,
+		"café":          false,
+		"👍":             false,
+	} {
+		if got := isASCII(s); got != want {
+			t.Errorf("isASCII(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestFieldStateInsert(t *testing.T) {
+	for _, c := range []struct {
+		state fieldState
+		want  string
+	}{
+		{fieldState{Value: "", Start: 0, End: 0}, "北京"},
+		{fieldState{Value: "北京天气", Start: 4, End: 4}, "北京天气北京"},
+		{fieldState{Value: "北京天气", Start: 0, End: 0}, "北京北京天气"},
+		{fieldState{Value: "👍 ok", Start: 2, End: 2}, "👍北京 ok"},
+		{fieldState{Value: "a 天气 b", Start: 2, End: 4}, "a 北京 b"},
+		{fieldState{Value: "abc", Start: 5, End: 5}, "abc北京"},
+	} {
+		if got := c.state.insert("北京"); got != c.want {
+			t.Errorf("%+v.insert = %q, want %q", c.state, got, c.want)
+		}
+	}
+}
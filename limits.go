@@ -0,0 +1,148 @@
+package webdriver
+
+import (
+	"sync"
+	"time"
+)
+
+// LimitReason is the limit of a SessionLimits that a session exceeded.
+type LimitReason string
+
+const (
+	// LimitLifetime is reported when a browser outlived MaxLifetime.
+	LimitLifetime LimitReason = "lifetime"
+	// LimitIdle is reported when a session was idle for longer than MaxIdle.
+	LimitIdle LimitReason = "idle"
+)
+
+// SessionLimits configures WithSessionLimits. A zero limit is disabled.
+type SessionLimits struct {
+	// MaxLifetime is the age after which the browser is recycled, bounding
+	// the memory extremely long-lived browsers accumulate.
+	MaxLifetime time.Duration
+	// MaxIdle is the duration without commands after which the session is
+	// considered idle.
+	MaxIdle time.Duration
+	// Recycle restarts the browser, as Session.Restart does, once a limit is
+	// exceeded. Otherwise only OnLimit is called. Sessions of CDPDriver
+	// cannot restart, so New rejects Recycle for them.
+	Recycle bool
+	// OnLimit, if set, is called once each time a limit is exceeded, after
+	// the browser was recycled if Recycle is set, with the error of the
+	// restart. It runs on the watcher goroutine.
+	OnLimit func(s *Session, reason LimitReason, err error)
+	// Interval is the period between checks. It defaults to a quarter of the
+	// smallest limit, but at least one second.
+	Interval time.Duration
+}
+
+// WithSessionLimits bounds the lifetime and idle time of the session as set
+// by limits.
+func WithSessionLimits(limits SessionLimits) Option {
+	return func(c *sessionConfig) {
+		c.limits = &limits
+	}
+}
+
+// limitWatcher checks a session against its limits.
+type limitWatcher struct {
+	s      *Session
+	limits SessionLimits
+	done   chan struct{}
+	once   sync.Once
+
+	// started is the time the current browser started, and notified the
+	// limits already reported for it or for the current idle period.
+	started  time.Time
+	notified map[LimitReason]bool
+}
+
+func startLimitWatcher(s *Session, limits SessionLimits) *limitWatcher {
+	if limits.Interval <= 0 {
+		var min time.Duration
+		for _, d := range []time.Duration{limits.MaxLifetime, limits.MaxIdle} {
+			if d > 0 && (min == 0 || d < min) {
+				min = d
+			}
+		}
+		limits.Interval = min / 4
+		if limits.Interval < time.Second {
+			limits.Interval = time.Second
+		}
+	}
+	w := &limitWatcher{
+		s:        s,
+		limits:   limits,
+		done:     make(chan struct{}),
+		started:  s.createdAt,
+		notified: map[LimitReason]bool{},
+	}
+	go w.run()
+	return w
+}
+
+func (w *limitWatcher) run() {
+	ticker := time.NewTicker(w.limits.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.done:
+			return
+		}
+		if reason, ok := w.exceeded(time.Now()); ok {
+			w.handle(reason)
+		}
+	}
+}
+
+// exceeded returns the first limit exceeded at now that was not reported yet.
+func (w *limitWatcher) exceeded(now time.Time) (LimitReason, bool) {
+	if w.limits.MaxLifetime > 0 && now.Sub(w.started) > w.limits.MaxLifetime && !w.notified[LimitLifetime] {
+		return LimitLifetime, true
+	}
+
+	last := w.s.LastActivity()
+	if last.Before(w.started) {
+		last = w.started
+	}
+	if w.limits.MaxIdle > 0 {
+		if now.Sub(last) <= w.limits.MaxIdle {
+			// A new idle period starts with the next command.
+			w.notified[LimitIdle] = false
+		} else if !w.notified[LimitIdle] {
+			return LimitIdle, true
+		}
+	}
+	return "", false
+}
+
+func (w *limitWatcher) handle(reason LimitReason) {
+	w.notified[reason] = true
+	var err error
+	if w.limits.Recycle {
+		debugLog("recycling session %v after exceeding its %v limit", w.s.SessionID(), reason)
+		if err = w.s.Restart(); err == nil {
+			w.started = time.Now()
+			w.notified = map[LimitReason]bool{}
+		}
+	}
+	if w.limits.OnLimit != nil {
+		w.limits.OnLimit(w.s, reason, err)
+	}
+}
+
+// stop stops the watcher. It does not wait for a running check, so that
+// OnLimit may close the session.
+func (w *limitWatcher) stop() {
+	w.once.Do(func() { close(w.done) })
+}
+
+func (s *Session) stopLimitWatcher() {
+	s.mu.Lock()
+	w := s.limits
+	s.mu.Unlock()
+	if w != nil {
+		w.stop()
+	}
+}
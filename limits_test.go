@@ -0,0 +1,109 @@
+package webdriver
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLimitWatcherExceeded(t *testing.T) {
+	start := time.Now()
+	s := &Session{createdAt: start}
+	w := &limitWatcher{
+		s:        s,
+		limits:   SessionLimits{MaxLifetime: time.Hour, MaxIdle: time.Minute},
+		started:  start,
+		notified: map[LimitReason]bool{},
+	}
+
+	if reason, ok := w.exceeded(start.Add(30 * time.Second)); ok {
+		t.Errorf("exceeded after 30s = %v", reason)
+	}
+	reason, ok := w.exceeded(start.Add(2 * time.Minute))
+	if !ok || reason != LimitIdle {
+		t.Fatalf("exceeded after 2m = %v, %v, want %v", reason, ok, LimitIdle)
+	}
+	w.notified[reason] = true
+	if reason, ok := w.exceeded(start.Add(3 * time.Minute)); ok {
+		t.Errorf("exceeded again in the same idle period = %v", reason)
+	}
+	reason, ok = w.exceeded(start.Add(2 * time.Hour))
+	if !ok || reason != LimitLifetime {
+		t.Errorf("exceeded after 2h = %v, %v, want %v", reason, ok, LimitLifetime)
+	}
+}
+
+func TestLastActivity(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	active := start.Add(time.Minute)
+	for _, wd := range []WebDriver{
+		&remoteWD{lastActive: active.UnixNano()},
+		&cdpWD{lastActive: active.UnixNano()},
+	} {
+		s := &Session{WebDriver: wd, createdAt: start}
+		if got := s.LastActivity(); !got.Equal(active) {
+			t.Errorf("LastActivity() of %T = %v, want %v", wd, got, active)
+		}
+	}
+}
+
+func TestRemoteRestart(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		paths []string
+		next  = 1
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		mu.Lock()
+		defer mu.Unlock()
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		if r.Method == "POST" && r.URL.Path == "/session" {
+			next++
+			fmt.Fprintf(w, `{"value": {"sessionId": "%v", "capabilities": {}}}`, next)
+			return
+		}
+		w.Write([]byte(`{"value": "https://example.com/"}`))
+	}))
+	defer srv.Close()
+
+	wd := &remoteWD{urlPrefix: srv.URL, id: "1"}
+	inSetup, resume := make(chan struct{}), make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- wd.restart(func(nw WebDriver) error {
+			close(inSetup)
+			<-resume
+			return nil
+		})
+	}()
+
+	<-inSetup
+	waited := make(chan error)
+	go func() {
+		_, err := wd.CurrentURL()
+		waited <- err
+	}()
+	select {
+	case err := <-waited:
+		t.Fatalf("CurrentURL() returned %v during the restart", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(resume)
+	if err := <-done; err != nil {
+		t.Fatalf("restart() returned error: %v", err)
+	}
+	if err := <-waited; err != nil {
+		t.Fatalf("CurrentURL() returned error: %v", err)
+	}
+
+	want := []string{"DELETE /session/1", "POST /session", "GET /session/2/url"}
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(paths) != fmt.Sprint(want) {
+		t.Errorf("requests = %v, want %v", paths, want)
+	}
+}
@@ -0,0 +1,86 @@
+package webdriver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TextOptions configures GetByText.
+type TextOptions struct {
+	// Exact requires the whole normalized text to match instead of
+	// containing the text.
+	Exact bool
+	// IgnoreCase compares the text case-insensitively. Only ASCII letters
+	// are folded.
+	IgnoreCase bool
+	// Tag restricts the match to elements with the tag name, e.g. "button".
+	Tag string
+}
+
+// xpathLiteral quotes s as an XPath 1.0 string literal, which has no escapes.
+func xpathLiteral(s string) string {
+	if !strings.Contains(s, "'") {
+		return "'" + s + "'"
+	}
+	if !strings.Contains(s, `"`) {
+		return `"` + s + `"`
+	}
+	parts := strings.Split(s, "'")
+	for i, p := range parts {
+		parts[i] = "'" + p + "'"
+	}
+	return "concat(" + strings.Join(parts, `, "'", `) + ")"
+}
+
+const (
+	xpathUpper = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	xpathLower = "abcdefghijklmnopqrstuvwxyz"
+)
+
+// textPredicate returns an XPath predicate comparing the normalized text of
+// the context node with text.
+func textPredicate(text string, exact, ignoreCase bool) string {
+	value := "normalize-space(.)"
+	text = strings.Join(strings.Fields(text), " ")
+	if ignoreCase {
+		value = fmt.Sprintf("translate(%s, '%s', '%s')", value, xpathUpper, xpathLower)
+		text = strings.ToLower(text)
+	}
+	if exact {
+		return fmt.Sprintf("%s = %s", value, xpathLiteral(text))
+	}
+	return fmt.Sprintf("contains(%s, %s)", value, xpathLiteral(text))
+}
+
+// TextXPath returns the xpath of the elements whose normalized text matches
+// text. Without a tag, only the innermost matches are selected, not their
+// ancestors that merely contain them.
+func TextXPath(text string, opts TextOptions) string {
+	pred := textPredicate(text, opts.Exact, opts.IgnoreCase)
+	if opts.Tag != "" {
+		return fmt.Sprintf("//%s[%s]", opts.Tag, pred)
+	}
+	return fmt.Sprintf("//*[%s and not(.//*[%s])]", pred, pred)
+}
+
+// LabelXPath returns the xpath of the form controls labelled labelText, by a
+// <label> element or an aria-label attribute.
+func LabelXPath(labelText string) string {
+	pred := textPredicate(labelText, true, false)
+	controls := "self::input or self::select or self::textarea or self::button"
+	return strings.Join([]string{
+		fmt.Sprintf("//*[@id = //label[%s]/@for]", pred),
+		fmt.Sprintf("//label[%s]//*[%s]", pred, controls),
+		fmt.Sprintf("//*[@aria-label = %s]", xpathLiteral(strings.TrimSpace(labelText))),
+	}, " | ")
+}
+
+// GetByText waits for an element with the given text, see TextXPath.
+func (s *Session) GetByText(text string, opts TextOptions) (*Element, error) {
+	return s.GetDOM(TextXPath(text, opts))
+}
+
+// GetByLabel waits for the form control labelled labelText, see LabelXPath.
+func (s *Session) GetByLabel(labelText string) (*Element, error) {
+	return s.GetDOM(LabelXPath(labelText))
+}
@@ -0,0 +1,33 @@
+package webdriver
+
+import "testing"
+
+func TestXPathLiteral(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"plain", "'plain'"},
+		{"it's", `"it's"`},
+		{`it's "x"`, `concat('it', "'", 's "x"')`},
+	} {
+		if got := xpathLiteral(tc.in); got != tc.want {
+			t.Errorf("xpathLiteral(%q) = %s, want %s", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestTextXPath(t *testing.T) {
+	for _, tc := range []struct {
+		text string
+		opts TextOptions
+		want string
+	}{
+		{"Sign  in", TextOptions{Exact: true, Tag: "button"}, "//button[normalize-space(.) = 'Sign in']"},
+		{"Next", TextOptions{}, "//*[contains(normalize-space(.), 'Next') and not(.//*[contains(normalize-space(.), 'Next')])]"},
+		{"OK", TextOptions{Exact: true, IgnoreCase: true, Tag: "a"}, "//a[translate(normalize-space(.), 'ABCDEFGHIJKLMNOPQRSTUVWXYZ', 'abcdefghijklmnopqrstuvwxyz') = 'ok']"},
+	} {
+		if got := TextXPath(tc.text, tc.opts); got != tc.want {
+			t.Errorf("TextXPath(%q, %+v) =\n%s\nwant\n%s", tc.text, tc.opts, got, tc.want)
+		}
+	}
+}
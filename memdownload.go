@@ -0,0 +1,180 @@
+package webdriver
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// MemoryDownload is a download captured by DownloadToMemory.
+type MemoryDownload struct {
+	URL      string
+	Filename string
+	MimeType string
+	Data     []byte
+}
+
+type pausedResponse struct {
+	RequestID string `json:"requestId"`
+	Request   struct {
+		URL string `json:"url"`
+	} `json:"request"`
+	ResourceType    string `json:"resourceType"`
+	ResponseHeaders []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"responseHeaders"`
+}
+
+func (p *pausedResponse) header(name string) string {
+	for _, h := range p.ResponseHeaders {
+		if strings.EqualFold(h.Name, name) {
+			return h.Value
+		}
+	}
+	return ""
+}
+
+// downloadFilename returns the file name of a response, from its
+// Content-Disposition header or its URL.
+func downloadFilename(disposition, rawURL string) string {
+	if _, params, err := mime.ParseMediaType(disposition); err == nil && params["filename"] != "" {
+		return path.Base(strings.Replace(params["filename"], "\\", "/", -1))
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		if name := path.Base(u.Path); name != "/" && name != "." {
+			return name
+		}
+	}
+	return ""
+}
+
+// DownloadToMemory runs trigger, e.g. a click on a download link, and
+// returns the first download it starts without the file reaching the disk,
+// so that downloads work on read-only filesystems. The response is captured
+// by intercepting the requests of the current window whose URL matches
+// match, where * matches any sequence of characters; it is taken if it is an
+// attachment, or if match is not empty and the response replaces the
+// document. It waits up to the session timeout for the download.
+func (s *Session) DownloadToMemory(trigger func() error, match string) (*MemoryDownload, error) {
+	handle, err := s.CurrentWindowHandle()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := s.dialPage(strings.TrimPrefix(handle, "CDwindow-"))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	pattern := match
+	if pattern == "" {
+		pattern = "*"
+	}
+	re := globRegexp(pattern)
+
+	type result struct {
+		d   *MemoryDownload
+		err error
+	}
+	results := make(chan result, 1)
+	var taken bool
+	conn.on("Fetch.requestPaused", func(params json.RawMessage) {
+		p := &pausedResponse{}
+		if err := json.Unmarshal(params, p); err != nil {
+			return
+		}
+		disposition := p.header("Content-Disposition")
+		isDownload := strings.HasPrefix(strings.ToLower(strings.TrimSpace(disposition)), "attachment") ||
+			match != "" && (p.ResourceType == "Document" || p.ResourceType == "Other")
+		if taken || !re.MatchString(p.Request.URL) || !isDownload {
+			conn.send("Fetch.continueRequest", map[string]interface{}{"requestId": p.RequestID})
+			return
+		}
+		taken = true
+
+		// Reading the body needs replies from the read loop.
+		go func() {
+			data, err := readResponseBody(conn, p.RequestID)
+			// Failing the request cancels the download to disk.
+			conn.call("Fetch.failRequest", map[string]interface{}{"requestId": p.RequestID, "errorReason": "Aborted"})
+			if err != nil {
+				results <- result{err: err}
+				return
+			}
+			mimeType, _, _ := mime.ParseMediaType(p.header("Content-Type"))
+			results <- result{d: &MemoryDownload{
+				URL:      p.Request.URL,
+				Filename: downloadFilename(disposition, p.Request.URL),
+				MimeType: mimeType,
+				Data:     data,
+			}}
+		}()
+	})
+	if _, err := conn.call("Fetch.enable", map[string]interface{}{
+		"patterns": []map[string]interface{}{{"urlPattern": pattern, "requestStage": "Response"}},
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := trigger(); err != nil {
+		return nil, err
+	}
+
+	select {
+	case r := <-results:
+		return r.d, r.err
+	case <-conn.closed:
+		return nil, fmt.Errorf("devtools connection closed")
+	case <-time.After(s.timeout):
+		return nil, s.fail("DownloadToMemory", match, &TimeoutError{Elapsed: s.timeout})
+	}
+}
+
+// readResponseBody reads the body of a paused response as a stream.
+func readResponseBody(conn *cdpConn, requestID string) ([]byte, error) {
+	data, err := conn.call("Fetch.takeResponseBodyAsStream", map[string]interface{}{"requestId": requestID})
+	if err != nil {
+		return nil, err
+	}
+	stream := struct {
+		Stream string `json:"stream"`
+	}{}
+	if err := json.Unmarshal(data, &stream); err != nil {
+		return nil, err
+	}
+	defer conn.call("IO.close", map[string]interface{}{"handle": stream.Stream})
+
+	var body []byte
+	for {
+		data, err := conn.call("IO.read", map[string]interface{}{"handle": stream.Stream, "size": 1 << 20})
+		if err != nil {
+			return nil, err
+		}
+		chunk := struct {
+			Data          string `json:"data"`
+			Base64Encoded bool   `json:"base64Encoded"`
+			EOF           bool   `json:"eof"`
+		}{}
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return nil, err
+		}
+		if chunk.Base64Encoded {
+			b, err := base64.StdEncoding.DecodeString(chunk.Data)
+			if err != nil {
+				return nil, err
+			}
+			body = append(body, b...)
+		} else {
+			body = append(body, chunk.Data...)
+		}
+		if chunk.EOF {
+			return body, nil
+		}
+	}
+}
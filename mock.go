@@ -0,0 +1,220 @@
+package webdriver
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// mockRule is a canned response registered by MockResponse.
+type mockRule struct {
+	pattern string
+	re      *regexp.Regexp
+	status  int
+	headers map[string]string
+	body    []byte
+}
+
+// mocker intercepts the requests of a target to fulfill them with mocks, or
+// to fail them if they are blocked. The fields below mu are guarded by it,
+// since requests are handled on the read loop of the connection.
+type mocker struct {
+	conn *cdpConn
+
+	mu    sync.Mutex
+	rules []*mockRule
+
+	// block is the filter list of SetFilterList, and mainFrame the frame
+	// telling documents from subdocuments.
+	block     *FilterList
+	mainFrame string
+	// blockTypes are the resource types of SetBlockedResourceTypes.
+	blockTypes []string
+	blocked    int
+}
+
+// globRegexp compiles a URL pattern where * matches any sequence of
+// characters and ? any single character, as in DevTools patterns.
+func globRegexp(pattern string) *regexp.Regexp {
+	re := regexp.QuoteMeta(pattern)
+	re = strings.Replace(re, `\*`, ".*", -1)
+	re = strings.Replace(re, `\?`, ".", -1)
+	return regexp.MustCompile("^" + re + "$")
+}
+
+// MockResponse makes the requests of the current window whose URL matches
+// pattern, where * matches any sequence of characters and ? any single
+// character, e.g. "*/api/users*", answer with the given response instead of
+// reaching the network, so that pages can be tested against canned API
+// responses. Later mocks take precedence over earlier ones for the same
+// request. The requests are intercepted through a DevTools connection to the
+// window, which requires a local Chromium-based browser.
+func (s *Session) MockResponse(pattern string, status int, headers map[string]string, body []byte) error {
+	conn, err := s.pageConn()
+	if err != nil {
+		return err
+	}
+
+	m := s.interceptor(conn)
+	m.mu.Lock()
+	m.rules = append(m.rules, &mockRule{
+		pattern: pattern,
+		re:      globRegexp(pattern),
+		status:  status,
+		headers: headers,
+		body:    body,
+	})
+	patterns := m.patterns()
+	m.mu.Unlock()
+
+	return m.enable(patterns)
+}
+
+// interceptor returns the mocker of conn, creating it if needed.
+func (s *Session) interceptor(conn *cdpConn) *mocker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := s.mocks[conn]
+	if m == nil {
+		m = &mocker{conn: conn}
+		if s.mocks == nil {
+			s.mocks = map[*cdpConn]*mocker{}
+		}
+		s.mocks[conn] = m
+		conn.on("Fetch.requestPaused", m.handle)
+	}
+	return m
+}
+
+// patterns returns the request patterns to intercept: all requests while
+// blocking by a filter list, or those of the mocks and blocked types. m.mu
+// must be held.
+func (m *mocker) patterns() []map[string]interface{} {
+	if m.block != nil {
+		return []map[string]interface{}{{"urlPattern": "*"}}
+	}
+	patterns := make([]map[string]interface{}, 0, len(m.rules)+len(m.blockTypes))
+	for _, r := range m.rules {
+		patterns = append(patterns, map[string]interface{}{"urlPattern": r.pattern})
+	}
+	for _, typ := range m.blockTypes {
+		patterns = append(patterns, map[string]interface{}{"urlPattern": "*", "resourceType": typ})
+	}
+	return patterns
+}
+
+// enable intercepts the requests matching patterns, or none if there are no
+// patterns.
+func (m *mocker) enable(patterns []map[string]interface{}) error {
+	if len(patterns) == 0 {
+		_, err := m.conn.call("Fetch.disable", nil)
+		return err
+	}
+	_, err := m.conn.call("Fetch.enable", map[string]interface{}{"patterns": patterns})
+	return err
+}
+
+// ClearMocks removes the mocks of the current window.
+func (s *Session) ClearMocks() error {
+	conn, err := s.pageConn()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	m := s.mocks[conn]
+	s.mu.Unlock()
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	m.rules = nil
+	patterns := m.patterns()
+	m.mu.Unlock()
+	return m.enable(patterns)
+}
+
+// handle answers a paused request. It runs on the read loop of the
+// connection, so the replies are sent without waiting for their result.
+func (m *mocker) handle(params json.RawMessage) {
+	ev := struct {
+		RequestID string `json:"requestId"`
+		Request   struct {
+			URL     string            `json:"url"`
+			Headers map[string]string `json:"headers"`
+		} `json:"request"`
+		FrameID      string `json:"frameId"`
+		ResourceType string `json:"resourceType"`
+	}{}
+	if err := json.Unmarshal(params, &ev); err != nil {
+		return
+	}
+
+	blocked, r := m.match(ev.Request.URL, ev.ResourceType, ev.FrameID, ev.Request.Headers)
+	if blocked {
+		if _, err := m.conn.send("Fetch.failRequest", map[string]interface{}{
+			"requestId":   ev.RequestID,
+			"errorReason": "BlockedByClient",
+		}); err != nil {
+			debugLog("error blocking %v: %v", ev.Request.URL, err)
+		}
+		return
+	}
+
+	if r != nil {
+		headers := []map[string]string{}
+		for k, v := range r.headers {
+			headers = append(headers, map[string]string{"name": k, "value": v})
+		}
+		status := r.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if _, err := m.conn.send("Fetch.fulfillRequest", map[string]interface{}{
+			"requestId":       ev.RequestID,
+			"responseCode":    status,
+			"responseHeaders": headers,
+			"body":            base64.StdEncoding.EncodeToString(r.body),
+		}); err != nil {
+			debugLog("error mocking %v: %v", ev.Request.URL, err)
+		}
+		return
+	}
+
+	if _, err := m.conn.send("Fetch.continueRequest", map[string]interface{}{"requestId": ev.RequestID}); err != nil {
+		debugLog("error continuing %v: %v", ev.Request.URL, err)
+	}
+}
+
+// match tells whether a request is blocked, counting it if so, or else returns
+// the mock answering it, if any.
+func (m *mocker) match(url, resourceType, frameID string, headers map[string]string) (bool, *mockRule) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	blocked := false
+	for _, typ := range m.blockTypes {
+		blocked = blocked || typ == resourceType
+	}
+	if !blocked && m.block != nil {
+		typ := resourceType
+		if typ == "Document" && frameID != m.mainFrame {
+			typ = "subdocument"
+		}
+		blocked = m.block.Match(url, typ, requestSource(headers))
+	}
+	if blocked {
+		m.blocked++
+		return true, nil
+	}
+
+	for i := len(m.rules) - 1; i >= 0; i-- {
+		if m.rules[i].re.MatchString(url) {
+			return false, m.rules[i]
+		}
+	}
+	return false, nil
+}
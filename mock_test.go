@@ -0,0 +1,22 @@
+package webdriver
+
+import "testing"
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		pattern, url string
+		match        bool
+	}{
+		{"*/api/users*", "https://example.com/api/users?page=2", true},
+		{"*/api/users*", "https://example.com/api/orders", false},
+		{"https://example.com/v?/items", "https://example.com/v2/items", true},
+		{"https://example.com/v?/items", "https://example.com/v10/items", false},
+		{"*.json", "https://example.com/data.json", true},
+		{"*.json", "https://example.com/dataxjson", false},
+	}
+	for _, test := range tests {
+		if got := globRegexp(test.pattern).MatchString(test.url); got != test.match {
+			t.Errorf("globRegexp(%q).MatchString(%q) = %v, want %v", test.pattern, test.url, got, test.match)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MutationOptions selects the changes reported by WatchMutations, as the
+// options of a MutationObserver.
+type MutationOptions struct {
+	ChildList     bool
+	Attributes    bool
+	CharacterData bool
+	// Subtree extends the observation to all the descendants of the element.
+	Subtree bool
+	// AttributeFilter restricts Attributes to the named attributes.
+	AttributeFilter []string
+	// Interval is the period at which the recorded changes are collected
+	// from the page. It defaults to 200ms.
+	Interval time.Duration
+	// Buffer is the capacity of the channel of changes. It defaults to 100.
+	// When it is full, polling pauses and the changes accumulate in the
+	// page.
+	Buffer int
+}
+
+// Mutation is a change recorded by WatchMutations.
+type Mutation struct {
+	// Type is "childList", "attributes" or "characterData".
+	Type string
+	// Target is the element that changed, or the parent of the changed text
+	// for characterData.
+	Target *Element
+	// Added are the elements inserted into Target, for childList.
+	Added []*Element
+	// Removed is the number of nodes removed from Target, for childList.
+	Removed int
+	// AttributeName is the changed attribute, for attributes.
+	AttributeName string
+	// OldValue and Value are the previous and new value of the attribute or
+	// text.
+	OldValue, Value string
+}
+
+// MutationWatcher streams the changes of an element, see WatchMutations.
+type MutationWatcher struct {
+	// C receives the changes in the order they happened. It is closed when
+	// the watcher stops.
+	C <-chan Mutation
+
+	e    *Element
+	id   string
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+
+	mu  sync.Mutex
+	err error
+}
+
+// watchMutationsScript installs a MutationObserver on the element in
+// arguments[0] recording its changes under the id in arguments[1], with the
+// options in arguments[2].
+const watchMutationsScript = `var target = arguments[0], id = arguments[1], init = arguments[2];
+var watches = window.__webdriverMutations = window.__webdriverMutations || {};
+var w = {records: []};
+w.observer = new MutationObserver(function(list) {
+	list.forEach(function(m) {
+		var target = m.target.nodeType === Node.ELEMENT_NODE ? m.target : m.target.parentElement;
+		var rec = {type: m.type, target: target, added: [], removed: m.removedNodes.length, attributeName: m.attributeName || "", oldValue: m.oldValue || ""};
+		m.addedNodes.forEach(function(n) {
+			if (n.nodeType === Node.ELEMENT_NODE) {
+				rec.added.push(n);
+			}
+		});
+		if (m.type === "attributes") {
+			rec.value = m.target.getAttribute(m.attributeName) || "";
+		} else if (m.type === "characterData") {
+			rec.value = m.target.data;
+		}
+		w.records.push(rec);
+	});
+});
+w.observer.observe(target, init);
+watches[id] = w;`
+
+var mutationWatchID int64
+var mutationWatchMu sync.Mutex
+
+// WatchMutations installs a MutationObserver on the element and streams the
+// changes it records, so that live-updating content can be followed without
+// re-querying it. The changes are collected by polling the page, in the frame
+// of the element. Stop the watcher to remove the observer.
+func (e *Element) WatchMutations(opts MutationOptions) (*MutationWatcher, error) {
+	if !opts.ChildList && !opts.Attributes && !opts.CharacterData {
+		return nil, fmt.Errorf("no mutation type selected")
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = 200 * time.Millisecond
+	}
+	buffer := opts.Buffer
+	if buffer <= 0 {
+		buffer = 100
+	}
+
+	mutationWatchMu.Lock()
+	mutationWatchID++
+	id := fmt.Sprintf("w%d", mutationWatchID)
+	mutationWatchMu.Unlock()
+
+	init := map[string]interface{}{
+		"childList":             opts.ChildList,
+		"attributes":            opts.Attributes,
+		"characterData":         opts.CharacterData,
+		"subtree":               opts.Subtree,
+		"attributeOldValue":     opts.Attributes,
+		"characterDataOldValue": opts.CharacterData,
+	}
+	if len(opts.AttributeFilter) > 0 {
+		init["attributeFilter"] = opts.AttributeFilter
+	}
+	err := e.inFrame(func() error {
+		_, err := e.s.ExecuteScript(watchMutationsScript, []interface{}{e.WebElement, id, init})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan Mutation, buffer)
+	w := &MutationWatcher{
+		C:    ch,
+		e:    e,
+		id:   id,
+		done: make(chan struct{}),
+	}
+	w.wg.Add(1)
+	go w.poll(ch, interval)
+	return w, nil
+}
+
+func (w *MutationWatcher) poll(ch chan<- Mutation, interval time.Duration) {
+	defer w.wg.Done()
+	defer close(ch)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.done:
+			return
+		}
+
+		muts, err := w.collect()
+		if err != nil {
+			// The observer is gone with its document, e.g. after a
+			// navigation.
+			w.mu.Lock()
+			w.err = err
+			w.mu.Unlock()
+			return
+		}
+		for _, m := range muts {
+			select {
+			case ch <- m:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+// collectScript drains the changes recorded by the watcher with the id in
+// arguments[0].
+const collectScript = `var w = (window.__webdriverMutations || {})[arguments[0]];
+if (!w) {
+	throw new Error("mutation watcher is gone");
+}
+var records = w.records;
+w.records = [];
+return records;`
+
+// collect drains the changes recorded in the page, in the frame of the
+// element.
+func (w *MutationWatcher) collect() ([]Mutation, error) {
+	s := w.e.s
+	var data []byte
+	err := w.e.inFrame(func() (err error) {
+		data, err = s.ExecuteScriptRaw(collectScript, []interface{}{w.id})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reply := struct {
+		Value []struct {
+			Type          string
+			Target        json.RawMessage
+			Added         []json.RawMessage
+			Removed       int
+			AttributeName string
+			OldValue      string
+			Value         string
+		}
+	}{}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return nil, err
+	}
+
+	muts := make([]Mutation, 0, len(reply.Value))
+	for _, r := range reply.Value {
+		m := Mutation{
+			Type:          r.Type,
+			Removed:       r.Removed,
+			AttributeName: r.AttributeName,
+			OldValue:      r.OldValue,
+			Value:         r.Value,
+		}
+		if we, err := s.DecodeElement(rawValue(r.Target)); err == nil {
+			m.Target = &Element{s: s, WebElement: we, frame: w.e.frame, framed: w.e.framed}
+		}
+		for _, a := range r.Added {
+			if we, err := s.DecodeElement(rawValue(a)); err == nil {
+				m.Added = append(m.Added, &Element{s: s, WebElement: we, frame: w.e.frame, framed: w.e.framed})
+			}
+		}
+		muts = append(muts, m)
+	}
+	return muts, nil
+}
+
+// Stop disconnects the observer and closes C. Changes not yet received are
+// dropped.
+func (w *MutationWatcher) Stop() error {
+	var err error
+	w.once.Do(func() {
+		close(w.done)
+		w.wg.Wait()
+		err = w.e.inFrame(func() error {
+			_, err := w.e.s.ExecuteScript(`var watches = window.__webdriverMutations || {};
+var w = watches[arguments[0]];
+if (w) {
+	w.observer.disconnect();
+	delete watches[arguments[0]];
+}`, []interface{}{w.id})
+			return err
+		})
+	})
+	return err
+}
+
+// Err returns the error that stopped the watcher, if it stopped on its own,
+// e.g. because the page navigated away.
+func (w *MutationWatcher) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
@@ -0,0 +1,172 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// NavigationResult describes how a navigation went, from the Navigation
+// Timing API of the loaded document.
+type NavigationResult struct {
+	// URL is the URL of the document after redirects, or the URL navigated
+	// to for error pages.
+	URL string
+	// Status is the HTTP status of the document, zero if the browser does
+	// not report it, as before Chrome 109, or for error pages.
+	Status int
+	// Redirects is the number of same-origin redirects followed. Browsers
+	// do not count cross-origin redirects.
+	Redirects int
+	// ErrorPage is set if the browser shows its error page, e.g. for DNS
+	// failures or refused connections, instead of a document.
+	ErrorPage bool
+
+	// The phases of the load. Phases skipped, e.g. DNS and Connect for
+	// reused connections, are zero.
+	DNS     time.Duration
+	Connect time.Duration
+	// TTFB is the time from the start of the navigation to the first byte
+	// of the response.
+	TTFB time.Duration
+	// DOMContentLoaded and Load are the times from the start of the
+	// navigation to the end of the respective events.
+	DOMContentLoaded time.Duration
+	Load             time.Duration
+}
+
+// GetWithMetrics is Get reporting what the navigation ended up at. Unlike
+// Get, failing to load the document is not an error: the result tells with
+// its ErrorPage and Status.
+func (s *Session) GetWithMetrics(url string) (*NavigationResult, error) {
+	getErr := s.Get(url)
+	if getErr != nil && !strings.Contains(getErr.Error(), "net::ERR_") {
+		return nil, getErr
+	}
+
+	data, err := s.ExecuteScriptRaw(`var e = performance.getEntriesByType("navigation")[0] || {};
+return {
+	url: location.href,
+	errorPage: location.protocol === "chrome-error:",
+	status: e.responseStatus || 0,
+	redirects: e.redirectCount || 0,
+	dns: (e.domainLookupEnd || 0) - (e.domainLookupStart || 0),
+	connect: (e.connectEnd || 0) - (e.connectStart || 0),
+	ttfb: e.responseStart || 0,
+	domContentLoaded: e.domContentLoadedEventEnd || 0,
+	load: e.loadEventEnd || 0
+};`, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := struct {
+		Value struct {
+			URL              string  `json:"url"`
+			ErrorPage        bool    `json:"errorPage"`
+			Status           int     `json:"status"`
+			Redirects        int     `json:"redirects"`
+			DNS              float64 `json:"dns"`
+			Connect          float64 `json:"connect"`
+			TTFB             float64 `json:"ttfb"`
+			DOMContentLoaded float64 `json:"domContentLoaded"`
+			Load             float64 `json:"load"`
+		}
+	}{}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return nil, err
+	}
+	v := reply.Value
+	res := &NavigationResult{
+		URL:              v.URL,
+		Status:           v.Status,
+		Redirects:        v.Redirects,
+		ErrorPage:        v.ErrorPage || getErr != nil,
+		DNS:              msDuration(v.DNS),
+		Connect:          msDuration(v.Connect),
+		TTFB:             msDuration(v.TTFB),
+		DOMContentLoaded: msDuration(v.DOMContentLoaded),
+		Load:             msDuration(v.Load),
+	}
+	if res.ErrorPage {
+		// The entry of an error page describes the error page.
+		res.URL, res.Status = url, 0
+	}
+	return res, nil
+}
+
+// defaultPageLoadTimeout is the page load timeout of WebDriver sessions.
+const defaultPageLoadTimeout = 5 * time.Minute
+
+// WithNavigationTimeout bounds the navigations of the session by timeout,
+// independently of the timeout of element waits passed to New; navigations
+// are otherwise bound by the 5 minute default of drivers. If stop is set, a
+// navigation exceeding the timeout stops loading the page, as the stop button
+// of the browser does, and succeeds with what has loaded, since the document
+// is usually there long before slow third-party resources finish.
+func WithNavigationTimeout(timeout time.Duration, stop bool) Option {
+	return func(c *sessionConfig) {
+		c.navTimeout, c.navStop = timeout, stop
+	}
+}
+
+// SetPageLoadTimeout sets the timeout of the navigations of the session,
+// which GetTimeout restores after its own.
+func (s *Session) SetPageLoadTimeout(timeout time.Duration) error {
+	if err := s.WebDriver.SetPageLoadTimeout(timeout); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.pageLoadTimeout = timeout
+	s.mu.Unlock()
+	return nil
+}
+
+// GetTimeout is Get bounded by timeout instead of the navigation timeout of
+// the session, see WithNavigationTimeout and SetPageLoadTimeout.
+func (s *Session) GetTimeout(url string, timeout time.Duration, stop bool) error {
+	s.mu.Lock()
+	restore := s.pageLoadTimeout
+	s.mu.Unlock()
+	if restore <= 0 {
+		restore = s.navTimeout
+	}
+	if restore <= 0 {
+		restore = defaultPageLoadTimeout
+	}
+
+	if err := s.WebDriver.SetPageLoadTimeout(timeout); err != nil {
+		return err
+	}
+	defer func() {
+		if err := s.WebDriver.SetPageLoadTimeout(restore); err != nil {
+			debugLog("error restoring page load timeout: %v", err)
+		}
+	}()
+
+	release := acquireNavigation(url)
+	defer release()
+	return s.fail("Get", url, s.navigate(url, stop))
+}
+
+// navigate navigates to url, stopping the load when it times out if stop is
+// set, and dismisses the consent banner of the page, see
+// WithConsentDismissal.
+func (s *Session) navigate(url string, stop bool) error {
+	err := s.WebDriver.Get(url)
+	if stop && isPageLoadTimeout(err) {
+		debugLog("stopping load of %v: %v", url, err)
+		_, err = s.ExecuteScript("window.stop();", nil)
+	}
+	if err == nil && s.consent != nil {
+		s.consent.dismiss(s)
+	}
+	return err
+}
+
+func isPageLoadTimeout(err error) bool {
+	e, ok := errors.Cause(err).(*Error)
+	return ok && (e.Err == "timeout" || e.LegacyCode == 21)
+}
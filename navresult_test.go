@@ -0,0 +1,62 @@
+package webdriver
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestIsPageLoadTimeout(t *testing.T) {
+	for _, c := range []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{&Error{Err: "timeout"}, true},
+		{errors.Wrap(&Error{Err: "timeout"}, "get"), true},
+		{&Error{LegacyCode: 21}, true},
+		{&Error{Err: "script timeout"}, false},
+		{fmt.Errorf("timeout"), false},
+	} {
+		if got := isPageLoadTimeout(c.err); got != c.want {
+			t.Errorf("isPageLoadTimeout(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
+
+// timeoutWD records the page load timeouts set.
+type timeoutWD struct {
+	WebDriver
+	timeouts []time.Duration
+}
+
+func (wd *timeoutWD) SetPageLoadTimeout(timeout time.Duration) error {
+	wd.timeouts = append(wd.timeouts, timeout)
+	return nil
+}
+
+func (wd *timeoutWD) Get(url string) error {
+	return nil
+}
+
+func TestGetTimeoutRestores(t *testing.T) {
+	wd := &timeoutWD{}
+	s := &Session{WebDriver: wd, navTimeout: time.Minute}
+
+	if err := s.GetTimeout("about:blank", time.Second, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetPageLoadTimeout(10 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.GetTimeout("about:blank", time.Second, false); err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Duration{time.Second, time.Minute, 10 * time.Second, time.Second, 10 * time.Second}
+	if !reflect.DeepEqual(wd.timeouts, want) {
+		t.Errorf("page load timeouts = %v, want %v", wd.timeouts, want)
+	}
+}
@@ -0,0 +1,209 @@
+package webdriver
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ScreenRegion is a region of the viewport located on a screenshot, in CSS
+// pixels.
+type ScreenRegion struct {
+	s *Session
+
+	X, Y, Width, Height float64
+	// Score is the confidence of the match, between 0 and 1.
+	Score float64
+}
+
+// Center returns the center of the region.
+func (r *ScreenRegion) Center() (float64, float64) {
+	return r.X + r.Width/2, r.Y + r.Height/2
+}
+
+// Click synthesizes a left click at the center of the region.
+func (r *ScreenRegion) Click() error {
+	x, y := r.Center()
+	return r.s.clickAt(x, y)
+}
+
+// clickAt clicks at the viewport coordinates x, y through DevTools input
+// events, which reach canvas and other elements without a usable DOM.
+func (s *Session) clickAt(x, y float64) error {
+	for _, typ := range []string{"mouseMoved", "mousePressed", "mouseReleased"} {
+		params := map[string]interface{}{"type": typ, "x": x, "y": y}
+		if typ != "mouseMoved" {
+			params["button"] = "left"
+			params["clickCount"] = 1
+		}
+		if err := s.cdp("Input.dispatchMouseEvent", params, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ocrWord is a word recognized by tesseract, in screenshot pixels.
+type ocrWord struct {
+	line            int
+	left, top, w, h int
+	conf            float64
+	text            string
+}
+
+// parseTesseractTSV parses the words of the tsv output of tesseract.
+func parseTesseractTSV(data string) []ocrWord {
+	var words []ocrWord
+	for _, row := range strings.Split(data, "\n") {
+		f := strings.Split(strings.TrimRight(row, "\r"), "\t")
+		// level page block par line word left top width height conf text
+		if len(f) < 12 || f[0] != "5" {
+			continue
+		}
+		var n [9]int
+		for i := range n {
+			n[i], _ = strconv.Atoi(f[i+1])
+		}
+		conf, _ := strconv.ParseFloat(f[10], 64)
+		text := strings.TrimSpace(f[11])
+		if text == "" {
+			continue
+		}
+		words = append(words, ocrWord{
+			line: n[1]*1e6 + n[2]*1e3 + n[3],
+			left: n[5], top: n[6], w: n[7], h: n[8],
+			conf: conf,
+			text: text,
+		})
+	}
+	return words
+}
+
+func normalizeOCRWord(w string) string {
+	return strings.ToLower(strings.TrimFunc(w, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}))
+}
+
+// matchOCRWords returns the bounds and confidence of the first run of words
+// of a line matching text, ignoring case and surrounding punctuation.
+func matchOCRWords(words []ocrWord, text string) (x0, y0, x1, y1 int, conf float64, ok bool) {
+	var want []string
+	for _, w := range strings.Fields(text) {
+		if w = normalizeOCRWord(w); w != "" {
+			want = append(want, w)
+		}
+	}
+	if len(want) == 0 {
+		return
+	}
+
+next:
+	for i := range words {
+		if i+len(want) > len(words) {
+			break
+		}
+		run := words[i : i+len(want)]
+		for j, w := range run {
+			if w.line != run[0].line || normalizeOCRWord(w.text) != want[j] {
+				continue next
+			}
+		}
+
+		x0, y0, conf = run[0].left, run[0].top, 100
+		for _, w := range run {
+			if w.left < x0 {
+				x0 = w.left
+			}
+			if w.top < y0 {
+				y0 = w.top
+			}
+			if w.left+w.w > x1 {
+				x1 = w.left + w.w
+			}
+			if w.top+w.h > y1 {
+				y1 = w.top + w.h
+			}
+			if w.conf < conf {
+				conf = w.conf
+			}
+		}
+		return x0, y0, x1, y1, conf / 100, true
+	}
+	return
+}
+
+// FindByScreenText recognizes the text of a screenshot of the viewport and
+// returns the region of the first occurrence of text, as a last resort for
+// text without a usable DOM, e.g. rendered in a canvas or obfuscated against
+// scraping. The match ignores case and punctuation around words. It returns
+// ErrNotFound if the text is not recognized.
+//
+// It requires tesseract, located through the TESSERACT environment variable
+// and defaulting to tesseract in PATH.
+func (s *Session) FindByScreenText(text string) (*ScreenRegion, error) {
+	bin := strings.TrimSpace(os.Getenv("TESSERACT"))
+	if bin == "" {
+		var err error
+		if bin, err = exec.LookPath("tesseract"); err != nil {
+			return nil, fmt.Errorf("tesseract not found, set TESSERACT: %v", err)
+		}
+	}
+
+	ratio, err := s.devicePixelRatio()
+	if err != nil {
+		return nil, err
+	}
+	img, err := s.Screenshot()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := ioutil.TempFile("", "webdriver-ocr-*.png")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(img)
+	if cErr := f.Close(); err == nil {
+		err = cErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(bin, f.Name(), "stdout", "tsv")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("tesseract failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	x0, y0, x1, y1, conf, ok := matchOCRWords(parseTesseractTSV(string(out)), text)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &ScreenRegion{
+		s:      s,
+		X:      float64(x0) / ratio,
+		Y:      float64(y0) / ratio,
+		Width:  float64(x1-x0) / ratio,
+		Height: float64(y1-y0) / ratio,
+		Score:  conf,
+	}, nil
+}
+
+// ClickScreenText clicks the center of the first occurrence of text
+// recognized on the screen, see FindByScreenText.
+func (s *Session) ClickScreenText(text string) error {
+	r, err := s.FindByScreenText(text)
+	if err != nil {
+		return err
+	}
+	return r.Click()
+}
@@ -0,0 +1,31 @@
+package webdriver
+
+import "testing"
+
+func TestMatchOCRWords(t *testing.T) {
+	tsv := "level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n" +
+		"4\t1\t1\t1\t1\t0\t10\t20\t200\t30\t-1\t\n" +
+		"5\t1\t1\t1\t1\t1\t10\t20\t50\t30\t96.5\tAdd\n" +
+		"5\t1\t1\t1\t1\t2\t70\t22\t40\t28\t91\tto\n" +
+		"5\t1\t1\t1\t1\t3\t120\t20\t60\t30\t88\tCart!\n" +
+		"5\t1\t1\t1\t2\t1\t10\t60\t50\t30\t90\tCheckout\n"
+	words := parseTesseractTSV(tsv)
+	if len(words) != 4 {
+		t.Fatalf("parseTesseractTSV() returned %d words, want 4", len(words))
+	}
+
+	x0, y0, x1, y1, conf, ok := matchOCRWords(words, "add to cart")
+	if !ok {
+		t.Fatal("matchOCRWords() found no match")
+	}
+	if x0 != 10 || y0 != 20 || x1 != 180 || y1 != 50 {
+		t.Errorf("bounds = %d, %d, %d, %d, want 10, 20, 180, 50", x0, y0, x1, y1)
+	}
+	if conf != 0.88 {
+		t.Errorf("confidence = %v, want 0.88", conf)
+	}
+
+	if _, _, _, _, _, ok := matchOCRWords(words, "cart checkout"); ok {
+		t.Error("matchOCRWords() matched words across lines")
+	}
+}
@@ -0,0 +1,154 @@
+package webdriver
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Option configures a session created by New.
+type Option func(*sessionConfig)
+
+type sessionConfig struct {
+	profile       string
+	width, height int
+	headless      bool
+
+	chromeBinary     string
+	chromeArgs       []string
+	excludedSwitches []string
+	hostRules        []string
+
+	artifactsDir string
+	sink         SnapshotSink
+	appium       *AppiumOptions
+	stealth      *StealthOptions
+	popups       *PopupPolicy
+
+	promptBehavior string
+	dialogs        *DialogPolicy
+	profileState   *ProfileState
+	limits         *SessionLimits
+	retry          *CreateRetry
+	delayFirstPoll bool
+	poll           *PollStrategy
+	healLimit      int
+	selectors      *SelectorRegistry
+	frameDepth     int
+	consent        *ConsentOptions
+	filterList     *FilterList
+	blockedTypes   []string
+	navTimeout     time.Duration
+	navStop        bool
+	scroll         *ScrollOptions
+}
+
+// WithChromeBinary runs the browser binary at path, e.g. Chrome Beta or
+// Chromium, instead of the default installation found by the driver.
+func WithChromeBinary(path string) Option {
+	return func(c *sessionConfig) {
+		c.chromeBinary = path
+	}
+}
+
+// WithChromeArgs passes additional command-line flags to the browser, e.g.
+// "lang=de" or "--proxy-server=localhost:8080". The leading dashes are
+// optional. Flags conflicting with each other or with the settings passed to
+// New make New fail.
+func WithChromeArgs(args ...string) Option {
+	return func(c *sessionConfig) {
+		c.chromeArgs = append(c.chromeArgs, args...)
+	}
+}
+
+// WithExcludedSwitches removes switches from the defaults ChromeDriver passes
+// to the browser, e.g. "enable-automation".
+func WithExcludedSwitches(switches ...string) Option {
+	return func(c *sessionConfig) {
+		c.excludedSwitches = append(c.excludedSwitches, switches...)
+	}
+}
+
+// WithHostRules resolves host names as set by the rules, passed to the
+// browser as --host-resolver-rules, so that e.g. a staging server can be
+// tested under its production host name without editing /etc/hosts. Rules
+// are strings such as "MAP example.com 10.0.0.5" or "EXCLUDE localhost", see
+// MapHost and ExcludeHost.
+func WithHostRules(rules ...string) Option {
+	return func(c *sessionConfig) {
+		c.hostRules = append(c.hostRules, rules...)
+	}
+}
+
+// MapHost returns a host rule resolving host, which may contain * wildcards
+// and a port, to target, an IP address or host name with an optional port.
+func MapHost(host, target string) string {
+	return "MAP " + host + " " + target
+}
+
+// ExcludeHost returns a host rule resolving host normally, as an exception
+// to the preceding MAP rules.
+func ExcludeHost(host string) string {
+	return "EXCLUDE " + host
+}
+
+// extraChromeArgs returns the flags passed to the browser on top of the
+// defaults.
+func (c *sessionConfig) extraChromeArgs() []string {
+	if len(c.hostRules) == 0 {
+		return c.chromeArgs
+	}
+	return append(append([]string(nil), c.chromeArgs...), "host-resolver-rules="+strings.Join(c.hostRules, ", "))
+}
+
+// splitFlag returns the name and the value of a command-line flag, without
+// leading dashes.
+func splitFlag(arg string) (string, string) {
+	arg = strings.TrimLeft(strings.TrimSpace(arg), "-")
+	if i := strings.IndexByte(arg, '='); i >= 0 {
+		return arg[:i], arg[i+1:]
+	}
+	return arg, ""
+}
+
+// mergeChromeArgs appends the extra flags to the default ones, dropping exact
+// duplicates. It fails if a flag is repeated with different values or is both
+// passed and excluded.
+func mergeChromeArgs(defaults, extra, excluded []string) ([]string, error) {
+	isExcluded := map[string]bool{}
+	for _, sw := range excluded {
+		name, _ := splitFlag(sw)
+		isExcluded[name] = true
+	}
+
+	var args []string
+	values := map[string]string{}
+	for _, arg := range append(append([]string(nil), defaults...), extra...) {
+		name, value := splitFlag(arg)
+		if name == "" {
+			return nil, fmt.Errorf("invalid chrome flag %q", arg)
+		}
+		if isExcluded[name] {
+			return nil, fmt.Errorf("chrome flag %q is also excluded", name)
+		}
+		if prev, ok := values[name]; ok {
+			if prev != value {
+				return nil, fmt.Errorf("conflicting values for chrome flag %q: %q and %q", name, prev, value)
+			}
+			continue
+		}
+		values[name] = value
+		args = append(args, strings.TrimLeft(strings.TrimSpace(arg), "-"))
+	}
+
+	return args, nil
+}
+
+// WithDelayedFirstPoll restores the behavior of earlier versions where waits,
+// such as GetDOM, sleep a polling interval before their first check, even
+// when the element already exists.
+func WithDelayedFirstPoll() Option {
+	return func(c *sessionConfig) {
+		c.delayFirstPoll = true
+	}
+}
@@ -0,0 +1,46 @@
+package webdriver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeChromeArgs(t *testing.T) {
+	defaults := []string{"window-size=800,600", "disable-notifications"}
+
+	got, err := mergeChromeArgs(defaults, []string{"--lang=de", "disable-notifications", "--incognito"}, []string{"--enable-automation"})
+	if err != nil {
+		t.Fatalf("mergeChromeArgs() returned error: %v", err)
+	}
+	want := []string{"window-size=800,600", "disable-notifications", "lang=de", "incognito"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeChromeArgs() = %q, want %q", got, want)
+	}
+
+	for _, tc := range []struct {
+		extra, excluded []string
+	}{
+		{[]string{"--window-size=1024,768"}, nil},
+		{[]string{"lang=de", "lang=fr"}, nil},
+		{[]string{"incognito"}, []string{"incognito"}},
+		{[]string{"--"}, nil},
+	} {
+		if _, err := mergeChromeArgs(defaults, tc.extra, tc.excluded); err == nil {
+			t.Errorf("mergeChromeArgs(%q, %q) returned nil error", tc.extra, tc.excluded)
+		}
+	}
+}
+
+func TestHostRules(t *testing.T) {
+	cfg := &sessionConfig{chromeArgs: []string{"lang=de"}}
+	WithHostRules(MapHost("*.example.com", "10.0.0.5"), ExcludeHost("cdn.example.com"))(cfg)
+	WithHostRules("MAP api.example.com 127.0.0.1:8080")(cfg)
+
+	want := []string{"lang=de", "host-resolver-rules=MAP *.example.com 10.0.0.5, EXCLUDE cdn.example.com, MAP api.example.com 127.0.0.1:8080"}
+	if got := cfg.extraChromeArgs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("extraChromeArgs() = %q, want %q", got, want)
+	}
+	if len(cfg.chromeArgs) != 1 {
+		t.Errorf("extraChromeArgs() modified the chrome args: %q", cfg.chromeArgs)
+	}
+}
@@ -0,0 +1,130 @@
+package webdriver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ParallelOption configures RunParallel.
+type ParallelOption func(*parallelConfig)
+
+type parallelConfig struct {
+	newSession func() (*Session, error)
+}
+
+// WithSessionFactory sets the function RunParallel uses to provision its
+// sessions. By default, headless 1920x1080 sessions without a profile and with
+// a one minute timeout are created.
+func WithSessionFactory(fn func() (*Session, error)) ParallelOption {
+	return func(c *parallelConfig) {
+		c.newSession = fn
+	}
+}
+
+// TaskError is the failure of one task run by RunParallel.
+type TaskError struct {
+	// Index is the position of the task in the slice passed to RunParallel.
+	Index int
+	Err   error
+}
+
+func (e TaskError) Error() string {
+	return fmt.Sprintf("task %d: %v", e.Index, e.Err)
+}
+
+// ParallelError aggregates the failures of RunParallel, ordered by task index.
+type ParallelError []TaskError
+
+func (e ParallelError) Error() string {
+	msgs := make([]string, len(e))
+	for i, te := range e {
+		msgs[i] = te.Error()
+	}
+	return fmt.Sprintf("%d task(s) failed: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// RunParallel runs the tasks on up to n concurrently provisioned sessions.
+// Each session is reused by the tasks it picks up and is closed before
+// RunParallel returns, even if tasks fail or panic. Once ctx is done, pending
+// tasks are not started and fail with the context error.
+//
+// The returned error is nil or a ParallelError.
+func RunParallel(ctx context.Context, n int, tasks []func(*Session) error, opts ...ParallelOption) error {
+	cfg := parallelConfig{
+		newSession: func() (*Session, error) {
+			return New("", 1920, 1080, true, time.Minute)
+		},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if n < 1 {
+		return fmt.Errorf("invalid concurrency %v", n)
+	}
+	if n > len(tasks) {
+		n = len(tasks)
+	}
+
+	idxCh := make(chan int)
+	errs := make([]error, len(tasks))
+	var wg sync.WaitGroup
+	for w := 0; w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var s *Session
+			defer func() {
+				if s != nil {
+					s.Close()
+				}
+			}()
+
+			for idx := range idxCh {
+				if err := ctx.Err(); err != nil {
+					errs[idx] = err
+					continue
+				}
+				if s == nil {
+					var err error
+					if s, err = cfg.newSession(); err != nil {
+						s = nil
+						errs[idx] = err
+						continue
+					}
+				}
+				errs[idx] = runTask(tasks[idx], s)
+			}
+		}()
+	}
+
+	for idx := range tasks {
+		idxCh <- idx
+	}
+	close(idxCh)
+	wg.Wait()
+
+	var perr ParallelError
+	for idx, err := range errs {
+		if err != nil {
+			perr = append(perr, TaskError{idx, err})
+		}
+	}
+	if len(perr) > 0 {
+		return perr
+	}
+	return nil
+}
+
+// runTask runs the task, converting a panic into an error.
+func runTask(task func(*Session) error, s *Session) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return task(s)
+}
@@ -0,0 +1,40 @@
+package webdriver
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRunParallel(t *testing.T) {
+	var provisioned int32
+	factory := WithSessionFactory(func() (*Session, error) {
+		atomic.AddInt32(&provisioned, 1)
+		return nil, errors.New("no driver")
+	})
+
+	tasks := make([]func(*Session) error, 3)
+	err := RunParallel(context.Background(), 5, tasks, factory)
+	perr, ok := err.(ParallelError)
+	if !ok {
+		t.Fatalf("RunParallel() = %v, want a ParallelError", err)
+	}
+	if len(perr) != len(tasks) {
+		t.Errorf("RunParallel() returned %d task errors, want %d", len(perr), len(tasks))
+	}
+	if provisioned < 1 || int(provisioned) > len(tasks) {
+		t.Errorf("RunParallel() provisioned %d sessions, want between 1 and %d", provisioned, len(tasks))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	provisioned = 0
+	err = RunParallel(ctx, 1, tasks, factory)
+	if perr, ok := err.(ParallelError); !ok || perr[0].Err != context.Canceled {
+		t.Errorf("RunParallel() with canceled context = %v, want context.Canceled errors", err)
+	}
+	if provisioned != 0 {
+		t.Errorf("RunParallel() with canceled context provisioned %d sessions", provisioned)
+	}
+}
@@ -0,0 +1,60 @@
+package webdriver
+
+import (
+	"math/rand"
+	"time"
+)
+
+// PollStrategy sets the intervals at which the waits of a session evaluate
+// their condition: starting fast for low latency on fast pages, then backing
+// off with jitter to limit the load on the driver when many sessions poll at
+// once.
+type PollStrategy struct {
+	// Initial is the first interval. It defaults to 50ms.
+	Initial time.Duration
+	// Max caps the interval. It defaults to one second.
+	Max time.Duration
+	// Factor multiplies the interval after each poll. It defaults to 1.5.
+	Factor float64
+	// Jitter randomizes each interval by up to this fraction of it, e.g. 0.2
+	// for ±20%. It defaults to 0.2; negative disables it.
+	Jitter float64
+}
+
+// WithPollStrategy makes the waits of the session poll as set by strategy
+// instead of every second.
+func WithPollStrategy(strategy PollStrategy) Option {
+	return func(c *sessionConfig) {
+		c.poll = &strategy
+	}
+}
+
+// intervals returns a function producing the successive polling intervals.
+func (p *PollStrategy) intervals() func() time.Duration {
+	if p == nil {
+		return func() time.Duration { return time.Second }
+	}
+	interval, max, factor, jitter := p.Initial, p.Max, p.Factor, p.Jitter
+	if interval <= 0 {
+		interval = 50 * time.Millisecond
+	}
+	if max <= 0 {
+		max = time.Second
+	}
+	if factor < 1 {
+		factor = 1.5
+	}
+	if jitter == 0 {
+		jitter = 0.2
+	}
+	return func() time.Duration {
+		d := interval
+		if interval = time.Duration(float64(interval) * factor); interval > max {
+			interval = max
+		}
+		if jitter > 0 {
+			d += time.Duration((rand.Float64()*2 - 1) * jitter * float64(d))
+		}
+		return d
+	}
+}
@@ -0,0 +1,22 @@
+package webdriver
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPollStrategyIntervals(t *testing.T) {
+	next := (&PollStrategy{Initial: 100 * time.Millisecond, Max: 400 * time.Millisecond, Factor: 2, Jitter: -1}).intervals()
+	for i, want := range []time.Duration{100, 200, 400, 400} {
+		if got := next(); got != want*time.Millisecond {
+			t.Errorf("interval %d = %v, want %v", i, got, want*time.Millisecond)
+		}
+	}
+
+	next = (&PollStrategy{}).intervals()
+	for i := 0; i < 20; i++ {
+		if d := next(); d < 40*time.Millisecond || d > 1200*time.Millisecond {
+			t.Errorf("interval %d = %v, outside of the jittered defaults", i, d)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package webdriver
+
+import (
+	"sync"
+	"time"
+)
+
+// PopupAction is what a PopupPolicy does with new windows.
+type PopupAction int
+
+const (
+	// PopupIgnore leaves new windows alone. They are still reported by
+	// WaitForPopup.
+	PopupIgnore PopupAction = iota
+	// PopupClose closes new windows as soon as they are noticed.
+	PopupClose
+	// PopupSwitch switches to new windows, and back to the window that was
+	// current when they opened once they close, as OAuth popups do.
+	PopupSwitch
+)
+
+// PopupPolicy configures WithPopupPolicy.
+type PopupPolicy struct {
+	Action PopupAction
+	// OnPopup, if set, is called with the handle of each new window, after
+	// the action. It runs on the watcher goroutine.
+	OnPopup func(s *Session, handle string)
+	// Interval is the period at which the windows are polled. It defaults
+	// to 500ms.
+	Interval time.Duration
+}
+
+// WithPopupPolicy makes the session watch for windows opened by the page,
+// e.g. OAuth flows or share dialogs, and handle them as set by policy, so
+// that the automation does not get stranded in the wrong window.
+func WithPopupPolicy(policy PopupPolicy) Option {
+	return func(c *sessionConfig) {
+		c.popups = &policy
+	}
+}
+
+// popupWatcher polls the windows of a session for popups.
+type popupWatcher struct {
+	s      *Session
+	policy PopupPolicy
+	done   chan struct{}
+	once   sync.Once
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	known   map[string]bool
+	openers map[string]string
+	queue   []string
+	changed chan struct{}
+}
+
+func startPopupWatcher(s *Session, policy PopupPolicy) (*popupWatcher, error) {
+	if policy.Interval <= 0 {
+		policy.Interval = 500 * time.Millisecond
+	}
+	handles, err := s.WindowHandles()
+	if err != nil {
+		return nil, err
+	}
+	w := &popupWatcher{
+		s:       s,
+		policy:  policy,
+		done:    make(chan struct{}),
+		known:   map[string]bool{},
+		openers: map[string]string{},
+		changed: make(chan struct{}),
+	}
+	for _, h := range handles {
+		w.known[h] = true
+	}
+	w.wg.Add(1)
+	go w.run()
+	return w, nil
+}
+
+func (w *popupWatcher) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.policy.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.done:
+			return
+		}
+		if err := w.poll(); err != nil {
+			debugLog("error watching popups: %v", err)
+		}
+	}
+}
+
+func (w *popupWatcher) poll() error {
+	handles, err := w.s.WindowHandles()
+	if err != nil {
+		return err
+	}
+	current, err := w.s.CurrentWindowHandle()
+	if err != nil {
+		// The current window closed.
+		current = ""
+	}
+
+	open := map[string]bool{}
+	var added []string
+	w.mu.Lock()
+	for _, h := range handles {
+		open[h] = true
+		if !w.known[h] {
+			w.known[h] = true
+			w.openers[h] = current
+			added = append(added, h)
+		}
+	}
+	var back string
+	for h := range w.known {
+		if open[h] {
+			continue
+		}
+		delete(w.known, h)
+		if h == current || current == "" {
+			back = w.openers[h]
+		}
+		delete(w.openers, h)
+	}
+	if len(added) > 0 {
+		w.queue = append(w.queue, added...)
+		close(w.changed)
+		w.changed = make(chan struct{})
+	}
+	w.mu.Unlock()
+
+	if w.policy.Action == PopupSwitch && back != "" && open[back] {
+		if err := w.s.SwitchWindow(back); err != nil {
+			return err
+		}
+	}
+	for _, h := range added {
+		switch w.policy.Action {
+		case PopupClose:
+			if err := w.s.CloseWindow(h); err != nil {
+				return err
+			}
+		case PopupSwitch:
+			if err := w.s.SwitchWindow(h); err != nil {
+				return err
+			}
+		}
+		if w.policy.OnPopup != nil {
+			w.policy.OnPopup(w.s, h)
+		}
+	}
+	return nil
+}
+
+func (w *popupWatcher) stop() {
+	w.once.Do(func() { close(w.done) })
+	w.wg.Wait()
+}
+
+// popupWatcher returns the watcher of the session, starting one ignoring
+// popups if the session has no policy.
+func (s *Session) popupWatcher() (*popupWatcher, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.popups == nil {
+		w, err := startPopupWatcher(s, PopupPolicy{})
+		if err != nil {
+			return nil, err
+		}
+		s.popups = w
+	}
+	return s.popups, nil
+}
+
+// WaitForPopup waits up to timeout for a window to open and returns its
+// handle. Each window is returned once, the oldest first. Windows are
+// noticed from the session creation with WithPopupPolicy, and from the first
+// call of WaitForPopup otherwise.
+func (s *Session) WaitForPopup(timeout time.Duration) (string, error) {
+	w, err := s.popupWatcher()
+	if err != nil {
+		return "", err
+	}
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for polls := 1; ; polls++ {
+		w.mu.Lock()
+		if len(w.queue) > 0 {
+			h := w.queue[0]
+			w.queue = w.queue[1:]
+			w.mu.Unlock()
+			return h, nil
+		}
+		changed := w.changed
+		w.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-deadline.C:
+			return "", s.fail("WaitForPopup", "", &TimeoutError{Elapsed: timeout, Polls: polls})
+		}
+	}
+}
+
+// stopPopupWatcher stops the popup watcher of the session, if any.
+func (s *Session) stopPopupWatcher() {
+	s.mu.Lock()
+	w := s.popups
+	s.popups = nil
+	s.mu.Unlock()
+	if w != nil {
+		w.stop()
+	}
+}
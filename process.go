@@ -0,0 +1,170 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProcessStats reports the resource usage of the browser processes backing a
+// session: the browser, renderer, GPU and utility processes.
+type ProcessStats struct {
+	// PIDs are the IDs of the processes.
+	PIDs []int
+	// RSS is the combined resident set size of the processes in bytes.
+	RSS uint64
+	// CPUTime is the combined CPU time consumed by the processes.
+	CPUTime time.Duration
+	// CPUPercent is the combined CPU utilization as reported by ps.
+	CPUPercent float64
+}
+
+// ProcessStats queries the browser for its process tree through the
+// SystemInfo.getProcessInfo CDP command and measures the processes with ps.
+// The browser must run on the local machine.
+func (s *Session) ProcessStats() (*ProcessStats, error) {
+	// Only the browser target answers SystemInfo commands.
+	conn, err := s.browserConn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	data, err := conn.call("SystemInfo.getProcessInfo", nil)
+	if err != nil {
+		return nil, err
+	}
+	var reply struct {
+		ProcessInfo []struct {
+			Type    string  `json:"type"`
+			ID      int     `json:"id"`
+			CPUTime float64 `json:"cpuTime"`
+		} `json:"processInfo"`
+	}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return nil, err
+	}
+
+	stats := &ProcessStats{}
+	var pids []string
+	for _, p := range reply.ProcessInfo {
+		stats.PIDs = append(stats.PIDs, p.ID)
+		stats.CPUTime += time.Duration(p.CPUTime * float64(time.Second))
+		pids = append(pids, strconv.Itoa(p.ID))
+	}
+	if len(pids) == 0 {
+		return stats, nil
+	}
+
+	// Processes may exit between the two queries, in which case ps omits them
+	// and exits with an error despite printing the others.
+	out, err := exec.Command("ps", "-o", "rss=,pcpu=", "-p", strings.Join(pids, ",")).Output()
+	if err != nil && len(out) == 0 {
+		return nil, fmt.Errorf("ps: %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if kb, err := strconv.ParseUint(fields[0], 10, 64); err == nil {
+			stats.RSS += kb * 1024
+		}
+		if pcpu, err := strconv.ParseFloat(fields[1], 64); err == nil {
+			stats.CPUPercent += pcpu
+		}
+	}
+
+	return stats, nil
+}
+
+// Restart replaces the browser of the session with a fresh one started with
+// the same capabilities and set up as by New. Commands issued meanwhile wait
+// for the restart. Elements located before the restart become invalid.
+// Sessions of the cdp driver cannot restart.
+func (s *Session) Restart() error {
+	if _, ok := s.WebDriver.(*cdpWD); ok {
+		// Its NewSession fails, which would leave the session without a
+		// browser.
+		return fmt.Errorf("restarting sessions is not supported by the cdp driver")
+	}
+	var err error
+	if wd, ok := s.WebDriver.(*remoteWD); ok {
+		err = wd.restart(s.setup)
+	} else {
+		if err := s.Quit(); err != nil {
+			debugLog("error quitting session %v for restart: %v", s.SessionID(), err)
+		}
+		_, err = s.NewSession()
+	}
+	s.events.driverRestart(s, err)
+	return err
+}
+
+// WatchdogConfig configures StartWatchdog.
+type WatchdogConfig struct {
+	// MaxRSS is the combined resident set size in bytes above which the
+	// browser of a session is restarted.
+	MaxRSS uint64
+	// Interval is the period between checks. It defaults to one minute.
+	Interval time.Duration
+	// OnRestart, if set, is called after a session was restarted, with the
+	// stats that triggered the restart and the restart error, if any.
+	OnRestart func(s *Session, stats *ProcessStats, err error)
+}
+
+// StartWatchdog starts a goroutine that restarts the sessions whose browser
+// uses more memory than cfg.MaxRSS. Long-running headless browsers leak
+// memory; recycling them keeps the host healthy. Sessions of CDPDriver cannot
+// restart, see Session.Restart. The returned function stops the watchdog.
+func StartWatchdog(cfg WatchdogConfig) (stop func()) {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				checkMemory(cfg)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+func checkMemory(cfg WatchdogConfig) {
+	smu.Lock()
+	live := append([]*Session(nil), sessions...)
+	smu.Unlock()
+
+	for _, s := range live {
+		stats, err := s.ProcessStats()
+		if err != nil {
+			debugLog("error reading process stats of session %v: %v", s.SessionID(), err)
+			continue
+		}
+		if stats.RSS <= cfg.MaxRSS {
+			continue
+		}
+
+		debugLog("restarting session %v using %v bytes", s.SessionID(), stats.RSS)
+		err = s.Restart()
+		if cfg.OnRestart != nil {
+			cfg.OnRestart(s, stats, err)
+		}
+	}
+}
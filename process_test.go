@@ -0,0 +1,27 @@
+package webdriver
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProcessStatsBrowserTarget(t *testing.T) {
+	srv, targets := fakeBrowser(t, nil)
+	defer srv.Close()
+
+	s := &Session{WebDriver: &cdpWD{debuggerAddress: strings.TrimPrefix(srv.URL, "http://")}}
+	if _, err := s.ProcessStats(); err != nil {
+		t.Fatal(err)
+	}
+	if got := targets(); len(got) != 1 || got[0] != "/devtools/browser/b1" {
+		t.Errorf("SystemInfo.getProcessInfo sent to %v, want the browser target", got)
+	}
+}
+
+func TestRestartCDPRejected(t *testing.T) {
+	// A zero cdpWD would fail to quit; Restart must not get that far.
+	s := &Session{WebDriver: &cdpWD{}}
+	if err := s.Restart(); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("Restart of a cdp session returned %v, want not supported", err)
+	}
+}
@@ -0,0 +1,201 @@
+package webdriver
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// ErrProfileInUse is returned when a profile directory is locked by a running
+// browser.
+var ErrProfileInUse = errors.New("profile in use")
+
+// ProfileInUseError describes the browser holding a profile lock. It matches
+// ErrProfileInUse via its Cause method.
+type ProfileInUseError struct {
+	Path string
+	// Owner is the lock owner recorded by Chrome, typically "hostname-pid".
+	Owner string
+}
+
+func (e *ProfileInUseError) Error() string {
+	return fmt.Sprintf("%v: %s is locked by %s", ErrProfileInUse, e.Path, e.Owner)
+}
+
+// Cause returns ErrProfileInUse, for errors.Cause.
+func (e *ProfileInUseError) Cause() error {
+	return ErrProfileInUse
+}
+
+// lockFiles are the files Chrome creates in a user data directory while it
+// is running.
+var lockFiles = []string{"SingletonLock", "SingletonCookie", "SingletonSocket", "lockfile"}
+
+var (
+	pmu          sync.Mutex
+	tempProfiles = map[string]bool{}
+)
+
+// CreateTempProfile creates an empty profile directory that is deleted by
+// Shutdown.
+func CreateTempProfile() (string, error) {
+	path, err := ioutil.TempDir("", "webdriver-profile-")
+	if err != nil {
+		return "", err
+	}
+
+	pmu.Lock()
+	defer pmu.Unlock()
+	tempProfiles[path] = true
+	return path, nil
+}
+
+// CloneProfile copies the profile directory src to a new temporary profile
+// that is deleted by Shutdown, so that several sessions can start from the
+// same logged-in state. The source profile must not be in use.
+func CloneProfile(src string) (string, error) {
+	if err := LockCheck(src); err != nil {
+		return "", err
+	}
+
+	dst, err := CreateTempProfile()
+	if err != nil {
+		return "", err
+	}
+	if err := copyDir(src, dst); err != nil {
+		DeleteProfile(dst)
+		return "", err
+	}
+	return dst, nil
+}
+
+// DeleteProfile removes a profile directory, refusing to do so while a
+// browser uses it.
+func DeleteProfile(path string) error {
+	if err := LockCheck(path); err != nil {
+		return err
+	}
+
+	pmu.Lock()
+	delete(tempProfiles, path)
+	pmu.Unlock()
+
+	return os.RemoveAll(path)
+}
+
+// LockCheck returns a *ProfileInUseError if a running browser holds the lock
+// of the profile directory. Locks left behind by browsers that exited are
+// ignored, as Chrome takes them over.
+func LockCheck(path string) error {
+	lock := filepath.Join(path, "SingletonLock")
+	owner, err := os.Readlink(lock)
+	if err != nil {
+		if runtime.GOOS == "windows" {
+			// Chrome keeps "lockfile" open exclusively while running.
+			lockfile := filepath.Join(path, "lockfile")
+			f, err := os.OpenFile(lockfile, os.O_WRONLY, 0)
+			if err == nil {
+				f.Close()
+			} else if !os.IsNotExist(err) {
+				return &ProfileInUseError{Path: path, Owner: lockfile}
+			}
+		}
+		return nil
+	}
+
+	// The link target is "hostname-pid".
+	i := strings.LastIndexByte(owner, '-')
+	if i < 0 {
+		return &ProfileInUseError{Path: path, Owner: owner}
+	}
+	host, _ := os.Hostname()
+	if owner[:i] != host {
+		// The profile is on a shared filesystem and used by another host.
+		return &ProfileInUseError{Path: path, Owner: owner}
+	}
+	pid, err := strconv.Atoi(owner[i+1:])
+	if err != nil || processAlive(pid) {
+		return &ProfileInUseError{Path: path, Owner: owner}
+	}
+	return nil
+}
+
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || err == syscall.EPERM
+}
+
+// deleteTempProfiles removes the profiles created by CreateTempProfile and
+// CloneProfile.
+func deleteTempProfiles() {
+	pmu.Lock()
+	defer pmu.Unlock()
+	for path := range tempProfiles {
+		if err := os.RemoveAll(path); err != nil {
+			fmt.Printf("*** [webdriver] error removing temp profile %v: %v ***\n", path, err)
+		}
+		delete(tempProfiles, path)
+	}
+}
+
+// copyDir copies the regular files and directories below src to dst, skipping
+// the browser lock files.
+func copyDir(src, dst string) error {
+	skip := map[string]bool{}
+	for _, name := range lockFiles {
+		skip[name] = true
+	}
+
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if skip[info.Name()] {
+			return nil
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		case info.Mode().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		}
+		return nil
+	})
+}
+
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,95 @@
+package webdriver
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestCloneProfile(t *testing.T) {
+	src, err := ioutil.TempDir("", "webdriver-test-")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir() returned error: %v", err)
+	}
+	defer os.RemoveAll(src)
+
+	if err := os.MkdirAll(filepath.Join(src, "Default"), 0755); err != nil {
+		t.Fatalf("os.MkdirAll() returned error: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(src, "Default", "Cookies"), []byte("cookies"), 0644); err != nil {
+		t.Fatalf("ioutil.WriteFile() returned error: %v", err)
+	}
+
+	dst, err := CloneProfile(src)
+	if err != nil {
+		t.Fatalf("CloneProfile() returned error: %v", err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dst, "Default", "Cookies"))
+	if err != nil || string(data) != "cookies" {
+		t.Errorf("cloned Cookies = %q, %v, want %q", data, err, "cookies")
+	}
+
+	deleteTempProfiles()
+	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+		t.Errorf("temp profile %v still exists after deleteTempProfiles()", dst)
+	}
+}
+
+func TestLockCheck(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Chrome does not use SingletonLock on Windows")
+	}
+
+	dir, err := ioutil.TempDir("", "webdriver-test-")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir() returned error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := LockCheck(dir); err != nil {
+		t.Errorf("LockCheck() of unlocked profile returned error: %v", err)
+	}
+
+	host, _ := os.Hostname()
+	lock := filepath.Join(dir, "SingletonLock")
+	if err := os.Symlink(fmt.Sprintf("%s-%d", host, os.Getpid()), lock); err != nil {
+		t.Fatalf("os.Symlink() returned error: %v", err)
+	}
+	if err := LockCheck(dir); err == nil {
+		t.Errorf("LockCheck() of profile locked by a live process returned nil error")
+	}
+}
+
+func TestProfileStateRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "webdriver-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	state, err := LoadProfileState(dir)
+	if err != nil || len(state.Cookies) != 0 {
+		t.Fatalf("LoadProfileState of empty dir = %+v, %v", state, err)
+	}
+
+	want := &ProfileState{
+		Cookies: []ProfileCookie{{Name: "sid", Value: "1", Domain: ".example.com", Path: "/", Expires: -1, HTTPOnly: true}},
+		LocalStorage: map[string]map[string]string{
+			"https://example.com": {"token": "abc"},
+		},
+	}
+	if err := SaveProfileState(dir, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadProfileState(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadProfileState = %+v, want %+v", got, want)
+	}
+}
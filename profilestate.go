@@ -0,0 +1,173 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// ProfileStateFile is the file in a profile directory that SaveProfileState
+// writes and LoadProfileState reads.
+const ProfileStateFile = "webdriver-state.json"
+
+// ProfileState is the portable part of a browser profile: its cookies and
+// the localStorage of selected origins. It carries a logged-in state to
+// remote sessions, e.g. on Selenium Grid, where the path of a local profile
+// directory is meaningless and the directory cannot be uploaded.
+type ProfileState struct {
+	Cookies []ProfileCookie `json:"cookies"`
+	// LocalStorage maps origins, e.g. "https://example.com", to their items.
+	LocalStorage map[string]map[string]string `json:"localStorage,omitempty"`
+}
+
+// ProfileCookie is a cookie as stored by the DevTools protocol. Unlike
+// Cookie it includes HttpOnly cookies.
+type ProfileCookie struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Domain string `json:"domain"`
+	Path   string `json:"path"`
+	// Expires is in seconds since the epoch; it is negative for session
+	// cookies.
+	Expires  float64 `json:"expires"`
+	HTTPOnly bool    `json:"httpOnly"`
+	Secure   bool    `json:"secure"`
+	SameSite string  `json:"sameSite,omitempty"`
+}
+
+// ProfileState returns the cookies of the browser and the localStorage of
+// origins, which default to the origin of the current page.
+func (s *Session) ProfileState(origins ...string) (*ProfileState, error) {
+	cookies := struct {
+		Cookies []ProfileCookie `json:"cookies"`
+	}{}
+	if err := s.cdp("Network.getAllCookies", nil, &cookies); err != nil {
+		return nil, err
+	}
+	state := &ProfileState{Cookies: cookies.Cookies}
+
+	if len(origins) == 0 {
+		ret, err := s.ExecuteScript("return window.location.origin;", nil)
+		if err != nil {
+			return nil, err
+		}
+		// Pages such as about:blank have an opaque origin without storage.
+		if origin, ok := ret.(string); ok && origin != "null" {
+			origins = []string{origin}
+		}
+	}
+	if len(origins) == 0 {
+		return state, nil
+	}
+	if err := s.cdp("DOMStorage.enable", nil, nil); err != nil {
+		return nil, err
+	}
+	state.LocalStorage = map[string]map[string]string{}
+	for _, origin := range origins {
+		items := struct {
+			Entries [][]string `json:"entries"`
+		}{}
+		if err := s.cdp("DOMStorage.getDOMStorageItems", map[string]interface{}{
+			"storageId": map[string]interface{}{"securityOrigin": origin, "isLocalStorage": true},
+		}, &items); err != nil {
+			return nil, err
+		}
+		m := map[string]string{}
+		for _, e := range items.Entries {
+			if len(e) == 2 {
+				m[e[0]] = e[1]
+			}
+		}
+		state.LocalStorage[origin] = m
+	}
+	return state, nil
+}
+
+// SaveProfileState writes state to ProfileStateFile in the profile directory
+// dir, so that the directory can be used both by local sessions and, through
+// LoadProfileState and WithProfileState, by remote ones.
+func SaveProfileState(dir string, state *ProfileState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, ProfileStateFile), data, 0600)
+}
+
+// LoadProfileState reads the state saved in the profile directory dir by
+// SaveProfileState. It returns an empty state if there is none.
+func LoadProfileState(dir string) (*ProfileState, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, ProfileStateFile))
+	if os.IsNotExist(err) {
+		return &ProfileState{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	state := &ProfileState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("invalid profile state in %v: %v", dir, err)
+	}
+	return state, nil
+}
+
+// WithProfileState restores state into new sessions before their first
+// navigation: the cookies are set directly and the localStorage items are
+// seeded when a page of their origin loads, without overwriting existing
+// items.
+func WithProfileState(state *ProfileState) Option {
+	return func(c *sessionConfig) {
+		c.profileState = state
+	}
+}
+
+// restore applies the state to a newly created session.
+func (st *ProfileState) restore(wd WebDriver) error {
+	if len(st.Cookies) > 0 {
+		var cookies []map[string]interface{}
+		for _, c := range st.Cookies {
+			cookie := map[string]interface{}{
+				"name":     c.Name,
+				"value":    c.Value,
+				"domain":   c.Domain,
+				"path":     c.Path,
+				"httpOnly": c.HTTPOnly,
+				"secure":   c.Secure,
+			}
+			if c.Expires >= 0 {
+				cookie["expires"] = c.Expires
+			}
+			if c.SameSite != "" {
+				cookie["sameSite"] = c.SameSite
+			}
+			cookies = append(cookies, cookie)
+		}
+		if _, err := wd.ExecuteCDPRaw("Network.setCookies", map[string]interface{}{"cookies": cookies}); err != nil {
+			return err
+		}
+	}
+
+	if len(st.LocalStorage) == 0 {
+		return nil
+	}
+	items, err := json.Marshal(st.LocalStorage)
+	if err != nil {
+		return err
+	}
+	return addInitScript(wd, fmt.Sprintf(profileStorageScript, items))
+}
+
+const profileStorageScript = `(function() {
+	var items = %s[window.location.origin];
+	if (!items) {
+		return;
+	}
+	try {
+		for (var k in items) {
+			if (window.localStorage.getItem(k) === null) {
+				window.localStorage.setItem(k, items[k]);
+			}
+		}
+	} catch (e) {}
+})();`
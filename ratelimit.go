@@ -0,0 +1,142 @@
+package webdriver
+
+import (
+	"net/url"
+	"sync"
+	"time"
+)
+
+// RateLimit bounds how often and how concurrently requests are made.
+type RateLimit struct {
+	// PerMinute is the number of requests allowed to start per minute. The
+	// requests are spread evenly over the minute. Zero means unlimited.
+	PerMinute int
+	// Concurrency is the number of requests allowed in flight at once. Zero
+	// means unlimited.
+	Concurrency int
+}
+
+// limiter enforces a RateLimit.
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+	sem      chan struct{}
+}
+
+func newLimiter(l RateLimit) *limiter {
+	lim := &limiter{}
+	if l.PerMinute > 0 {
+		lim.interval = time.Minute / time.Duration(l.PerMinute)
+	}
+	if l.Concurrency > 0 {
+		lim.sem = make(chan struct{}, l.Concurrency)
+	}
+	return lim
+}
+
+// acquire blocks until a request may start and returns the function to call
+// once it is done. A nil limiter never blocks.
+func (l *limiter) acquire() func() {
+	if l == nil {
+		return func() {}
+	}
+
+	if l.sem != nil {
+		l.sem <- struct{}{}
+	}
+
+	if l.interval > 0 {
+		l.mu.Lock()
+		now := time.Now()
+		start := l.next
+		if start.Before(now) {
+			start = now
+		}
+		l.next = start.Add(l.interval)
+		l.mu.Unlock()
+		time.Sleep(start.Sub(now))
+	}
+
+	return func() {
+		if l.sem != nil {
+			<-l.sem
+		}
+	}
+}
+
+var (
+	lmu            sync.Mutex
+	globalLimiter  *limiter
+	hostLimiters   = map[string]*limiter{}
+	commandLimiter *limiter
+)
+
+// SetGlobalRateLimit limits the navigations of all sessions combined,
+// regardless of the target host. A zero RateLimit removes the limit.
+func SetGlobalRateLimit(l RateLimit) {
+	lmu.Lock()
+	defer lmu.Unlock()
+	globalLimiter = nil
+	if l != (RateLimit{}) {
+		globalLimiter = newLimiter(l)
+	}
+}
+
+// SetHostRateLimit limits the navigations of all sessions to the given host
+// name, e.g. "example.com". A zero RateLimit removes the limit.
+func SetHostRateLimit(host string, l RateLimit) {
+	lmu.Lock()
+	defer lmu.Unlock()
+	delete(hostLimiters, host)
+	if l != (RateLimit{}) {
+		hostLimiters[host] = newLimiter(l)
+	}
+}
+
+// SetCommandRateLimit limits every command sent to the WebDriver server, not
+// only navigations. A zero RateLimit removes the limit.
+func SetCommandRateLimit(l RateLimit) {
+	lmu.Lock()
+	defer lmu.Unlock()
+	commandLimiter = nil
+	if l != (RateLimit{}) {
+		commandLimiter = newLimiter(l)
+	}
+}
+
+// acquireNavigation waits for the global and the host rate limits of the URL.
+func acquireNavigation(rawURL string) func() {
+	var host string
+	if u, err := url.Parse(rawURL); err == nil {
+		host = u.Hostname()
+	}
+
+	lmu.Lock()
+	global, perHost := globalLimiter, hostLimiters[host]
+	lmu.Unlock()
+
+	releaseHost := perHost.acquire()
+	releaseGlobal := global.acquire()
+	return func() {
+		releaseGlobal()
+		releaseHost()
+	}
+}
+
+func acquireCommand() func() {
+	lmu.Lock()
+	l := commandLimiter
+	lmu.Unlock()
+	return l.acquire()
+}
+
+// Get navigates the browser to the provided URL, respecting the rate limits
+// configured with SetGlobalRateLimit and SetHostRateLimit and the navigation
+// timeout of WithNavigationTimeout.
+func (s *Session) Get(url string) error {
+	release := acquireNavigation(url)
+	defer release()
+
+	return s.fail("Get", url, s.navigate(url, s.navStop))
+}
@@ -0,0 +1,85 @@
+package webdriver
+
+import (
+	"sync"
+	"time"
+)
+
+// LastActivity returns the time the session last sent a command to the
+// driver, or its creation time if it has not sent any since.
+func (s *Session) LastActivity() time.Time {
+	last := s.createdAt
+	if wd, ok := s.WebDriver.(activityTracker); ok && wd.lastActivity().After(last) {
+		last = wd.lastActivity()
+	}
+	return last
+}
+
+// activityTracker is implemented by the backends recording when they last
+// sent a command.
+type activityTracker interface {
+	lastActivity() time.Time
+}
+
+// ReaperConfig configures StartReaper.
+type ReaperConfig struct {
+	// MaxIdle is the duration after which a session without activity is
+	// closed.
+	MaxIdle time.Duration
+	// Interval is the period between checks. It defaults to a quarter of
+	// MaxIdle, but at least one second.
+	Interval time.Duration
+	// OnReap, if set, is called right before an idle session is closed.
+	OnReap func(s *Session, idle time.Duration)
+}
+
+// StartReaper starts a goroutine that closes the sessions that have been idle
+// for longer than cfg.MaxIdle, which keeps forgotten sessions from
+// accumulating browser processes in long-lived programs. The returned function
+// stops the reaper.
+func StartReaper(cfg ReaperConfig) (stop func()) {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = cfg.MaxIdle / 4
+		if interval < time.Second {
+			interval = time.Second
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				reapIdle(cfg)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+func reapIdle(cfg ReaperConfig) {
+	smu.Lock()
+	live := append([]*Session(nil), sessions...)
+	smu.Unlock()
+
+	for _, s := range live {
+		idle := time.Since(s.LastActivity())
+		if idle <= cfg.MaxIdle {
+			continue
+		}
+		if cfg.OnReap != nil {
+			cfg.OnReap(s, idle)
+		}
+		debugLog("reaping session %v idle for %v", s.SessionID(), idle)
+		s.Close()
+	}
+}
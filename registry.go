@@ -0,0 +1,84 @@
+package webdriver
+
+import (
+	"sort"
+	"time"
+)
+
+// SessionInfo describes a session created by New and not yet closed.
+type SessionInfo struct {
+	ID      string
+	Label   string
+	Profile string
+	Created time.Time
+	// URL is the current URL of the session, or empty if it could not be
+	// queried.
+	URL string
+	// CloudURL is the dashboard page of a cloud session, see
+	// Session.CloudURL.
+	CloudURL string
+	// Endpoint is the driver endpoint serving the session when Init was
+	// called with WithShards.
+	Endpoint string
+}
+
+// SetLabel attaches a free-form label to the session, e.g. the name of the job
+// using it, for Sessions and LookupSession.
+func (s *Session) SetLabel(label string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.label = label
+}
+
+// Label returns the label set by SetLabel.
+func (s *Session) Label() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.label
+}
+
+// Sessions describes the live sessions, in creation order. The current URL of
+// each session is queried from the driver.
+func Sessions() []SessionInfo {
+	smu.Lock()
+	live := append([]*Session(nil), sessions...)
+	smu.Unlock()
+
+	infos := make([]SessionInfo, len(live))
+	for i, s := range live {
+		infos[i] = SessionInfo{
+			ID:      s.SessionID(),
+			Label:   s.Label(),
+			Profile: s.profile,
+			Created: s.createdAt,
+
+			CloudURL: s.cloudURL,
+		}
+		if s.shard != nil {
+			infos[i].Endpoint = s.shard.URL
+		}
+		if u, err := s.CurrentURL(); err == nil {
+			infos[i].URL = u
+		}
+	}
+	// Close reorders the registry, so restore the creation order.
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Created.Before(infos[j].Created)
+	})
+	return infos
+}
+
+// LookupSession returns the oldest live session with the given label, or nil
+// if there is none.
+func LookupSession(label string) *Session {
+	smu.Lock()
+	defer smu.Unlock()
+
+	var found *Session
+	for _, s := range sessions {
+		if s.Label() == label && (found == nil || s.createdAt.Before(found.createdAt)) {
+			found = s
+		}
+	}
+	return found
+}
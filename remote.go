@@ -15,6 +15,8 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/blang/semver"
@@ -48,7 +50,7 @@ func debugLog(format string, args ...interface{}) {
 	if !debugFlag {
 		return
 	}
-	fmt.Printf(format+"\n", args...)
+	currentLogger().Printf(format, args...)
 }
 
 // filteredURL replaces existing password from the given URL.
@@ -67,12 +69,30 @@ func filteredURL(u string) string {
 }
 
 type remoteWD struct {
+	// lastActive is the time of the last command in Unix nanoseconds. It is
+	// accessed atomically and kept first for 64-bit alignment.
+	lastActive int64
+
 	id, urlPrefix string
 	capabilities  Capabilities
 
 	w3cCompatible  bool
 	browser        string
 	browserVersion semver.Version
+
+	// hookSet holds the command hooks added with Session.Use.
+	hookSet
+
+	// gate is held for reading by commands and for writing by restart, so
+	// that commands wait for a restart in progress. live holds the
+	// restartedSession replacing id: commands keep addressing id, which is
+	// rewritten to the new session.
+	gate sync.RWMutex
+	live atomic.Value
+}
+
+type restartedSession struct {
+	from, to string
 }
 
 // HTTPClient is the default client to use to communicate with the WebDriver
@@ -144,20 +164,81 @@ func (e *Error) Error() string {
 // encoded by the remote end in a JSON structure. If no error is present, the
 // entire, raw request payload is returned.
 func (wd *remoteWD) execute(method, url string, data []byte) (json.RawMessage, error) {
-	return executeCommand(method, url, data)
+	atomic.StoreInt64(&wd.lastActive, time.Now().UnixNano())
+
+	wd.gate.RLock()
+	defer wd.gate.RUnlock()
+	if live := wd.liveID(); live != wd.id {
+		old := "/session/" + wd.id
+		if i := strings.Index(url, old); i >= 0 && (len(url) == i+len(old) || url[i+len(old)] == '/') {
+			url = url[:i] + "/session/" + live + url[i+len(old):]
+		}
+	}
+	return wd.executeHooked(method, url, data)
+}
+
+// liveID returns the id of the session in the driver, see remoteWD.live.
+func (wd *remoteWD) liveID() string {
+	if r, ok := wd.live.Load().(restartedSession); ok && r.from == wd.id && wd.id != "" {
+		return r.to
+	}
+	return wd.id
+}
+
+// restart replaces the session with a new one with the same capabilities,
+// set up by setup before the commands waiting for the restart resume.
+func (wd *remoteWD) restart(setup func(WebDriver) error) error {
+	wd.gate.Lock()
+	defer wd.gate.Unlock()
+
+	if wd.id == "" {
+		return fmt.Errorf("session already quit")
+	}
+	// The restart runs its commands on a separate driver, which does not
+	// wait for the gate.
+	if err := DeleteSession(wd.urlPrefix, wd.liveID()); err != nil {
+		debugLog("error quitting session %v for restart: %v", wd.liveID(), err)
+	}
+	nw := &remoteWD{urlPrefix: wd.urlPrefix, capabilities: wd.capabilities, browser: wd.browser}
+	if _, err := nw.NewSession(); err != nil {
+		return err
+	}
+	if setup != nil {
+		if err := setup(nw); err != nil {
+			nw.Quit()
+			return err
+		}
+	}
+	wd.live.Store(restartedSession{from: wd.id, to: nw.id})
+	return nil
+}
+
+// lastActivity returns the time the last command was sent, or the zero time if
+// none was.
+func (wd *remoteWD) lastActivity() time.Time {
+	if ns := atomic.LoadInt64(&wd.lastActive); ns != 0 {
+		return time.Unix(0, ns)
+	}
+	return time.Time{}
 }
 
 func executeCommand(method, url string, data []byte) (json.RawMessage, error) {
+	release := acquireCommand()
+	defer release()
+
 	debugLog("-> %s %s\n%s", method, filteredURL(url), data)
 	request, err := newRequest(method, url, data)
 	if err != nil {
 		return nil, err
 	}
 
+	start := time.Now()
 	response, err := HTTPClient.Do(request)
 	if err != nil {
+		logWire(method, url, data, err.Error(), start)
 		return nil, err
 	}
+	logWire(method, url, data, response.Status, start)
 
 	buf, err := ioutil.ReadAll(response.Body)
 	if debugFlag {
@@ -186,7 +267,7 @@ func executeCommand(method, url string, data []byte) (json.RawMessage, error) {
 	reply := new(serverReply)
 	if err := json.Unmarshal(buf, reply); err != nil {
 		if response.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("bad server reply status: %s", response.Status)
+			return nil, &statusError{response.StatusCode, response.Status}
 		}
 		return nil, err
 	}
@@ -229,6 +310,17 @@ func executeCommand(method, url string, data []byte) (json.RawMessage, error) {
 	return buf, nil
 }
 
+// statusError is returned when the server fails with a reply that does not
+// describe the error.
+type statusError struct {
+	code   int
+	status string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("bad server reply status: %s", e.status)
+}
+
 // DefaultURLPrefix is the default HTTP endpoint that offers the WebDriver API.
 const DefaultURLPrefix = "http://127.0.0.1:4444/wd/hub"
 
@@ -237,7 +329,8 @@ const DefaultURLPrefix = "http://127.0.0.1:4444/wd/hub"
 // Selenium server, must be prefixed with protocol (http, https, ...).
 //
 // Providing an empty string for urlPrefix causes the DefaultURLPrefix to be
-// used.
+// used. For https:// endpoints with a private CA or client certificates, set
+// HTTPClient to a client returned by NewHTTPClient with TLS options first.
 func NewRemote(capabilities Capabilities, urlPrefix string) (WebDriver, error) {
 	if urlPrefix == "" {
 		urlPrefix = DefaultURLPrefix
@@ -299,10 +392,18 @@ func voidCommand(method, url string, params interface{}) error {
 }
 
 func (wd *remoteWD) voidCommand(urlTemplate string, params interface{}) error {
-	return voidCommand("POST", wd.requestURL(urlTemplate, wd.id), params)
+	if params == nil {
+		params = make(map[string]interface{})
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	_, err = wd.execute("POST", wd.requestURL(urlTemplate, wd.id), data)
+	return err
 }
 
-func (wd remoteWD) stringsCommand(urlTemplate string) ([]string, error) {
+func (wd *remoteWD) stringsCommand(urlTemplate string) ([]string, error) {
 	url := wd.requestURL(urlTemplate, wd.id)
 	response, err := wd.execute("GET", url, nil)
 	if err != nil {
@@ -532,7 +633,7 @@ func (wd *remoteWD) SessionId() string {
 
 // SessionID returns the current session ID
 func (wd *remoteWD) SessionID() string {
-	return wd.id
+	return wd.liveID()
 }
 
 func (wd *remoteWD) SwitchSession(sessionID string) error {
@@ -1177,6 +1278,44 @@ func (wd *remoteWD) ExecuteScriptAsyncRaw(script string, args []interface{}) ([]
 	return wd.execScriptRaw(script, args, "/async")
 }
 
+func (wd *remoteWD) ExecuteCDPRaw(cmd string, params map[string]interface{}) ([]byte, error) {
+	if params == nil {
+		params = make(map[string]interface{})
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"cmd":    cmd,
+		"params": params,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return wd.execute("POST", wd.requestURL("/session/%s/"+wd.cdpVendor()+"/cdp/execute", wd.id), data)
+}
+
+// cdpVendor returns the vendor prefix of the driver's CDP endpoint.
+func (wd *remoteWD) cdpVendor() string {
+	if wd.browser == "MicrosoftEdge" {
+		return "ms"
+	}
+	return "goog"
+}
+
+func (wd *remoteWD) ExecuteCDP(cmd string, params map[string]interface{}) (interface{}, error) {
+	response, err := wd.ExecuteCDPRaw(cmd, params)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := new(struct{ Value interface{} })
+	if err = json.Unmarshal(response, reply); err != nil {
+		return nil, err
+	}
+
+	return reply.Value, nil
+}
+
 func (wd *remoteWD) Screenshot() ([]byte, error) {
 	data, err := wd.stringCommand("/session/%s/screenshot")
 	if err != nil {
@@ -0,0 +1,257 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestInfo describes a completed request of the page, see
+// WaitForRequest.
+type RequestInfo struct {
+	URL    string
+	Method string
+	// Type is the resource type, e.g. "Document", "XHR" or "Fetch".
+	Type            string
+	RequestHeaders  map[string]string
+	Status          int
+	StatusText      string
+	ResponseHeaders map[string]string
+	MimeType        string
+	// Size is the number of bytes received, headers included.
+	Size int64
+	// ErrorText is the network error of a failed request, e.g.
+	// "net::ERR_CONNECTION_REFUSED". Status is zero for failed requests.
+	ErrorText string
+	Start     time.Time
+	Duration  time.Duration
+}
+
+// maxTrackedRequests bounds the completed requests kept for WaitForRequest.
+const maxTrackedRequests = 1000
+
+// requestTracker records the requests of a target from its Network events.
+type requestTracker struct {
+	mu       sync.Mutex
+	inflight map[string]*RequestInfo
+	started  map[string]float64
+	done     []*RequestInfo
+	changed  chan struct{}
+}
+
+// TrackRequests starts recording the requests of the current window, for
+// WaitForRequest. Requests are only recorded once tracking started, so call
+// it before triggering the requests to wait for. It requires a local
+// Chromium-based browser.
+func (s *Session) TrackRequests() error {
+	_, err := s.requestTracker()
+	return err
+}
+
+func (s *Session) requestTracker() (*requestTracker, error) {
+	conn, err := s.pageConn()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	t := s.trackers[conn]
+	if t != nil {
+		s.mu.Unlock()
+		return t, nil
+	}
+	t = &requestTracker{
+		inflight: map[string]*RequestInfo{},
+		started:  map[string]float64{},
+		changed:  make(chan struct{}),
+	}
+	if s.trackers == nil {
+		s.trackers = map[*cdpConn]*requestTracker{}
+	}
+	s.trackers[conn] = t
+	s.mu.Unlock()
+
+	conn.on("Network.requestWillBeSent", t.requestWillBeSent)
+	conn.on("Network.responseReceived", t.responseReceived)
+	conn.on("Network.loadingFinished", t.loadingFinished)
+	conn.on("Network.loadingFailed", t.loadingFailed)
+	if _, err := conn.call("Network.enable", nil); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+type cdpResponse struct {
+	Status     int               `json:"status"`
+	StatusText string            `json:"statusText"`
+	Headers    map[string]string `json:"headers"`
+	MimeType   string            `json:"mimeType"`
+}
+
+func (r *RequestInfo) setResponse(resp *cdpResponse) {
+	r.Status = resp.Status
+	r.StatusText = resp.StatusText
+	r.ResponseHeaders = resp.Headers
+	r.MimeType = resp.MimeType
+}
+
+func (t *requestTracker) requestWillBeSent(params json.RawMessage) {
+	ev := struct {
+		RequestID string `json:"requestId"`
+		Request   struct {
+			URL     string            `json:"url"`
+			Method  string            `json:"method"`
+			Headers map[string]string `json:"headers"`
+		} `json:"request"`
+		Type             string       `json:"type"`
+		Timestamp        float64      `json:"timestamp"`
+		WallTime         float64      `json:"wallTime"`
+		RedirectResponse *cdpResponse `json:"redirectResponse"`
+	}{}
+	if err := json.Unmarshal(params, &ev); err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// A redirect reuses the id of the request it completes.
+	if prev := t.inflight[ev.RequestID]; prev != nil && ev.RedirectResponse != nil {
+		prev.setResponse(ev.RedirectResponse)
+		t.complete(ev.RequestID, ev.Timestamp)
+	}
+	t.inflight[ev.RequestID] = &RequestInfo{
+		URL:            ev.Request.URL,
+		Method:         ev.Request.Method,
+		Type:           ev.Type,
+		RequestHeaders: ev.Request.Headers,
+		Start:          time.Unix(0, int64(ev.WallTime*float64(time.Second))),
+	}
+	t.started[ev.RequestID] = ev.Timestamp
+}
+
+func (t *requestTracker) responseReceived(params json.RawMessage) {
+	ev := struct {
+		RequestID string      `json:"requestId"`
+		Response  cdpResponse `json:"response"`
+	}{}
+	if err := json.Unmarshal(params, &ev); err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if r := t.inflight[ev.RequestID]; r != nil {
+		r.setResponse(&ev.Response)
+	}
+}
+
+func (t *requestTracker) loadingFinished(params json.RawMessage) {
+	ev := struct {
+		RequestID         string  `json:"requestId"`
+		Timestamp         float64 `json:"timestamp"`
+		EncodedDataLength float64 `json:"encodedDataLength"`
+	}{}
+	if err := json.Unmarshal(params, &ev); err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if r := t.inflight[ev.RequestID]; r != nil {
+		r.Size = int64(ev.EncodedDataLength)
+	}
+	t.complete(ev.RequestID, ev.Timestamp)
+}
+
+func (t *requestTracker) loadingFailed(params json.RawMessage) {
+	ev := struct {
+		RequestID string  `json:"requestId"`
+		Timestamp float64 `json:"timestamp"`
+		ErrorText string  `json:"errorText"`
+	}{}
+	if err := json.Unmarshal(params, &ev); err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if r := t.inflight[ev.RequestID]; r != nil {
+		r.ErrorText = ev.ErrorText
+	}
+	t.complete(ev.RequestID, ev.Timestamp)
+}
+
+// complete moves a request to the completed ones. It is called with t.mu
+// held.
+func (t *requestTracker) complete(id string, timestamp float64) {
+	r := t.inflight[id]
+	if r == nil {
+		return
+	}
+	r.Duration = time.Duration((timestamp - t.started[id]) * float64(time.Second))
+	delete(t.inflight, id)
+	delete(t.started, id)
+
+	t.done = append(t.done, r)
+	if len(t.done) > maxTrackedRequests {
+		t.done = t.done[len(t.done)-maxTrackedRequests:]
+	}
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// requestMatcher parses a WaitForRequest pattern.
+func requestMatcher(pattern string) func(*RequestInfo) bool {
+	method := ""
+	if i := strings.IndexByte(pattern, ' '); i > 0 && strings.ToUpper(pattern[:i]) == pattern[:i] {
+		method, pattern = pattern[:i], strings.TrimSpace(pattern[i+1:])
+	}
+	var re *regexp.Regexp
+	if pattern != "" {
+		re = globRegexp(pattern)
+	}
+	return func(r *RequestInfo) bool {
+		return (method == "" || r.Method == method) && (re == nil || re.MatchString(r.URL))
+	}
+}
+
+// WaitForRequest waits up to timeout for a request of the current window
+// matching urlPattern to complete, and returns it. The pattern is matched
+// against the whole URL, * matching any sequence of characters and ? any
+// single character, and may be prefixed with a method, e.g.
+// "POST */api/submit". Each completed request is returned once, the oldest
+// first, so that successive calls see successive requests.
+//
+// Requests are recorded from the first call or from TrackRequests, which
+// must come first if the request may complete before WaitForRequest is
+// called.
+func (s *Session) WaitForRequest(urlPattern string, timeout time.Duration) (*RequestInfo, error) {
+	t, err := s.requestTracker()
+	if err != nil {
+		return nil, err
+	}
+	match := requestMatcher(urlPattern)
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for polls := 1; ; polls++ {
+		t.mu.Lock()
+		for i, r := range t.done {
+			if match(r) {
+				t.done = append(t.done[:i], t.done[i+1:]...)
+				t.mu.Unlock()
+				return r, nil
+			}
+		}
+		changed := t.changed
+		t.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-deadline.C:
+			return nil, s.fail("WaitForRequest", urlPattern, &TimeoutError{Elapsed: timeout, Polls: polls})
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package webdriver
+
+import "testing"
+
+func TestRequestMatcher(t *testing.T) {
+	post := &RequestInfo{Method: "POST", URL: "https://example.com/api/submit"}
+	get := &RequestInfo{Method: "GET", URL: "https://example.com/api/submit?id=1"}
+
+	tests := []struct {
+		pattern string
+		r       *RequestInfo
+		match   bool
+	}{
+		{"*/api/submit", post, true},
+		{"POST */api/submit", post, true},
+		{"POST */api/submit*", get, false},
+		{"GET */api/submit*", get, true},
+		{"*/api/submit", get, false},
+		{"", get, true},
+	}
+	for _, test := range tests {
+		if got := requestMatcher(test.pattern)(test.r); got != test.match {
+			t.Errorf("requestMatcher(%q)(%v %v) = %v, want %v", test.pattern, test.r.Method, test.r.URL, got, test.match)
+		}
+	}
+}
+
+func TestRequestTracker(t *testing.T) {
+	tr := &requestTracker{inflight: map[string]*RequestInfo{}, started: map[string]float64{}, changed: make(chan struct{})}
+	changed := tr.changed
+	tr.requestWillBeSent([]byte(`{"requestId": "1", "request": {"url": "https://example.com/a", "method": "GET"}, "type": "XHR", "timestamp": 10, "wallTime": 1600000000}`))
+	tr.requestWillBeSent([]byte(`{"requestId": "1", "request": {"url": "https://example.com/b", "method": "GET"}, "timestamp": 10.5, "redirectResponse": {"status": 302}}`))
+	tr.responseReceived([]byte(`{"requestId": "1", "response": {"status": 200, "mimeType": "application/json"}}`))
+	tr.loadingFinished([]byte(`{"requestId": "1", "timestamp": 11, "encodedDataLength": 512}`))
+
+	select {
+	case <-changed:
+	default:
+		t.Error("completion not signaled")
+	}
+	if len(tr.done) != 2 {
+		t.Fatalf("%d requests completed, want 2", len(tr.done))
+	}
+	if r := tr.done[0]; r.URL != "https://example.com/a" || r.Status != 302 {
+		t.Errorf("redirect = %v %v", r.URL, r.Status)
+	}
+	if r := tr.done[1]; r.URL != "https://example.com/b" || r.Status != 200 || r.Size != 512 || r.Duration.Seconds() != 0.5 {
+		t.Errorf("request = %+v", r)
+	}
+}
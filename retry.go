@@ -0,0 +1,108 @@
+package webdriver
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// CreateRetry configures WithCreateRetry.
+type CreateRetry struct {
+	// Attempts is the maximum number of attempts to create the session. It
+	// defaults to 3.
+	Attempts int
+	// Backoff is the delay before the first retry, doubled after each
+	// further attempt. It defaults to one second.
+	Backoff time.Duration
+	// MaxBackoff caps the delay between attempts. It defaults to 30 seconds.
+	MaxBackoff time.Duration
+}
+
+// WithCreateRetry makes New retry the creation of the session with
+// exponential backoff when it fails transiently, e.g. with "session not
+// created" right after the driver started or while the host is loaded.
+// Errors caused by the capabilities, such as an unsupported browser version
+// or an invalid argument, fail immediately.
+func WithCreateRetry(retry CreateRetry) Option {
+	return func(c *sessionConfig) {
+		c.retry = &retry
+	}
+}
+
+// do runs create until it succeeds, fails permanently or the attempts are
+// exhausted. A nil retry runs create once.
+func (r *CreateRetry) do(create func() error) error {
+	if r == nil {
+		return create()
+	}
+	attempts := r.Attempts
+	if attempts <= 0 {
+		attempts = 3
+	}
+	backoff := r.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	max := r.MaxBackoff
+	if max <= 0 {
+		max = 30 * time.Second
+	}
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			debugLog("retrying session creation in %v: %v", backoff, err)
+			time.Sleep(backoff)
+			if backoff *= 2; backoff > max {
+				backoff = max
+			}
+		}
+		if err = create(); err == nil || !retryableCreate(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// Messages of "session not created" errors caused by the capabilities rather
+// than by a transient condition.
+var permanentCreateMessages = []string{
+	"only supports",
+	"cannot find",
+	"no such file",
+	"unrecognized",
+	"invalid argument",
+	"no matching capabilities",
+	"unable to find",
+}
+
+// retryableCreate reports whether a session creation error is transient.
+func retryableCreate(err error) bool {
+	switch e := errors.Cause(err).(type) {
+	case nil:
+		return false
+	case *Error:
+		if e.Err == "invalid argument" {
+			return false
+		}
+		if e.Err != "session not created" && e.Err != "unknown error" && e.LegacyCode != 33 && e.LegacyCode != 13 {
+			return false
+		}
+		msg := strings.ToLower(e.Message)
+		for _, m := range permanentCreateMessages {
+			if strings.Contains(msg, m) {
+				return false
+			}
+		}
+		return true
+	case *statusError:
+		return e.code >= 500
+	case net.Error:
+		return true
+	default:
+		// The driver may not accept connections yet.
+		return IsSessionDead(err)
+	}
+}
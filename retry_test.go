@@ -0,0 +1,52 @@
+package webdriver
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRetryableCreate(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{&Error{Err: "session not created", Message: "session not created: Chrome failed to start: exited abnormally. DevToolsActivePort file doesn't exist"}, true},
+		{&Error{Err: "session not created", Message: "session not created: This version of ChromeDriver only supports Chrome version 114"}, false},
+		{&Error{Err: "session not created", Message: "session not created: cannot find Chrome binary"}, false},
+		{&Error{Err: "invalid argument", Message: "invalid argument: unrecognized capability: foo"}, false},
+		{&Error{Err: "no such element"}, false},
+		{&statusError{503, "503 Service Unavailable"}, true},
+		{&statusError{400, "400 Bad Request"}, false},
+		{&url.Error{Op: "Post", URL: "http://localhost:9090", Err: &net.OpError{Op: "dial", Err: fmt.Errorf("connection refused")}}, true},
+		{fmt.Errorf("bad config"), false},
+	} {
+		if got := retryableCreate(tc.err); got != tc.want {
+			t.Errorf("retryableCreate(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestCreateRetry(t *testing.T) {
+	r := &CreateRetry{Attempts: 3, Backoff: time.Millisecond}
+	calls := 0
+	transient := &Error{Err: "session not created", Message: "DevToolsActivePort file doesn't exist"}
+	err := r.do(func() error {
+		if calls++; calls < 3 {
+			return transient
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("do = %v after %d calls, want success after 3", err, calls)
+	}
+
+	calls = 0
+	permanent := &Error{Err: "invalid argument"}
+	if err := r.do(func() error { calls++; return permanent }); err != permanent || calls != 1 {
+		t.Errorf("do = %v after %d calls, want %v after 1", err, calls, permanent)
+	}
+}
@@ -0,0 +1,37 @@
+package webdriver
+
+import "time"
+
+// WaitForRouteChange waits up to timeout for the URL of the page to differ
+// from fromURL and returns the new URL. It detects the client-side
+// navigations of single page applications, through history.pushState,
+// replaceState or the fragment, as well as full page loads, so that a click
+// on a client-side link can be awaited without guessing at element markers.
+// An empty fromURL means the URL at the time of the call, which is only
+// useful when the navigation is triggered concurrently.
+func (s *Session) WaitForRouteChange(fromURL string, timeout time.Duration) (string, error) {
+	if fromURL == "" {
+		u, err := s.CurrentURL()
+		if err != nil {
+			return "", err
+		}
+		fromURL = u
+	}
+
+	var to string
+	err := s.waitOn(func() (bool, error) {
+		u, err := s.CurrentURL()
+		if IsRetryable(err) {
+			return false, retryWith(err)
+		} else if err != nil {
+			return true, err
+		}
+		if u == fromURL {
+			return false, nil
+		}
+		to = u
+		return true, nil
+	}, timeout)
+
+	return to, s.fail("WaitForRouteChange", fromURL, err)
+}
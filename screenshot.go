@@ -0,0 +1,217 @@
+package webdriver
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"math"
+)
+
+// ImageFormat is the encoding of a captured screenshot.
+type ImageFormat string
+
+// The supported screenshot encodings.
+const (
+	PNG  ImageFormat = "png"
+	JPEG ImageFormat = "jpeg"
+	WebP ImageFormat = "webp"
+)
+
+// ScreenshotOptions configures Session.ScreenshotWith.
+type ScreenshotOptions struct {
+	// Format is the image encoding. It defaults to PNG.
+	Format ImageFormat
+	// Quality, if set, is the compression quality in the range [0, 100]. It
+	// is ignored for PNG and defaults to DefaultScreenshotQuality.
+	Quality *int
+	// FullPage captures the whole scrollable page rather than the viewport.
+	FullPage bool
+}
+
+// DefaultScreenshotQuality is the quality used for lossy formats when
+// ScreenshotOptions.Quality is left unset.
+const DefaultScreenshotQuality = 80
+
+// ScreenshotWith takes a screenshot of the browser window encoded as
+// configured by opts. Unlike Screenshot, which always returns a PNG, it is
+// captured through Page.captureScreenshot and thus requires a Chromium-based
+// browser.
+func (s *Session) ScreenshotWith(opts ScreenshotOptions) ([]byte, error) {
+	params, err := opts.params()
+	if err != nil {
+		return nil, err
+	}
+	if opts.FullPage {
+		// Capturing beyond the viewport still captures the viewport size
+		// unless clipped to the size of the page.
+		metrics := struct {
+			ContentSize, CSSContentSize struct {
+				Width, Height float64
+			}
+		}{}
+		if err := s.cdp("Page.getLayoutMetrics", nil, &metrics); err != nil {
+			return nil, err
+		}
+		size := metrics.CSSContentSize
+		if size.Width == 0 || size.Height == 0 {
+			// Before Chrome 92, contentSize was in CSS pixels.
+			size = metrics.ContentSize
+		}
+		params["clip"] = map[string]interface{}{
+			"x":      0,
+			"y":      0,
+			"width":  size.Width,
+			"height": size.Height,
+			"scale":  1,
+		}
+	}
+
+	var reply struct {
+		Data string `json:"data"`
+	}
+	if err := s.cdp("Page.captureScreenshot", params, &reply); err != nil {
+		return nil, err
+	}
+
+	return base64.StdEncoding.DecodeString(reply.Data)
+}
+
+func (o ScreenshotOptions) params() (map[string]interface{}, error) {
+	format := o.Format
+	if format == "" {
+		format = PNG
+	}
+
+	params := map[string]interface{}{
+		"format": string(format),
+	}
+	switch format {
+	case PNG:
+	case JPEG, WebP:
+		quality := DefaultScreenshotQuality
+		if o.Quality != nil {
+			quality = *o.Quality
+		}
+		if quality < 0 || quality > 100 {
+			return nil, fmt.Errorf("screenshot quality out of range [0, 100]: %v", quality)
+		}
+		params["quality"] = quality
+	default:
+		return nil, fmt.Errorf("unsupported screenshot format %q", format)
+	}
+	if o.FullPage {
+		params["captureBeyondViewport"] = true
+	}
+
+	return params, nil
+}
+
+// ScreenshotRegion takes a PNG screenshot of the given region of the viewport.
+// The region is specified in CSS pixels and is scaled by the device pixel
+// ratio before cropping, so it matches what getBoundingClientRect reports.
+// Parts of the region outside of the viewport are clipped.
+func (s *Session) ScreenshotRegion(x, y, w, h int) ([]byte, error) {
+	if w <= 0 || h <= 0 {
+		return nil, fmt.Errorf("invalid screenshot region size %vx%v", w, h)
+	}
+
+	ratio, err := s.devicePixelRatio()
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := s.Screenshot()
+	if err != nil {
+		return nil, err
+	}
+
+	return cropPNG(img, x, y, w, h, ratio)
+}
+
+func (s *Session) devicePixelRatio() (float64, error) {
+	ret, err := s.ExecuteScript("return window.devicePixelRatio;", nil)
+	if err != nil {
+		return 0, err
+	}
+	if ratio, ok := ret.(float64); ok && ratio > 0 {
+		return ratio, nil
+	}
+	return 1, nil
+}
+
+// boundingBoxScript returns the bounding box of the element in arguments[0] in
+// the view of the top-level document, offset by the boxes of the frames
+// containing it as far as they are accessible.
+const boundingBoxScript = `var r = arguments[0].getBoundingClientRect();
+var x = 0, y = 0;
+try {
+	for (var w = window; w.frameElement; w = w.parent) {
+		var f = w.frameElement, fr = f.getBoundingClientRect();
+		x += fr.left + f.clientLeft;
+		y += fr.top + f.clientTop;
+	}
+} catch (e) {
+	// Cross-origin frames hide their elements.
+}
+return [r.left + x, r.top + y, r.right + x, r.bottom + y];`
+
+// ScreenshotPadded scrolls the element into view and takes a PNG screenshot
+// of its bounding box, extended by margin CSS pixels on each side.
+func (e *Element) ScreenshotPadded(margin int) ([]byte, error) {
+	if err := e.ScrollIntoView(); err != nil {
+		return nil, err
+	}
+
+	var ret interface{}
+	err := e.inFrame(func() (err error) {
+		ret, err = e.s.ExecuteScript(boundingBoxScript, []interface{}{e.WebElement})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	vals, ok := ret.([]interface{})
+	if !ok || len(vals) != 4 {
+		return nil, fmt.Errorf("unexpected bounding box %v", ret)
+	}
+	box := make([]float64, len(vals))
+	for i, v := range vals {
+		if box[i], ok = v.(float64); !ok {
+			return nil, fmt.Errorf("unexpected bounding box %v", ret)
+		}
+	}
+
+	x0, y0 := int(math.Floor(box[0]))-margin, int(math.Floor(box[1]))-margin
+	x1, y1 := int(math.Ceil(box[2]))+margin, int(math.Ceil(box[3]))+margin
+	return e.s.ScreenshotRegion(x0, y0, x1-x0, y1-y0)
+}
+
+// cropPNG crops the region, given in CSS pixels, out of a PNG image captured
+// at the given device pixel ratio.
+func cropPNG(data []byte, x, y, w, h int, ratio float64) ([]byte, error) {
+	src, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	scale := func(v int) int {
+		return int(math.Round(float64(v) * ratio))
+	}
+	b := src.Bounds()
+	r := image.Rect(scale(x), scale(y), scale(x+w), scale(y+h)).Add(b.Min).Intersect(b)
+	if r.Empty() {
+		return nil, fmt.Errorf("screenshot region (%v, %v, %v, %v) is outside of the viewport", x, y, w, h)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, r.Min, draw.Src)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,76 @@
+package webdriver
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"reflect"
+	"testing"
+)
+
+func TestScreenshotOptionsParams(t *testing.T) {
+	quality := func(q int) *int { return &q }
+	for _, tc := range []struct {
+		opts ScreenshotOptions
+		want map[string]interface{}
+	}{
+		{ScreenshotOptions{}, map[string]interface{}{"format": "png"}},
+		{ScreenshotOptions{Format: PNG, Quality: quality(10)}, map[string]interface{}{"format": "png"}},
+		{ScreenshotOptions{Format: JPEG}, map[string]interface{}{"format": "jpeg", "quality": DefaultScreenshotQuality}},
+		{ScreenshotOptions{Format: JPEG, Quality: quality(0)}, map[string]interface{}{"format": "jpeg", "quality": 0}},
+		{ScreenshotOptions{Format: WebP, Quality: quality(50), FullPage: true}, map[string]interface{}{"format": "webp", "quality": 50, "captureBeyondViewport": true}},
+	} {
+		got, err := tc.opts.params()
+		if err != nil {
+			t.Fatalf("%+v.params() returned error: %v", tc.opts, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%+v.params() = %v, want %v", tc.opts, got, tc.want)
+		}
+	}
+
+	for _, opts := range []ScreenshotOptions{
+		{Format: JPEG, Quality: quality(101)},
+		{Format: "gif"},
+	} {
+		if _, err := opts.params(); err == nil {
+			t.Errorf("%+v.params() returned nil error", opts)
+		}
+	}
+}
+
+func TestCropPNG(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	src.Set(20, 10, color.RGBA{255, 0, 0, 255})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatalf("png.Encode() returned error: %v", err)
+	}
+
+	for _, tc := range []struct {
+		x, y, w, h int
+		ratio      float64
+		want       image.Point
+	}{
+		{10, 5, 20, 10, 1, image.Pt(20, 10)},
+		{10, 5, 20, 10, 2, image.Pt(40, 20)},
+		{90, 40, 50, 50, 2, image.Pt(20, 20)},
+	} {
+		data, err := cropPNG(buf.Bytes(), tc.x, tc.y, tc.w, tc.h, tc.ratio)
+		if err != nil {
+			t.Fatalf("cropPNG(%v, %v, %v, %v, %v) returned error: %v", tc.x, tc.y, tc.w, tc.h, tc.ratio, err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("png.Decode() returned error: %v", err)
+		}
+		if got := img.Bounds().Size(); got != tc.want {
+			t.Errorf("cropPNG(%v, %v, %v, %v, %v) size = %v, want %v", tc.x, tc.y, tc.w, tc.h, tc.ratio, got, tc.want)
+		}
+	}
+
+	if _, err := cropPNG(buf.Bytes(), 300, 300, 10, 10, 1); err == nil {
+		t.Errorf("cropPNG() outside of the image returned nil error")
+	}
+}
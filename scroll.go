@@ -0,0 +1,100 @@
+package webdriver
+
+// ScrollOptions configures how elements are scrolled into view.
+type ScrollOptions struct {
+	// Block and Inline align the element vertically and horizontally:
+	// "start", "center", "end" or "nearest". They default to "center".
+	Block, Inline string
+	// Behavior is "auto", "smooth" or "instant". It defaults to "auto".
+	// Offsets are applied once the element is in place, so a smooth scroll
+	// with offsets scrolls without animation.
+	Behavior string
+	// Offset is the number of pixels kept clear above the element, e.g. the
+	// height of a sticky header.
+	Offset int
+	// AvoidFixed detects bars fixed or sticky to the top of the viewport that
+	// overlap the element, such as navbars, and keeps the element below
+	// them, in addition to Offset.
+	AvoidFixed bool
+}
+
+// WithScrollOptions sets how ScrollIntoView, and so ClickDOM, scroll elements
+// into view, e.g. to keep clicks from landing on a navbar overlaying the
+// element.
+func WithScrollOptions(opts ScrollOptions) Option {
+	return func(c *sessionConfig) {
+		c.scroll = &opts
+	}
+}
+
+// scrollScript scrolls arguments[0] into view as set by the options in
+// arguments[1], then scrolls the window up as far as needed to keep the top of
+// the element clear of fixed bars and the offset.
+const scrollScript = `var el = arguments[0], o = arguments[1];
+var adjust = o.offset > 0 || o.avoidFixed;
+el.scrollIntoView({
+	behavior: adjust && o.behavior === "smooth" ? "auto" : o.behavior,
+	block: o.block,
+	inline: o.inline
+});
+if (!adjust) {
+	return;
+}
+var r = el.getBoundingClientRect(), clear = o.offset;
+if (o.avoidFixed) {
+	var all = document.querySelectorAll("body *");
+	for (var i = 0; i < all.length; i++) {
+		var bar = all[i];
+		if (bar.contains(el)) {
+			continue;
+		}
+		var cs = getComputedStyle(bar);
+		if (cs.position !== "fixed" && cs.position !== "sticky" || cs.display === "none" || cs.visibility === "hidden") {
+			continue;
+		}
+		var b = bar.getBoundingClientRect();
+		if (b.top <= 1 && b.bottom > 0 && b.height < window.innerHeight / 2 && b.left < r.right && b.right > r.left) {
+			clear = Math.max(clear, b.bottom + o.offset);
+		}
+	}
+}
+if (r.top < clear) {
+	window.scrollBy(0, r.top - clear);
+}`
+
+// ScrollIntoViewOpts scrolls the element into view as set by opts and waits
+// until it is displayed. Offsets apply to the scrolling of the window, not of
+// scrollable containers inside the page.
+func (e *Element) ScrollIntoViewOpts(opts ScrollOptions) error {
+	if opts.Block == "" {
+		opts.Block = "center"
+	}
+	if opts.Inline == "" {
+		opts.Inline = "center"
+	}
+	if opts.Behavior == "" {
+		opts.Behavior = "auto"
+	}
+	err := e.inFrame(func() error {
+		_, err := e.s.ExecuteScript(scrollScript, []interface{}{e.WebElement, map[string]interface{}{
+			"block":      opts.Block,
+			"inline":     opts.Inline,
+			"behavior":   opts.Behavior,
+			"offset":     opts.Offset,
+			"avoidFixed": opts.AvoidFixed,
+		}})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return e.s.waitOn(func() (bool, error) {
+		var displayed bool
+		err := e.inFrame(func() (err error) {
+			displayed, err = e.WebElement.IsDisplayed()
+			return err
+		})
+		return err != nil || displayed, err
+	}, e.s.timeout)
+}
@@ -0,0 +1,240 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+// SelectorRegistry maps logical names, e.g. "search.box", to selectors, so
+// that they can be updated in a configuration file when the target site
+// changes, without recompiling. A name maps to an xpath or to a fallback
+// chain of locators, see GetFirst. It is safe for concurrent use.
+type SelectorRegistry struct {
+	path string
+
+	mu        sync.RWMutex
+	selectors map[string][]Locator
+}
+
+// NewSelectorRegistry returns a registry of the given selectors, in the
+// textual form of ParseLocator.
+func NewSelectorRegistry(selectors map[string]string) *SelectorRegistry {
+	r := &SelectorRegistry{selectors: map[string][]Locator{}}
+	for name, sel := range selectors {
+		r.selectors[name] = []Locator{ParseLocator(sel)}
+	}
+	return r
+}
+
+// LoadSelectors reads a registry from a JSON file, if path ends with .json,
+// or a YAML file. Nested maps are flattened into dotted names, and lists
+// define fallback chains, in the textual form of ParseLocator:
+//
+//	search:
+//	  box: //input[@name='q']
+//	  button:
+//	    - //button[@type='submit']
+//	    - css:form .search-btn
+//	    - text:Search
+//
+// defines "search.box" and "search.button".
+func LoadSelectors(path string) (*SelectorRegistry, error) {
+	r := &SelectorRegistry{path: path}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the file the registry was loaded from again, replacing all its
+// selectors.
+func (r *SelectorRegistry) Reload() error {
+	if r.path == "" {
+		return fmt.Errorf("selector registry was not loaded from a file")
+	}
+	data, err := ioutil.ReadFile(r.path)
+	if err != nil {
+		return err
+	}
+	selectors, err := parseSelectors(data, strings.EqualFold(filepath.Ext(r.path), ".json"))
+	if err != nil {
+		return fmt.Errorf("invalid selectors in %v: %v", r.path, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.selectors = selectors
+	return nil
+}
+
+func parseSelectors(data []byte, isJSON bool) (map[string][]Locator, error) {
+	var tree interface{}
+	if isJSON {
+		if err := json.Unmarshal(data, &tree); err != nil {
+			return nil, err
+		}
+	} else if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+
+	selectors := map[string][]Locator{}
+	var walk func(prefix string, v interface{}) error
+	walk = func(prefix string, v interface{}) error {
+		switch v := v.(type) {
+		case string:
+			if prefix == "" {
+				return fmt.Errorf("selector %q has no name", v)
+			}
+			selectors[prefix] = []Locator{ParseLocator(v)}
+		case []interface{}:
+			if prefix == "" || len(v) == 0 {
+				return fmt.Errorf("invalid fallback chain %q", prefix)
+			}
+			for _, sel := range v {
+				str, ok := sel.(string)
+				if !ok {
+					return fmt.Errorf("selector %q has a %T fallback, not a string", prefix, sel)
+				}
+				selectors[prefix] = append(selectors[prefix], ParseLocator(str))
+			}
+		case map[string]interface{}:
+			for k, child := range v {
+				if err := walk(joinName(prefix, k), child); err != nil {
+					return err
+				}
+			}
+		case map[interface{}]interface{}:
+			for k, child := range v {
+				if err := walk(joinName(prefix, fmt.Sprint(k)), child); err != nil {
+					return err
+				}
+			}
+		case nil:
+			if prefix != "" {
+				return fmt.Errorf("selector %q is empty", prefix)
+			}
+		default:
+			return fmt.Errorf("selector %q is a %T, not a string", prefix, v)
+		}
+		return nil
+	}
+	if err := walk("", tree); err != nil {
+		return nil, err
+	}
+	return selectors, nil
+}
+
+func joinName(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// Locators returns the fallback chain registered as name.
+func (r *SelectorRegistry) Locators(name string) ([]Locator, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	locators, ok := r.selectors[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown selector %q", name)
+	}
+	return locators, nil
+}
+
+// XPath returns the xpath registered as name. It fails if name is registered
+// as a CSS selector or a fallback chain.
+func (r *SelectorRegistry) XPath(name string) (string, error) {
+	locators, err := r.Locators(name)
+	if err != nil {
+		return "", err
+	}
+	if len(locators) != 1 || locators[0].By != ByXPATH {
+		return "", fmt.Errorf("selector %q is not a single xpath", name)
+	}
+	return locators[0].Value, nil
+}
+
+// Names returns the registered names in sorted order.
+func (r *SelectorRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var names []string
+	for name := range r.selectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithSelectors sets the registry resolving the names of GetNamed and its
+// variants.
+func WithSelectors(r *SelectorRegistry) Option {
+	return func(c *sessionConfig) {
+		c.selectors = r
+	}
+}
+
+func (s *Session) named(name string) ([]Locator, error) {
+	if s.selectors == nil {
+		return nil, fmt.Errorf("session has no selector registry, see WithSelectors")
+	}
+	return s.selectors.Locators(name)
+}
+
+func (s *Session) namedXPath(name string) (string, error) {
+	if s.selectors == nil {
+		return "", fmt.Errorf("session has no selector registry, see WithSelectors")
+	}
+	return s.selectors.XPath(name)
+}
+
+// GetNamed waits for the element registered as name, trying its fallback
+// chain as GetFirst does.
+func (s *Session) GetNamed(name string) (*Element, error) {
+	locators, err := s.named(name)
+	if err != nil {
+		return nil, err
+	}
+	elem, _, err := s.getFirst("GetNamed", locators)
+	return elem, err
+}
+
+// GetNamedAll is GetDOMs with the xpath registered as name.
+func (s *Session) GetNamedAll(name string) ([]*Element, error) {
+	xpath, err := s.namedXPath(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetDOMs(xpath)
+}
+
+// ClickNamed clicks the element registered as name, as ClickDOM does for a
+// single xpath.
+func (s *Session) ClickNamed(name string) error {
+	if xpath, err := s.namedXPath(name); err == nil {
+		return s.ClickDOM(xpath)
+	}
+	elem, err := s.GetNamed(name)
+	if err != nil {
+		return err
+	}
+	return elem.Click()
+}
+
+// GetNamed is Session.GetNamed for the descendants of e.
+func (e *Element) GetNamed(name string) (*Element, error) {
+	locators, err := e.s.named(name)
+	if err != nil {
+		return nil, err
+	}
+	elem, _, err := e.getFirst("GetNamed", locators)
+	return elem, err
+}
@@ -0,0 +1,64 @@
+package webdriver
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadSelectors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "webdriver-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	yamlPath := filepath.Join(dir, "selectors.yaml")
+	if err := ioutil.WriteFile(yamlPath, []byte("search:\n  box: //input[@name='q']\nresults:\n  item: //div[@class='result']\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := LoadSelectors(yamlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := r.Names(), []string{"results.item", "search.box"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+	if xpath, err := r.XPath("search.box"); err != nil || xpath != "//input[@name='q']" {
+		t.Errorf("XPath(search.box) = %q, %v", xpath, err)
+	}
+	if _, err := r.XPath("search.button"); err == nil {
+		t.Error("XPath of an unknown name succeeded")
+	}
+
+	if err := ioutil.WriteFile(yamlPath, []byte("search.box: //input[@id='q']\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if xpath, _ := r.XPath("search.box"); xpath != "//input[@id='q']" {
+		t.Errorf("XPath(search.box) after Reload = %q", xpath)
+	}
+
+	jsonPath := filepath.Join(dir, "selectors.json")
+	if err := ioutil.WriteFile(jsonPath, []byte(`{"search": {"box": 1}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSelectors(jsonPath); err == nil {
+		t.Error("LoadSelectors with a number selector succeeded")
+	}
+}
+
+func TestSelectorFallbackChain(t *testing.T) {
+	tree, err := parseSelectors([]byte("search:\n  button:\n    - //button[@type='submit']\n    - css:form .search-btn\n"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Locator{XPathLocator("//button[@type='submit']"), CSSLocator("form .search-btn")}
+	if got := tree["search.button"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("search.button = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,79 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ServiceWorker is a service worker registration of the current origin.
+type ServiceWorker struct {
+	// Scope is the URL prefix controlled by the worker.
+	Scope string
+	// ScriptURL is the URL of the worker script.
+	ScriptURL string
+	// State is the state of the active worker, e.g. "activated", or of the
+	// installing or waiting one if none is active.
+	State string
+}
+
+// ServiceWorkers lists the service workers registered by the origin of the
+// current page.
+func (s *Session) ServiceWorkers() ([]ServiceWorker, error) {
+	data, err := s.ExecuteScriptAsyncRaw(`var done = arguments[arguments.length - 1];
+if (!navigator.serviceWorker) {
+	done([]);
+	return;
+}
+navigator.serviceWorker.getRegistrations().then(function(regs) {
+	done(regs.map(function(r) {
+		var w = r.active || r.waiting || r.installing;
+		return {Scope: r.scope, ScriptURL: w ? w.scriptURL : "", State: w ? w.state : ""};
+	}));
+}, function() { done([]); });`, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := struct{ Value []ServiceWorker }{}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Value, nil
+}
+
+// UnregisterServiceWorker unregisters the service worker controlling scope,
+// as reported by ServiceWorkers.
+func (s *Session) UnregisterServiceWorker(scope string) error {
+	if err := s.cdp("ServiceWorker.enable", nil, nil); err != nil {
+		return err
+	}
+	if err := s.cdp("ServiceWorker.unregister", map[string]interface{}{
+		"scopeURL": scope,
+	}, nil); err != nil {
+		return fmt.Errorf("error unregistering service worker %v: %v", scope, err)
+	}
+	return nil
+}
+
+// UnregisterServiceWorkers unregisters all the service workers of the origin
+// of the current page, so that it stops serving cached responses.
+func (s *Session) UnregisterServiceWorkers() error {
+	workers, err := s.ServiceWorkers()
+	if err != nil {
+		return err
+	}
+	for _, w := range workers {
+		if err := s.UnregisterServiceWorker(w.Scope); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetBypassServiceWorker makes the requests of the page skip service workers
+// and go to the network, without unregistering them.
+func (s *Session) SetBypassServiceWorker(bypass bool) error {
+	return s.cdp("Network.setBypassServiceWorker", map[string]interface{}{
+		"bypass": bypass,
+	}, nil)
+}
@@ -1,24 +1,21 @@
 package webdriver
 
 import (
-	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
-	"runtime/debug"
 	"strconv"
 	"strings"
 	"sync"
 	"syscall"
 	"time"
 
-	"github.com/phayes/freeport"
 	"github.com/pkg/errors"
-
-	"github.com/iamjinlei/memfs"
 )
 
 var (
@@ -41,9 +38,18 @@ type driver struct {
 	addr            string
 	cmd             *exec.Cmd
 	shutdownURLPath string
+	container       *dockerContainer
+	log             *rotatingFile
 }
 
 func (d *driver) Stop() error {
+	if d.container != nil {
+		return d.container.remove()
+	}
+	if d.cmd == nil {
+		return nil
+	}
+
 	// Selenium 3 stopped supporting the shutdown URL by default.
 	// https://github.com/SeleniumHQ/selenium/issues/2852
 	if d.shutdownURLPath == "" {
@@ -58,38 +64,72 @@ func (d *driver) Stop() error {
 		resp.Body.Close()
 	}
 
-	if err := d.cmd.Wait(); err != nil && err.Error() != "signal: killed" {
+	err := d.cmd.Wait()
+	d.closeLog()
+	if err != nil && err.Error() != "signal: killed" {
 		return err
 	}
 
 	return nil
 }
 
+func (d *driver) closeLog() {
+	if d.log != nil {
+		d.log.Close()
+	}
+}
+
 type server struct {
 	d         *driver
+	kind      DriverKind
 	port      int
 	ownDriver bool
+	cloud     *CloudOptions
+	shards    *scheduler
+	events    *Events
 }
 
 var inst *server
 var sessions []*Session
 var smu sync.Mutex
 
-func Init(port int, debug bool) error {
-	chromeDriverPath := strings.TrimSpace(os.Getenv("CHROME_DRIVER"))
-	if chromeDriverPath == "" {
-		return fmt.Errorf("env CHROME_DRIVER is missing")
+// Init starts the WebDriver server on the given port, unless one is already
+// listening there. By default ChromeDriver is used; pass WithDriver to select
+// another implementation.
+func Init(port int, debug bool, opts ...InitOption) error {
+	cfg := initConfig{kind: ChromeDriver}
+	for _, opt := range opts {
+		opt(&cfg)
 	}
 
-	if port < 1000 {
+	if port < 1000 && cfg.cloud == nil && cfg.shards == nil && cfg.kind != CDPDriver {
 		return fmt.Errorf("driver port < 1000: %v", port)
 	}
 
-	// detect chrome driver running process
-	out, _ := exec.Command("pgrep", filepath.Base(chromeDriverPath)).CombinedOutput()
-	pidStr := strings.TrimSpace(string(out))
-	if len(pidStr) > 0 {
-		fmt.Printf("*** [webdriver] detected chrome driver running process (PIDs = %v) ***\n", strings.Replace(pidStr, "\n", ", ", -1))
+	if cfg.transport != nil {
+		if cfg.httpClient != nil {
+			return fmt.Errorf("WithHTTPClient cannot be combined with WithTransport or WithTLS")
+		}
+		c, err := NewHTTPClient(*cfg.transport)
+		if err != nil {
+			return fmt.Errorf("invalid transport options: %v", err)
+		}
+		cfg.httpClient = c
+	}
+
+	var driverPath string
+	if cfg.docker == nil && cfg.cloud == nil && cfg.shards == nil && cfg.kind != CDPDriver {
+		var err error
+		if driverPath, err = cfg.kind.path(); err != nil {
+			return err
+		}
+
+		// detect driver running process
+		out, _ := exec.Command("pgrep", filepath.Base(driverPath)).CombinedOutput()
+		pidStr := strings.TrimSpace(string(out))
+		if len(pidStr) > 0 {
+			fmt.Printf("*** [webdriver] detected %v running process (PIDs = %v) ***\n", cfg.kind, strings.Replace(pidStr, "\n", ", ", -1))
+		}
 	}
 
 	smu.Lock()
@@ -99,18 +139,55 @@ func Init(port int, debug bool) error {
 	}
 
 	SetDebug(debug)
+	if cfg.httpClient != nil {
+		HTTPClient = cfg.httpClient
+	}
 
-	d, isOwned, err := newChromeDriver(chromeDriverPath, port)
+	var d *driver
+	var isOwned bool
+	var err error
+	var shards *scheduler
+	if cfg.shards != nil {
+		if shards, err = newScheduler(*cfg.shards); err == nil {
+			d = &driver{port: port, addr: shards.endpoints[0].URL}
+		}
+	} else if cfg.cloud != nil {
+		var hub string
+		if hub, err = cfg.cloud.hubURL(); err == nil {
+			d = &driver{port: port, addr: hub}
+		}
+	} else if cfg.kind == CDPDriver {
+		// Each session launches its own browser.
+		d = &driver{port: port}
+		isOwned = true
+	} else if cfg.docker != nil {
+		d, err = startContainer(cfg.kind, *cfg.docker, port)
+		isOwned = true
+	} else {
+		d, isOwned, err = startDriver(cfg.kind, driverPath, port, cfg.driverLog)
+		if err == nil && cfg.versionCheck && (cfg.kind == ChromeDriver || cfg.kind == EdgeDriver) {
+			if err = checkVersions(d.addr, cfg.browserBinary, cfg.kind); err != nil && isOwned {
+				d.Stop()
+			}
+		}
+	}
 	if err != nil {
 		return err
 	}
 
 	inst = &server{
 		d:         d,
+		kind:      cfg.kind,
 		port:      port,
 		ownDriver: isOwned,
+		cloud:     cfg.cloud,
+		shards:    shards,
+		events:    cfg.events,
 	}
 
+	if cfg.noSignalHandler {
+		return nil
+	}
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
@@ -125,13 +202,8 @@ func Init(port int, debug bool) error {
 	return nil
 }
 
-func newChromeDriver(path string, port int) (*driver, bool, error) {
-	d := &driver{
-		port:            port,
-		addr:            fmt.Sprintf("http://localhost:%d/wd/hub", port),
-		shutdownURLPath: "/shutdown",
-		cmd:             exec.Command(path, "--port="+strconv.Itoa(port), "--url-base=wd/hub", "--verbose"),
-	}
+func startDriver(kind DriverKind, path string, port int, logOpts *DriverLogOptions) (*driver, bool, error) {
+	d := kind.newDriver(path, port, logOpts)
 
 	if debugFlag {
 		d.cmd.Stderr = os.Stderr
@@ -139,100 +211,314 @@ func newChromeDriver(path string, port int) (*driver, bool, error) {
 	}
 	d.cmd.Env = os.Environ()
 
-	status := func(addr string) int {
-		resp, err := http.Get(addr + "/status")
-		if err == nil {
-			resp.Body.Close()
-			switch resp.StatusCode {
-			// Selenium <3 returned Forbidden and BadRequest. ChromeDriver and
-			// Selenium 3 return OK.
-			case http.StatusForbidden, http.StatusBadRequest, http.StatusOK:
-				return http.StatusOK
-			default:
-				return resp.StatusCode
-			}
-		}
-
-		return http.StatusInternalServerError
+	if driverStatus(d.addr) == http.StatusOK {
+		return d, false, nil
 	}
 
-	if status(d.addr) == http.StatusOK {
-		return d, false, nil
+	if logOpts != nil && logOpts.Path != "" {
+		f, err := openRotatingFile(logOpts.Path, logOpts.MaxSize, logOpts.MaxBackups)
+		if err != nil {
+			return nil, false, err
+		}
+		d.log = f
+		d.cmd.Stderr = f
+		d.cmd.Stdout = f
+		if debugFlag {
+			d.cmd.Stderr = io.MultiWriter(os.Stderr, f)
+			d.cmd.Stdout = io.MultiWriter(os.Stdout, f)
+		}
 	}
 
-	fmt.Printf("*** [webdriver] starting chromedriver ***\n")
+	fmt.Printf("*** [webdriver] starting %v ***\n", kind)
 	if err := d.cmd.Start(); err != nil {
+		d.closeLog()
 		return nil, false, err
 	}
 
 	for i := 0; i < 30; i++ {
 		time.Sleep(time.Second)
-		if status(d.addr) == http.StatusOK {
+		if driverStatus(d.addr) == http.StatusOK {
 			return d, true, nil
 		}
 	}
 
-	return nil, false, fmt.Errorf("failed to start chrome driver on port %d", port)
+	d.cmd.Process.Kill()
+	d.cmd.Wait()
+	d.closeLog()
+	return nil, false, fmt.Errorf("failed to start %v on port %d", kind, port)
+}
+
+// driverStatus probes the WebDriver server at addr, returning http.StatusOK if
+// it is up.
+func driverStatus(addr string) int {
+	return statusWith(http.DefaultClient, addr)
+}
+
+func statusWith(client *http.Client, addr string) int {
+	resp, err := client.Get(addr + "/status")
+	if err == nil {
+		resp.Body.Close()
+		switch resp.StatusCode {
+		// Selenium <3 returned Forbidden and BadRequest. ChromeDriver and
+		// Selenium 3 return OK.
+		case http.StatusForbidden, http.StatusBadRequest, http.StatusOK:
+			return http.StatusOK
+		default:
+			return resp.StatusCode
+		}
+	}
+
+	return http.StatusInternalServerError
 }
 
+// Shutdown runs the hooks registered by RegisterShutdownHook, closes all
+// sessions, stops the driver if Init started it and deletes the temporary
+// profiles.
 func Shutdown() {
+	runShutdownHooks()
+
+	var closed []*Session
+	defer func() {
+		for _, s := range closed {
+			s.events.sessionClosed(s)
+		}
+	}()
 	smu.Lock()
 	defer smu.Unlock()
+	closed = sessions
 	for _, s := range sessions {
 		fmt.Printf("*** [webdriver] closing session %v ***\n", s.SessionID())
-		s.Quit()
+		s.teardown()
 	}
 	sessions = nil
 
-	if inst != nil && inst.ownDriver {
+	if inst != nil && inst.shards != nil {
+		fmt.Printf("*** [webdriver] stopping shard health checks ***\n")
+		inst.shards.stop()
+		inst = nil
+	} else if inst != nil && inst.ownDriver {
 		fmt.Printf("*** [webdriver] stopping webdriver ***\n")
 		inst.d.Stop()
 		inst = nil
 	} else {
 		fmt.Printf("*** [webdriver] leave alone webdriver (not owned) ***\n")
 	}
+	deleteTempProfiles()
 	fmt.Printf("*** [webdriver] shutdown complete ***\n\n")
 }
 
 type Session struct {
 	WebDriver
 	timeout time.Duration
+
+	profile   string
+	createdAt time.Time
+
+	artifactsDir string
+	sink         SnapshotSink
+	cloudURL     string
+	shard        *shardEndpoint
+
+	mu      sync.Mutex
+	label   string
+	trace   *tracer
+	gallery *snapGallery
+
+	scopedHeaders   map[string]map[string]string
+	headersScriptID string
+
+	pageConns map[string]*cdpConn
+	mocks     map[*cdpConn]*mocker
+	trackers  map[*cdpConn]*requestTracker
+
+	popups  *popupWatcher
+	limits  *limitWatcher
+	uploads string
+	events  *Events
+
+	// done is closed by Close for sessions of NewCtx, and err, guarded by
+	// errMu, records why the session is dead.
+	done  chan struct{}
+	errMu sync.Mutex
+	err   error
+
+	delayFirstPoll bool
+	poll           *PollStrategy
+	healLimit      int
+	selectors      *SelectorRegistry
+
+	// frameDepth is the frame search depth, and frame the path of the frame
+	// the session switched to last while searching, guarded by frameMu.
+	frameDepth int
+	frameMu    sync.Mutex
+	frame      []int
+
+	// navTimeout bounds navigations, and navStop makes them stop loading
+	// when it expires, see WithNavigationTimeout.
+	navTimeout time.Duration
+	navStop    bool
+	// pageLoadTimeout is the timeout set last by SetPageLoadTimeout, guarded
+	// by mu.
+	pageLoadTimeout time.Duration
+	scroll          *ScrollOptions
+	consent         *ConsentOptions
+
+	// setup sets up a new browser of the session as New does, see Restart.
+	setup func(WebDriver) error
 }
 
 type Element struct {
 	s *Session
 	WebElement
-}
 
-func New(profile string, w, h int, headless bool, timeout time.Duration) (*Session, error) {
-	caps := Capabilities{"browserName": "chrome"}
+	// parent and xpath record how the element was located, so that it can be
+	// located again once its reference goes stale. A nil parent means the
+	// xpath is evaluated from the document root.
+	parent *Element
+	xpath  string
+
+	// frame is the frame path of an element found by a frame search, see
+	// WithFrameSearch, if framed is set.
+	frame  []int
+	framed bool
+}
 
-	chromeCfg := chromeCapabilities{
-		Args: []string{
-			fmt.Sprintf("window-size=%v,%v", w, h),
-			"disable-notifications",
-		},
+// New creates a session with a browser window of the given size. profile, if
+// not empty, is the user data directory of the browser. timeout bounds the
+// element waits of the session. opts customize the browser further.
+func New(profile string, w, h int, headless bool, timeout time.Duration, opts ...Option) (*Session, error) {
+	cfg := &sessionConfig{
+		profile:  profile,
+		width:    w,
+		height:   h,
+		headless: headless,
+	}
+	for _, opt := range opts {
+		opt(cfg)
 	}
-	if headless {
-		chromeCfg.Args = append(chromeCfg.Args, "headless")
+	if cfg.limits != nil && cfg.limits.Recycle && inst.kind == CDPDriver {
+		return nil, fmt.Errorf("recycling sessions is not supported by the cdp driver")
 	}
-	if profile != "" {
-		chromeCfg.Args = append(chromeCfg.Args, fmt.Sprintf("user-data-dir=%v", profile))
+
+	// Paths in docker and cloud mode refer to a remote filesystem.
+	if profile != "" && inst.d.container == nil && inst.cloud == nil && inst.shards == nil {
+		if err := LockCheck(profile); err != nil {
+			return nil, err
+		}
 	}
 
-	caps.AddChrome(chromeCfg)
+	caps, err := inst.kind.capabilities(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if inst.cloud != nil {
+		inst.cloud.capabilities(caps)
+	}
+	if cfg.promptBehavior != "" {
+		caps["unhandledPromptBehavior"] = cfg.promptBehavior
+	}
 
-	d, err := NewRemote(caps, fmt.Sprintf("http://localhost:%d/wd/hub", inst.port))
+	var (
+		d     WebDriver
+		shard *shardEndpoint
+	)
+	err = cfg.retry.do(func() (err error) {
+		if inst.kind == CDPDriver {
+			d, err = launchChrome(cfg)
+		} else if inst.shards != nil {
+			d, shard, err = inst.shards.newRemote(caps)
+		} else {
+			d, err = NewRemote(caps, inst.d.addr)
+		}
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	s := &Session{d, timeout}
+	// abort releases what New set up so far when a later step fails.
+	var s *Session
+	abort := func(err error) (*Session, error) {
+		if s != nil {
+			s.stopPopupWatcher()
+			s.closePageConns()
+		}
+		d.Quit()
+		shard.release()
+		return nil, err
+	}
+
+	kind := inst.kind
+	setup := func(wd WebDriver) error {
+		if err := kind.setup(wd, cfg); err != nil {
+			return err
+		}
+		if cfg.navTimeout > 0 {
+			return wd.SetPageLoadTimeout(cfg.navTimeout)
+		}
+		return nil
+	}
+	if err := setup(d); err != nil {
+		return abort(err)
+	}
+
+	s = &Session{
+		WebDriver: d,
+		timeout:   timeout,
+		profile:   profile,
+		createdAt: time.Now(),
+
+		artifactsDir: cfg.artifactsDir,
+		sink:         cfg.sink,
+		shard:        shard,
+		events:       inst.events,
+
+		delayFirstPoll: cfg.delayFirstPoll,
+		poll:           cfg.poll,
+		healLimit:      cfg.healLimit,
+		selectors:      cfg.selectors,
+		frameDepth:     cfg.frameDepth,
+		navTimeout:     cfg.navTimeout,
+		navStop:        cfg.navStop,
+		scroll:         cfg.scroll,
+		consent:        cfg.consent,
+		setup:          setup,
+	}
+	if s.events != nil && (s.events.OnNavigation != nil || s.events.OnCommandError != nil) {
+		s.Use(s.events.hook(s))
+	}
+	if inst.cloud != nil {
+		s.cloudURL = inst.cloud.sessionURL(d.SessionID())
+		fmt.Printf("*** [webdriver] cloud session %v ***\n", s.cloudURL)
+	}
+
+	if cfg.popups != nil {
+		if s.popups, err = startPopupWatcher(s, *cfg.popups); err != nil {
+			return abort(err)
+		}
+	}
+
+	if cfg.filterList != nil {
+		if err := s.SetFilterList(cfg.filterList); err != nil {
+			return abort(err)
+		}
+	}
+
+	if len(cfg.blockedTypes) > 0 {
+		if err := s.SetBlockedResourceTypes(cfg.blockedTypes...); err != nil {
+			return abort(err)
+		}
+	}
+
+	if cfg.limits != nil {
+		s.limits = startLimitWatcher(s, *cfg.limits)
+	}
 
 	smu.Lock()
-	defer smu.Unlock()
 	sessions = append(sessions, s)
+	smu.Unlock()
 
+	s.events.sessionCreated(s)
 	return s, nil
 }
 
@@ -244,14 +530,45 @@ func (s *Session) Close() error {
 			break
 		}
 	}
-	sessions[idx] = sessions[len(sessions)-1]
-	sessions = sessions[:len(sessions)-1]
+	// The session may already have been closed, e.g. by the reaper.
+	open := idx < len(sessions)
+	if open {
+		sessions[idx] = sessions[len(sessions)-1]
+		sessions = sessions[:len(sessions)-1]
+		s.shard.release()
+	}
 	smu.Unlock()
+	if open {
+		defer s.events.sessionClosed(s)
+	}
+
+	return s.teardown()
+}
 
+// teardown stops the goroutines of the session, releases its resources and
+// quits the browser.
+func (s *Session) teardown() error {
+	s.markDone()
+	s.stopLimitWatcher()
+	s.stopPopupWatcher()
+	s.closeGallery()
+	s.closePageConns()
+	defer s.removeUploads()
 	return s.Quit()
 }
 
 func (s *Session) find(xpath string) (*Element, error) {
+	if s.frameDepth > 0 {
+		return s.findInFrames(xpath)
+	}
+	return s.findHere(xpath)
+}
+
+// findHere looks up xpath in the current frame only.
+func (s *Session) findHere(xpath string) (*Element, error) {
+	if IsDeepSelector(xpath) {
+		return s.findDeep(nil, xpath)
+	}
 	elem, err := s.FindElement(ByXPATH, xpath)
 	if notFound(err) {
 		return nil, ErrNotFound
@@ -261,10 +578,13 @@ func (s *Session) find(xpath string) (*Element, error) {
 		return nil, ErrNotFound
 	}
 
-	return &Element{s, elem}, nil
+	return &Element{s: s, WebElement: elem, xpath: xpath}, nil
 }
 
 func (s *Session) findN(xpath string) ([]*Element, error) {
+	if IsDeepSelector(xpath) {
+		return s.findDeepN(nil, xpath)
+	}
 	elements, err := s.FindElements(ByXPATH, xpath)
 	if notFound(err) {
 		return nil, ErrNotFound
@@ -275,8 +595,8 @@ func (s *Session) findN(xpath string) ([]*Element, error) {
 	}
 
 	ret := []*Element{}
-	for _, elem := range elements {
-		ret = append(ret, &Element{s, elem})
+	for i, elem := range elements {
+		ret = append(ret, &Element{s: s, WebElement: elem, xpath: nthXPath(xpath, i)})
 	}
 	return ret, nil
 }
@@ -288,10 +608,10 @@ func (s *Session) GetDOM(xpath string) (*Element, error) {
 
 func (s *Session) GetDOMTimeout(xpath string, to time.Duration) (*Element, error) {
 	var ret *Element
-	err := waitOn(func() (bool, error) {
+	err := s.waitOn(func() (bool, error) {
 		elem, err := s.find(xpath)
-		if err == ErrNotFound {
-			return false, nil
+		if err == ErrNotFound || IsRetryable(err) {
+			return false, retryWith(err)
 		} else if err != nil {
 			return true, err
 		} else if elem == nil {
@@ -303,16 +623,16 @@ func (s *Session) GetDOMTimeout(xpath string, to time.Duration) (*Element, error
 		return true, nil
 	}, to)
 
-	return ret, err
+	return ret, s.fail("GetDOM", xpath, err)
 }
 
 // GetDOMs expects elements existence
 func (s *Session) GetDOMs(xpath string) ([]*Element, error) {
 	var ret []*Element
-	err := waitOn(func() (bool, error) {
+	err := s.waitOn(func() (bool, error) {
 		elems, err := s.findN(xpath)
-		if err == ErrNotFound {
-			return false, nil
+		if err == ErrNotFound || IsRetryable(err) {
+			return false, retryWith(err)
 		} else if err != nil {
 			return true, err
 		} else if len(elems) == 0 {
@@ -324,31 +644,50 @@ func (s *Session) GetDOMs(xpath string) ([]*Element, error) {
 		return true, nil
 	}, s.timeout)
 
-	return ret, err
+	return ret, s.fail("GetDOMs", xpath, err)
 }
 
 func (s *Session) ClickDOM(xpath string) error {
-	return waitOn(func() (bool, error) {
+	err := s.waitOn(func() (bool, error) {
 		elem, err := s.find(xpath)
-		if err == ErrNotFound {
-			return false, nil
+		if err == ErrNotFound || IsRetryable(err) {
+			return false, retryWith(err)
 		} else if err != nil {
 			return true, err
 		}
 
-		if err := elem.ScrollIntoView(); err != nil {
+		if err := elem.ScrollIntoView(); IsRetryable(err) {
+			return false, retryWith(err)
+		} else if err != nil {
 			return true, err
 		}
 
-		if err := elem.Click(); err != nil {
+		if err := elem.Click(); IsRetryable(err) {
+			return false, retryWith(err)
+		} else if err != nil {
 			return true, err
 		}
 
 		return true, nil
 	}, s.timeout)
+
+	return s.fail("ClickDOM", xpath, err)
 }
 
 func (e *Element) find(xpath string) (*Element, error) {
+	var elem *Element
+	err := e.inFrame(func() (err error) {
+		elem, err = e.findHere(xpath)
+		return err
+	})
+	return elem, err
+}
+
+// findHere looks up xpath under the element in the current frame.
+func (e *Element) findHere(xpath string) (*Element, error) {
+	if IsDeepSelector(xpath) {
+		return e.s.findDeep(e, xpath)
+	}
 	elem, err := e.WebElement.FindElement(ByXPATH, xpath)
 	if notFound(err) {
 		return nil, ErrNotFound
@@ -357,10 +696,24 @@ func (e *Element) find(xpath string) (*Element, error) {
 	} else if elem == nil {
 		return nil, ErrNotFound
 	}
-	return &Element{e.s, elem}, nil
+	return &Element{s: e.s, WebElement: elem, parent: e, xpath: xpath, frame: e.frame, framed: e.framed}, nil
 }
 
 func (e *Element) findN(xpath string) ([]*Element, error) {
+	var elems []*Element
+	err := e.inFrame(func() (err error) {
+		elems, err = e.findHereN(xpath)
+		return err
+	})
+	return elems, err
+}
+
+// findHereN looks up all the matches of xpath under the element in the
+// current frame.
+func (e *Element) findHereN(xpath string) ([]*Element, error) {
+	if IsDeepSelector(xpath) {
+		return e.s.findDeepN(e, xpath)
+	}
 	elements, err := e.WebElement.FindElements(ByXPATH, xpath)
 	if notFound(err) {
 		return nil, ErrNotFound
@@ -371,8 +724,8 @@ func (e *Element) findN(xpath string) ([]*Element, error) {
 	}
 
 	ret := []*Element{}
-	for _, elem := range elements {
-		ret = append(ret, &Element{e.s, elem})
+	for i, elem := range elements {
+		ret = append(ret, &Element{s: e.s, WebElement: elem, parent: e, xpath: nthXPath(xpath, i), frame: e.frame, framed: e.framed})
 	}
 	return ret, nil
 }
@@ -380,10 +733,10 @@ func (e *Element) findN(xpath string) ([]*Element, error) {
 // GetDOM expects the element existence
 func (e *Element) GetDOM(xpath string) (*Element, error) {
 	var ret *Element
-	err := waitOn(func() (bool, error) {
+	err := e.s.waitOn(func() (bool, error) {
 		elem, err := e.find(xpath)
-		if err == ErrNotFound {
-			return false, nil
+		if err == ErrNotFound || IsRetryable(err) {
+			return false, retryWith(err)
 		} else if err != nil {
 			return true, err
 		} else if elem == nil {
@@ -395,16 +748,16 @@ func (e *Element) GetDOM(xpath string) (*Element, error) {
 		return true, nil
 	}, e.s.timeout)
 
-	return ret, err
+	return ret, e.s.fail("GetDOM", xpath, err)
 }
 
 // GetDOMs expects elements existence
 func (e *Element) GetDOMs(xpath string) ([]*Element, error) {
 	var ret []*Element
-	err := waitOn(func() (bool, error) {
+	err := e.s.waitOn(func() (bool, error) {
 		elems, err := e.findN(xpath)
-		if err == ErrNotFound {
-			return false, nil
+		if err == ErrNotFound || IsRetryable(err) {
+			return false, retryWith(err)
 		} else if err != nil {
 			return true, err
 		} else if len(elems) == 0 {
@@ -416,28 +769,34 @@ func (e *Element) GetDOMs(xpath string) ([]*Element, error) {
 		return true, nil
 	}, e.s.timeout)
 
-	return ret, err
+	return ret, e.s.fail("GetDOMs", xpath, err)
 }
 
 func (e *Element) ClickDOM(xpath string) error {
-	return waitOn(func() (bool, error) {
+	err := e.s.waitOn(func() (bool, error) {
 		elem, err := e.find(xpath)
-		if err == ErrNotFound {
-			return false, nil
+		if err == ErrNotFound || IsRetryable(err) {
+			return false, retryWith(err)
 		} else if err != nil {
 			return true, err
 		}
 
-		if err := elem.ScrollIntoView(); err != nil {
+		if err := elem.ScrollIntoView(); IsRetryable(err) {
+			return false, retryWith(err)
+		} else if err != nil {
 			return true, err
 		}
 
-		if err := elem.Click(); err != nil {
+		if err := elem.Click(); IsRetryable(err) {
+			return false, retryWith(err)
+		} else if err != nil {
 			return true, err
 		}
 
 		return true, nil
 	}, e.s.timeout)
+
+	return e.s.fail("ClickDOM", xpath, err)
 }
 
 func notFound(err error) bool {
@@ -449,8 +808,25 @@ func StaleElement(err error) bool {
 }
 
 func (s *Session) Wait(xpaths []string) (int, error) {
+	_, selected, err := s.race("Wait", xpaths)
+	return selected, err
+}
+
+// WaitElement is Wait returning the element matched along with the index of
+// its xpath, found by the same script that checks the xpaths, so that callers
+// need not find it again while the DOM may change.
+func (s *Session) WaitElement(xpaths ...string) (*Element, int, error) {
+	return s.race("WaitElement", xpaths)
+}
+
+func (s *Session) race(op string, xpaths []string) (*Element, int, error) {
+	return s.getFirst(op, xpathLocators(xpaths))
+}
+
+func (s *Session) getFirst(op string, locators []Locator) (*Element, int, error) {
+	var elem *Element
 	selected := -1
-	err := waitOn(func() (bool, error) {
+	err := s.waitOn(func() (bool, error) {
 		status, err := s.Status()
 		if err != nil {
 			return true, err
@@ -459,27 +835,114 @@ func (s *Session) Wait(xpaths []string) (int, error) {
 			return false, nil
 		}
 
-		for idx, xpath := range xpaths {
-			result, err := s.find(xpath)
-			if err == ErrNotFound {
-				continue
-			} else if err != nil {
-				return true, err
-			} else if result != nil {
-				selected = idx
-				return true, nil
-			}
+		found, idx, err := s.firstMatchInFrames(locators)
+		if IsRetryable(err) {
+			return false, retryWith(err)
+		} else if err == ErrNotFound {
+			return false, nil
+		} else if err != nil {
+			return true, err
 		}
-
-		return false, nil
+		elem, selected = found, idx
+		return true, nil
 	}, s.timeout)
 
-	return selected, err
+	return elem, selected, s.fail(op, locatorsString(locators), err)
+}
+
+// firstMatchInFrames returns the element matched by the first of locators
+// matching in the document, or in its frames with WithFrameSearch, and the
+// index of the locator. It returns ErrNotFound if none matches.
+func (s *Session) firstMatchInFrames(locators []Locator) (*Element, int, error) {
+	selected := -1
+	find := func() (*Element, error) {
+		idx, node, err := s.firstMatch(nil, locators)
+		if err != nil {
+			return nil, err
+		} else if idx < 0 {
+			return nil, ErrNotFound
+		}
+		selected = idx
+		return &Element{s: s, WebElement: node, xpath: locators[idx].xpath()}, nil
+	}
+	var elem *Element
+	var err error
+	if s.frameDepth > 0 {
+		elem, err = s.searchInFrames(find)
+	} else {
+		elem, err = find()
+	}
+	return elem, selected, err
+}
+
+// firstMatchScript returns the index of the first locator matching a node
+// under arguments[0], or the document, along with the node, or [-1, null] if
+// none matches. Deep selectors come as arrays of their parts.
+const firstMatchScript = deepQueryFunc + `
+var root = arguments[0] || document, locators = arguments[1];
+for (var i = 0; i < locators.length; i++) {
+	var by = locators[i][0], value = locators[i][1];
+	var node = Array.isArray(value) ? deepQuery(arguments[0], value)[0] :
+		by === "css selector" ? root.querySelector(value) :
+		document.evaluate(value, root, null, XPathResult.FIRST_ORDERED_NODE_TYPE, null).singleNodeValue;
+	if (node) {
+		return [i, node];
+	}
+}
+return [-1, null];`
+
+// firstMatch returns the index of the first of locators matching under root,
+// or the document if root is nil, and the element it matches, or -1 if none
+// matches. All locators are checked in a single round trip to the driver.
+func (s *Session) firstMatch(root WebElement, locators []Locator) (int, WebElement, error) {
+	var pairs [][]interface{}
+	for _, l := range locators {
+		if l.By == ByXPATH && IsDeepSelector(l.Value) {
+			pairs = append(pairs, []interface{}{l.By, deepParts(l.Value)})
+		} else {
+			pairs = append(pairs, []interface{}{l.By, l.Value})
+		}
+	}
+	data, err := s.ExecuteScriptRaw(firstMatchScript, []interface{}{root, pairs})
+	if err != nil {
+		return -1, nil, err
+	}
+	reply := struct {
+		Value []json.RawMessage
+	}{}
+	var idx int
+	if err := json.Unmarshal(data, &reply); err != nil || len(reply.Value) != 2 || json.Unmarshal(reply.Value[0], &idx) != nil {
+		return -1, nil, fmt.Errorf("unexpected locator match result %s", data)
+	}
+	if idx < 0 {
+		return -1, nil, nil
+	}
+	node, err := s.DecodeElement(rawValue(reply.Value[1]))
+	if err != nil {
+		return -1, nil, err
+	}
+	return idx, node, nil
 }
 
 func (e *Element) Wait(xpaths []string) (int, error) {
+	_, selected, err := e.race("Wait", xpaths)
+	return selected, err
+}
+
+// WaitElement is Wait returning the element matched under e along with the
+// index of its xpath, found by the same script that checks the xpaths.
+func (e *Element) WaitElement(xpaths ...string) (*Element, int, error) {
+	return e.race("WaitElement", xpaths)
+}
+
+func (e *Element) race(op string, xpaths []string) (*Element, int, error) {
+	return e.getFirst(op, xpathLocators(xpaths))
+}
+
+func (e *Element) getFirst(op string, locators []Locator) (*Element, int, error) {
+	var elem *Element
 	selected := -1
-	err := waitOn(func() (bool, error) {
+	err := e.s.waitOn(func() (bool, error) {
 		status, err := e.s.Status()
 		if err != nil {
 			return true, err
@@ -488,31 +951,99 @@ func (e *Element) Wait(xpaths []string) (int, error) {
 			return false, nil
 		}
 
-		for idx, xpath := range xpaths {
-			result, err := e.find(xpath)
-			if err == ErrNotFound {
-				continue
-			} else if err != nil {
-				return true, err
-			} else if result != nil {
-				selected = idx
-				return true, nil
+		var idx int
+		var node WebElement
+		err = e.inFrame(func() (err error) {
+			idx, node, err = e.s.firstMatch(e.WebElement, locators)
+			return err
+		})
+		if IsRetryable(err) {
+			return false, retryWith(err)
+		} else if err != nil {
+			return true, err
+		} else if idx < 0 {
+			return false, nil
+		}
+		elem = &Element{s: e.s, WebElement: node, parent: e, xpath: locators[idx].xpath(), frame: e.frame, framed: e.framed}
+		selected = idx
+		return true, nil
+	}, e.s.timeout)
+
+	return elem, selected, e.s.fail(op, locatorsString(locators), err)
+}
+
+// WaitCount waits until the number of elements matching xpath satisfies
+// predicate, a comparison with a number such as ">= 10" or "== 3", and returns
+// the count. A bare number means equality.
+func (s *Session) WaitCount(xpath, predicate string) (int, error) {
+	match, err := parseCountPredicate(predicate)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = s.waitOn(func() (bool, error) {
+		elems, err := s.FindElements(ByXPATH, xpath)
+		if notFound(err) {
+			elems, err = nil, nil
+		}
+		if err != nil {
+			if IsRetryable(err) {
+				return false, retryWith(err)
 			}
+			return true, err
 		}
+		count = len(elems)
+		return match(count), nil
+	}, s.timeout)
 
-		return false, nil
-	}, e.s.timeout)
+	return count, s.fail("WaitCount", xpath+" "+predicate, err)
+}
 
-	return selected, err
+// parseCountPredicate parses the predicate of WaitCount.
+func parseCountPredicate(predicate string) (func(int) bool, error) {
+	p := strings.TrimSpace(predicate)
+	op := "=="
+	for _, o := range []string{">=", "<=", "==", "!=", ">", "<"} {
+		if strings.HasPrefix(p, o) {
+			op = o
+			p = strings.TrimSpace(p[len(o):])
+			break
+		}
+	}
+	n, err := strconv.Atoi(p)
+	if err != nil {
+		return nil, fmt.Errorf("invalid count predicate %q", predicate)
+	}
+
+	switch op {
+	case ">=":
+		return func(c int) bool { return c >= n }, nil
+	case "<=":
+		return func(c int) bool { return c <= n }, nil
+	case "!=":
+		return func(c int) bool { return c != n }, nil
+	case ">":
+		return func(c int) bool { return c > n }, nil
+	case "<":
+		return func(c int) bool { return c < n }, nil
+	}
+	return func(c int) bool { return c == n }, nil
 }
 
-func (s *Session) Snap() error {
-	img, err := s.Screenshot()
+// cdp executes a Chrome DevTools Protocol command and decodes its result into
+// ret, unless ret is nil.
+func (s *Session) cdp(cmd string, params map[string]interface{}, ret interface{}) error {
+	data, err := s.ExecuteCDPRaw(cmd, params)
 	if err != nil {
 		return err
 	}
+	if ret == nil {
+		return nil
+	}
 
-	return serveSnap(img)
+	reply := struct{ Value interface{} }{ret}
+	return json.Unmarshal(data, &reply)
 }
 
 func (e *Element) Txt() string {
@@ -524,40 +1055,47 @@ func (e *Element) Txt() string {
 }
 
 func (e *Element) Parent() (*Element, error) {
-	parent, err := e.WebElement.FindElement(ByXPATH, "..")
+	var parent WebElement
+	err := e.inFrame(func() (err error) {
+		parent, err = e.WebElement.FindElement(ByXPATH, "..")
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &Element{e.s, parent}, nil
+	return &Element{s: e.s, WebElement: parent, parent: e, xpath: "..", frame: e.frame, framed: e.framed}, nil
 }
 
 func (e *Element) SetAttribute(attr, val string) error {
-	_, err := e.s.ExecuteScript("arguments[0].setAttribute(arguments[1], arguments[2]);", []interface{}{e.WebElement, attr, val})
-	return err
+	return e.inFrame(func() error {
+		_, err := e.s.ExecuteScript("arguments[0].setAttribute(arguments[1], arguments[2]);", []interface{}{e.WebElement, attr, val})
+		return err
+	})
 }
 
+// ScrollIntoView scrolls the element to the center of the view, or as set by
+// WithScrollOptions, and waits until it is displayed.
 func (e *Element) ScrollIntoView() error {
-	if _, err := e.s.ExecuteScript("arguments[0].scrollIntoView({behavior: \"auto\", block: \"center\", inline: \"center\"});", []interface{}{e.WebElement}); err != nil {
-		return err
+	var opts ScrollOptions
+	if e.s.scroll != nil {
+		opts = *e.s.scroll
 	}
-
-	return waitOn(func() (bool, error) {
-		if displayed, err := e.WebElement.IsDisplayed(); err != nil {
-			return true, err
-		} else if displayed {
-			return true, nil
-		}
-		return false, nil
-	}, e.s.timeout)
+	return e.ScrollIntoViewOpts(opts)
 }
 
 func (e *Element) Snap() error {
 	return e.s.Snap()
 }
 
+// nthXPath returns an xpath selecting only the i-th (zero-based) element
+// matched by xpath.
+func nthXPath(xpath string, i int) string {
+	return fmt.Sprintf("(%s)[%d]", xpath, i+1)
+}
+
 func (s *Session) NoStale(fn func() error) error {
-	return waitOn(func() (bool, error) {
+	return s.waitOn(func() (bool, error) {
 		err := fn()
 		if err == ErrNeedRetry || StaleElement(err) {
 			return false, nil
@@ -566,71 +1104,46 @@ func (s *Session) NoStale(fn func() error) error {
 	}, s.timeout)
 }
 
-func waitOn(fn func() (bool, error), timeout time.Duration) error {
-	ticker := time.NewTicker(1000 * time.Millisecond)
+// waitOn polls fn until it is done, fails or timeout expires, in which case
+// it returns a *TimeoutError. fn may return an error wrapped by retryWith to
+// keep polling, recording the error as the last one of the wait. fn is
+// evaluated immediately, unless the session was created with
+// WithDelayedFirstPoll. It then polls every second, or as set by
+// WithPollStrategy.
+func (s *Session) waitOn(fn func() (bool, error), timeout time.Duration) error {
+	start := time.Now()
+	next := s.poll.intervals()
+	tick := time.NewTimer(next())
+	defer tick.Stop()
 	to := time.NewTimer(timeout)
+	defer to.Stop()
+
+	polls := 0
+	var last error
+	poll := func() (bool, error) {
+		polls++
+		done, err := fn()
+		if r, ok := err.(*retryError); ok {
+			last = r.err
+			return false, nil
+		}
+		return done || err != nil, err
+	}
+	if !s.delayFirstPoll {
+		if done, err := poll(); done {
+			return err
+		}
+	}
 	for {
 		select {
-		case <-ticker.C:
-			if done, err := fn(); err != nil {
+		case <-tick.C:
+			if done, err := poll(); done {
 				return err
-			} else if done {
-				return nil
 			}
+			tick.Reset(next())
 
 		case <-to.C:
-			return errors.Wrapf(ErrWaitTimeout, string(debug.Stack()))
+			return &TimeoutError{Elapsed: time.Since(start), Polls: polls, LastErr: last}
 		}
 	}
-
-	return ErrUnknown
-}
-
-func serveSnap(img []byte) error {
-	fsMap := map[string][]byte{
-		"/snap.png": img,
-		"/index.html": []byte(`
-<!doctype html>
-<html>
-	<head>
-		<title>Selenium debug snapshot</title>
-		<link rel="icon" href="data:;base64,iVBORw0KGgo=">
-	</head>
-	<body>
-		<img src="snap.png" style="width:800px" alt="snap.png">
-	</body>
-</html>
-`),
-	}
-
-	var wg sync.WaitGroup
-	wg.Add(3)
-
-	fs, err := memfs.New(fsMap, map[string]func(path string){
-		"Close": func(path string) {
-			fmt.Printf("close %v\n", path)
-			wg.Done()
-		},
-	})
-	if err != nil {
-		return err
-	}
-
-	port, err := freeport.GetFreePort()
-	if err != nil {
-		return err
-	}
-
-	fmt.Printf("serving http://localhost:%v\n", port)
-
-	mux := http.NewServeMux()
-	mux.Handle("/", http.StripPrefix("/", http.FileServer(fs)))
-	srv := &http.Server{Addr: fmt.Sprintf(":%v", port), Handler: mux}
-
-	go func() {
-		srv.ListenAndServe()
-	}()
-
-	wg.Wait()
-	return srv.Shutdown(context.TODO())
 }
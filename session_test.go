@@ -0,0 +1,127 @@
+package webdriver
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseCountPredicate(t *testing.T) {
+	for _, tc := range []struct {
+		predicate string
+		count     int
+		want      bool
+	}{
+		{">= 10", 10, true},
+		{">=10", 9, false},
+		{"== 3", 3, true},
+		{"3", 4, false},
+		{"!= 0", 1, true},
+		{"> 2", 2, false},
+		{"< 2", 1, true},
+		{"<= 2", 3, false},
+	} {
+		match, err := parseCountPredicate(tc.predicate)
+		if err != nil {
+			t.Errorf("parseCountPredicate(%q) returned error: %v", tc.predicate, err)
+			continue
+		}
+		if got := match(tc.count); got != tc.want {
+			t.Errorf("parseCountPredicate(%q)(%d) = %v, want %v", tc.predicate, tc.count, got, tc.want)
+		}
+	}
+
+	for _, p := range []string{"", ">=", "about 3", "=> 3"} {
+		if _, err := parseCountPredicate(p); err == nil {
+			t.Errorf("parseCountPredicate(%q) returned no error", p)
+		}
+	}
+}
+
+func TestWaitOnPollsImmediately(t *testing.T) {
+	s := &Session{}
+	start := time.Now()
+	if err := s.waitOn(func() (bool, error) { return true, nil }, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("waitOn took %v for a condition met immediately", elapsed)
+	}
+
+	err := s.waitOn(func() (bool, error) { return false, retryWith(ErrNotFound) }, 10*time.Millisecond)
+	te, ok := err.(*TimeoutError)
+	if !ok || te.Polls != 1 || te.LastErr != ErrNotFound {
+		t.Errorf("waitOn = %#v, want TimeoutError after 1 poll with ErrNotFound", err)
+	}
+}
+
+func TestWaitElementSingleRoundTrip(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/status":
+			w.Write([]byte(`{"value": {"ready": true}}`))
+		case "/session/1/execute/sync":
+			w.Write([]byte(`{"value": [1, {"element-6066-11e4-a52e-4f735466cecf": "e1"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"value": {"error": "unknown command", "message": "unexpected"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	s := &Session{WebDriver: &remoteWD{urlPrefix: srv.URL, id: "1", w3cCompatible: true}, timeout: time.Second}
+	elem, idx, err := s.WaitElement("//a", "//b")
+	if err != nil {
+		t.Fatalf("WaitElement() returned error: %v", err)
+	}
+	if idx != 1 || elem.xpath != "//b" {
+		t.Errorf("WaitElement() = %q, %v, want //b, 1", elem.xpath, idx)
+	}
+	if id, _ := elem.WebElement.(*remoteWE); id == nil || id.id != "e1" {
+		t.Errorf("WaitElement() element = %v, want e1", elem.WebElement)
+	}
+	if want := []string{"/status", "/session/1/execute/sync"}; fmt.Sprint(paths) != fmt.Sprint(want) {
+		t.Errorf("requests = %v, want %v", paths, want)
+	}
+}
+
+func TestElementWaitElementRetriesStale(t *testing.T) {
+	executes := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		switch r.URL.Path {
+		case "/status":
+			w.Write([]byte(`{"value": {"ready": true}}`))
+		case "/session/1/execute/sync":
+			if executes++; executes == 1 {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"value": {"error": "stale element reference", "message": "stale element reference: element is not attached to the page document"}}`))
+				return
+			}
+			w.Write([]byte(`{"value": [0, {"element-6066-11e4-a52e-4f735466cecf": "e2"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"value": {"error": "unknown command", "message": "unexpected"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	wd := &remoteWD{urlPrefix: srv.URL, id: "1", w3cCompatible: true}
+	s := &Session{WebDriver: wd, timeout: time.Second, poll: &PollStrategy{}}
+	parent := &Element{s: s, WebElement: &remoteWE{parent: wd, id: "e1"}}
+	elem, idx, err := parent.WaitElement("//a")
+	if err != nil {
+		t.Fatalf("WaitElement() returned error: %v", err)
+	}
+	if id, _ := elem.WebElement.(*remoteWE); idx != 0 || id == nil || id.id != "e2" {
+		t.Errorf("WaitElement() = %v, %v, want e2, 0", elem.WebElement, idx)
+	}
+	if executes != 2 {
+		t.Errorf("WaitElement() ran %d scripts, want a retry after the stale element", executes)
+	}
+}
@@ -0,0 +1,97 @@
+package webdriver
+
+import "strings"
+
+// deepSeparator separates the CSS selectors of a deep selector.
+const deepSeparator = ">>>"
+
+// deepQueryFunc is a script function returning the elements matched by the
+// CSS selectors in parts, each searched in the shadow roots of the elements
+// matched by the previous one, starting under root or the document. An empty
+// first selector stands for root itself.
+const deepQueryFunc = `function deepQuery(root, parts) {
+	var scopes = [root || document];
+	for (var i = 0; i < parts.length; i++) {
+		if (i === 0 && parts[i] === "") {
+			continue;
+		}
+		var next = [];
+		for (var j = 0; j < scopes.length; j++) {
+			var scope = i === 0 ? scopes[j] : scopes[j].shadowRoot;
+			if (!scope) {
+				continue;
+			}
+			var found = scope.querySelectorAll(parts[i]);
+			for (var k = 0; k < found.length; k++) {
+				if (next.indexOf(found[k]) < 0) {
+					next.push(found[k]);
+				}
+			}
+		}
+		scopes = next;
+	}
+	return scopes;
+}`
+
+// IsDeepSelector reports whether sel is a deep selector piercing shadow roots,
+// e.g. "app-root >>> settings-page >>> #save". Each ">>>" separates CSS
+// selectors, each matched inside the open shadow roots of the elements matched
+// by the selector before it. Deep selectors can be passed wherever an xpath is
+// expected by GetDOM, GetDOMs, ClickDOM, Wait and WaitElement of sessions and
+// elements, and they wait the same way. An element's deep selector starting
+// with ">>>" searches the element's own shadow root.
+func IsDeepSelector(sel string) bool {
+	return strings.Contains(sel, deepSeparator)
+}
+
+func deepParts(sel string) []string {
+	parts := strings.Split(sel, deepSeparator)
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+// deepQuery returns the elements matched by a deep selector under root, or the
+// document if root is nil.
+func (s *Session) deepQuery(root WebElement, sel string) ([]WebElement, error) {
+	data, err := s.ExecuteScriptRaw(deepQueryFunc+"\nreturn deepQuery(arguments[0], arguments[1]);", []interface{}{root, deepParts(sel)})
+	if err != nil {
+		return nil, err
+	}
+	return s.DecodeElements(data)
+}
+
+// findDeep is find for deep selectors. The element is relocated with the
+// selector as long as it is the first match.
+func (s *Session) findDeep(parent *Element, sel string) (*Element, error) {
+	elems, err := s.findDeepN(parent, sel)
+	if err != nil {
+		return nil, err
+	}
+	elems[0].xpath = sel
+	return elems[0], nil
+}
+
+// findDeepN is findN for deep selectors. The elements cannot be relocated.
+func (s *Session) findDeepN(parent *Element, sel string) ([]*Element, error) {
+	var root WebElement
+	if parent != nil {
+		root = parent.WebElement
+	}
+	wes, err := s.deepQuery(root, sel)
+	if err != nil {
+		return nil, err
+	} else if len(wes) == 0 {
+		return nil, ErrNotFound
+	}
+
+	ret := make([]*Element, len(wes))
+	for i, we := range wes {
+		ret[i] = &Element{s: s, WebElement: we, parent: parent}
+		if parent != nil {
+			ret[i].frame, ret[i].framed = parent.frame, parent.framed
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,22 @@
+package webdriver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDeepParts(t *testing.T) {
+	if IsDeepSelector("//div[@id='a']") {
+		t.Errorf("xpath taken for a deep selector")
+	}
+	sel := "app-root >>> settings-page>>>#save"
+	if !IsDeepSelector(sel) {
+		t.Fatalf("%q not taken for a deep selector", sel)
+	}
+	if got, want := deepParts(sel), []string{"app-root", "settings-page", "#save"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("deepParts(%q) = %q, want %q", sel, got, want)
+	}
+	if got, want := deepParts(">>> button"), []string{"", "button"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("deepParts = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,186 @@
+package webdriver
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Endpoint is a remote WebDriver server, e.g. a Selenium Grid node or an
+// Appium server attached to a device.
+type Endpoint struct {
+	// URL is the WebDriver prefix of the server, e.g.
+	// "http://10.0.0.5:4444/wd/hub".
+	URL string
+	// Weight is the relative capacity of the server. It defaults to 1.
+	Weight int
+}
+
+// ShardOptions configures WithShards.
+type ShardOptions struct {
+	Endpoints []Endpoint
+	// HealthInterval is the period between health checks of the endpoints.
+	// It defaults to 30 seconds.
+	HealthInterval time.Duration
+}
+
+// WithShards makes Init distribute the sessions created by New across remote
+// endpoints instead of starting a local driver. Each session goes to the
+// healthy endpoint with the fewest sessions relative to its weight; an
+// endpoint failing to create a session is marked unhealthy until its next
+// successful health check. SessionInfo.Endpoint reports the endpoint serving
+// each session.
+//
+// Note that paths passed to New, such as the profile directory, refer to the
+// filesystem of the endpoint.
+func WithShards(opts ShardOptions) InitOption {
+	return func(c *initConfig) {
+		c.shards = &opts
+	}
+}
+
+// shardEndpoint is the scheduling state of an endpoint.
+type shardEndpoint struct {
+	Endpoint
+	sch *scheduler
+
+	// Guarded by sch.mu.
+	active  int
+	healthy bool
+}
+
+// release accounts for the end of a session on the endpoint. It is a no-op
+// for a nil endpoint.
+func (e *shardEndpoint) release() {
+	if e == nil {
+		return
+	}
+	e.sch.mu.Lock()
+	defer e.sch.mu.Unlock()
+	if e.active > 0 {
+		e.active--
+	}
+}
+
+type scheduler struct {
+	mu        sync.Mutex
+	endpoints []*shardEndpoint
+	done      chan struct{}
+	once      sync.Once
+}
+
+func newScheduler(opts ShardOptions) (*scheduler, error) {
+	if len(opts.Endpoints) == 0 {
+		return nil, fmt.Errorf("no shard endpoints")
+	}
+
+	sch := &scheduler{done: make(chan struct{})}
+	for _, ep := range opts.Endpoints {
+		if ep.Weight <= 0 {
+			ep.Weight = 1
+		}
+		sch.endpoints = append(sch.endpoints, &shardEndpoint{Endpoint: ep, sch: sch})
+	}
+	sch.check()
+
+	interval := opts.HealthInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				sch.check()
+			case <-sch.done:
+				return
+			}
+		}
+	}()
+
+	return sch, nil
+}
+
+func (sch *scheduler) stop() {
+	sch.once.Do(func() { close(sch.done) })
+}
+
+// check updates the health of the endpoints.
+func (sch *scheduler) check() {
+	var wg sync.WaitGroup
+	healthy := make([]bool, len(sch.endpoints))
+	for i, ep := range sch.endpoints {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			healthy[i] = statusWith(HTTPClient, url) == http.StatusOK
+		}(i, ep.URL)
+	}
+	wg.Wait()
+
+	sch.mu.Lock()
+	defer sch.mu.Unlock()
+	for i, ep := range sch.endpoints {
+		if ep.healthy != healthy[i] {
+			debugLog("shard endpoint %v healthy: %v", ep.URL, healthy[i])
+		}
+		ep.healthy = healthy[i]
+	}
+}
+
+// pick reserves a slot on the healthy endpoint with the lowest load relative
+// to its weight, skipping the endpoints in tried.
+func (sch *scheduler) pick(tried map[*shardEndpoint]bool) *shardEndpoint {
+	sch.mu.Lock()
+	defer sch.mu.Unlock()
+
+	var best *shardEndpoint
+	for _, ep := range sch.endpoints {
+		if !ep.healthy || tried[ep] {
+			continue
+		}
+		// Compare active/weight without dividing.
+		if best == nil || ep.active*best.Weight < best.active*ep.Weight {
+			best = ep
+		}
+	}
+	if best != nil {
+		best.active++
+	}
+	return best
+}
+
+// newRemote creates a session on the best endpoint, falling back to the
+// others if it fails.
+func (sch *scheduler) newRemote(caps Capabilities) (WebDriver, *shardEndpoint, error) {
+	tried := map[*shardEndpoint]bool{}
+	var lastErr error
+	for {
+		ep := sch.pick(tried)
+		if ep == nil {
+			if lastErr == nil {
+				lastErr = fmt.Errorf("no healthy shard endpoint")
+			}
+			return nil, nil, lastErr
+		}
+		tried[ep] = true
+
+		wd, err := NewRemote(caps, ep.URL)
+		if err == nil {
+			return wd, ep, nil
+		}
+		debugLog("error creating session on shard endpoint %v: %v", ep.URL, err)
+		lastErr = err
+
+		sch.mu.Lock()
+		ep.active--
+		// An error reply means the endpoint is up but rejected the session.
+		if _, ok := err.(*Error); !ok {
+			ep.healthy = false
+		}
+		sch.mu.Unlock()
+	}
+}
@@ -0,0 +1,24 @@
+package webdriver
+
+import "testing"
+
+func TestSchedulerPick(t *testing.T) {
+	sch := &scheduler{}
+	a := &shardEndpoint{Endpoint: Endpoint{URL: "a", Weight: 1}, sch: sch, healthy: true}
+	b := &shardEndpoint{Endpoint: Endpoint{URL: "b", Weight: 3}, sch: sch, healthy: true}
+	c := &shardEndpoint{Endpoint: Endpoint{URL: "c", Weight: 5}, sch: sch}
+	sch.endpoints = []*shardEndpoint{a, b, c}
+
+	counts := map[string]int{}
+	for i := 0; i < 8; i++ {
+		counts[sch.pick(nil).URL]++
+	}
+	if counts["a"] != 2 || counts["b"] != 6 || counts["c"] != 0 {
+		t.Errorf("pick() distribution = %v, want a:2 b:6 c:0", counts)
+	}
+
+	b.release()
+	if got := sch.pick(map[*shardEndpoint]bool{a: true}); got != b {
+		t.Errorf("pick() skipping a = %v, want b", got)
+	}
+}
@@ -0,0 +1,117 @@
+package webdriver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// shortcutKeys maps the names of keys in shortcuts to keys.
+var shortcutKeys = map[string]string{
+	"ctrl": ControlKey, "control": ControlKey,
+	"shift": ShiftKey,
+	"alt":   AltKey, "option": AltKey,
+	"meta": MetaKey, "cmd": MetaKey, "command": MetaKey, "super": MetaKey, "win": MetaKey,
+	"enter": EnterKey, "return": ReturnKey,
+	"tab": TabKey,
+	"esc": EscapeKey, "escape": EscapeKey,
+	"space":     SpaceKey,
+	"backspace": BackspaceKey,
+	"delete":    DeleteKey, "del": DeleteKey,
+	"insert": InsertKey, "ins": InsertKey,
+	"home": HomeKey, "end": EndKey,
+	"pageup": PageUpKey, "pgup": PageUpKey,
+	"pagedown": PageDownKey, "pgdn": PageDownKey,
+	"up": UpArrowKey, "arrowup": UpArrowKey,
+	"down": DownArrowKey, "arrowdown": DownArrowKey,
+	"left": LeftArrowKey, "arrowleft": LeftArrowKey,
+	"right": RightArrowKey, "arrowright": RightArrowKey,
+	"plus": "+",
+	"f1":   F1Key, "f2": F2Key, "f3": F3Key, "f4": F4Key, "f5": F5Key, "f6": F6Key,
+	"f7": F7Key, "f8": F8Key, "f9": F9Key, "f10": F10Key, "f11": F11Key, "f12": F12Key,
+}
+
+// ParseShortcut parses a keyboard shortcut such as "ctrl+shift+p", "cmd+k",
+// "alt+f4" or "shift+tab" into the keys to press in order. Names are case
+// insensitive; "plus" stands for the + key. The pseudo modifier "mod" is
+// returned as is, see Shortcut.
+func ParseShortcut(combo string) ([]string, error) {
+	parts := strings.Split(combo, "+")
+	// "ctrl++" presses the + key.
+	if len(parts) > 1 && parts[len(parts)-1] == "" && parts[len(parts)-2] == "" {
+		parts = append(parts[:len(parts)-2], "plus")
+	}
+
+	var keys []string
+	for _, p := range parts {
+		name := strings.ToLower(strings.TrimSpace(p))
+		switch {
+		case name == "mod":
+			keys = append(keys, name)
+		case shortcutKeys[name] != "":
+			keys = append(keys, shortcutKeys[name])
+		case len([]rune(name)) == 1:
+			keys = append(keys, name)
+		default:
+			return nil, fmt.Errorf("unknown key %q in shortcut %q", p, combo)
+		}
+	}
+	return keys, nil
+}
+
+// focusScript focuses the element in arguments[0], or blurs the active element
+// if it is null, and returns the modifier that "mod" stands for.
+const focusScript = `var el = arguments[0];
+window.focus();
+if (el) {
+	el.focus();
+} else if (document.activeElement && document.activeElement !== document.body) {
+	document.activeElement.blur();
+}
+return /Mac|iP(hone|ad|od)/.test(navigator.platform) ? "meta" : "control";`
+
+// Shortcut presses a keyboard shortcut, see ParseShortcut, as keyboard-driven
+// apps such as editors and consoles expect: the modifiers are held down in
+// order while the last key is pressed. It focuses target first, or, without
+// target, the document body, so that the shortcut reaches the page instead
+// of the input focused last. The modifier "mod" is ctrl, or cmd on macOS, as
+// the page sees the platform.
+func (s *Session) Shortcut(combo string, target ...*Element) error {
+	keys, err := ParseShortcut(combo)
+	if err != nil {
+		return err
+	}
+
+	var ret interface{}
+	if len(target) > 0 && target[0] != nil {
+		err = target[0].inFrame(func() (err error) {
+			ret, err = s.ExecuteScript(focusScript, []interface{}{target[0].WebElement})
+			return err
+		})
+	} else {
+		ret, err = s.ExecuteScript(focusScript, []interface{}{nil})
+	}
+	if err != nil {
+		return s.fail("Shortcut", combo, err)
+	}
+	for i, k := range keys {
+		if k != "mod" {
+			continue
+		}
+		keys[i] = ControlKey
+		if ret == "meta" {
+			keys[i] = MetaKey
+		}
+	}
+
+	up := make([]string, len(keys))
+	for i, k := range keys {
+		up[len(keys)-1-i] = k
+	}
+	// The keys are released even if pressing them failed partway, so that no
+	// modifier stays held for the rest of the session.
+	err = s.KeyDown(strings.Join(keys, ""))
+	if upErr := s.KeyUp(strings.Join(up, "")); err == nil {
+		err = upErr
+	}
+	return s.fail("Shortcut", combo, err)
+}
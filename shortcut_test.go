@@ -0,0 +1,60 @@
+package webdriver
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseShortcut(t *testing.T) {
+	for combo, want := range map[string][]string{
+		"ctrl+shift+p": {ControlKey, ShiftKey, "p"},
+		"Cmd+K":        {MetaKey, "k"},
+		"alt + F4":     {AltKey, F4Key},
+		"ctrl++":       {ControlKey, "+"},
+		"mod+s":        {"mod", "s"},
+		"esc":          {EscapeKey},
+	} {
+		got, err := ParseShortcut(combo)
+		if err != nil {
+			t.Errorf("ParseShortcut(%q): %v", combo, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseShortcut(%q) = %q, want %q", combo, got, want)
+		}
+	}
+	if _, err := ParseShortcut("ctrl+hyper"); err == nil {
+		t.Errorf("ParseShortcut accepted an unknown key")
+	}
+}
+
+// keysWD fails to press keys and records the keys released.
+type keysWD struct {
+	WebDriver
+	released string
+}
+
+func (wd *keysWD) ExecuteScript(script string, args []interface{}) (interface{}, error) {
+	return "control", nil
+}
+
+func (wd *keysWD) KeyDown(keys string) error {
+	return errors.New("key down failed")
+}
+
+func (wd *keysWD) KeyUp(keys string) error {
+	wd.released = keys
+	return nil
+}
+
+func TestShortcutReleasesKeys(t *testing.T) {
+	wd := &keysWD{}
+	s := &Session{WebDriver: wd}
+	if err := s.Shortcut("mod+shift+k"); err == nil {
+		t.Fatal("Shortcut returned no error for a failed key down")
+	}
+	if want := "k" + ShiftKey + ControlKey; wd.released != want {
+		t.Errorf("released %q, want %q", wd.released, want)
+	}
+}
@@ -0,0 +1,54 @@
+package webdriver
+
+import "sync"
+
+var (
+	shutdownMu    sync.Mutex
+	shutdownHooks []*shutdownHook
+)
+
+type shutdownHook struct {
+	fn func()
+}
+
+// WithoutSignalHandler keeps Init from installing its SIGINT and SIGTERM
+// handler, which calls Shutdown and exits the program. Programs with their
+// own graceful shutdown call Shutdown themselves instead.
+func WithoutSignalHandler() InitOption {
+	return func(c *initConfig) {
+		c.noSignalHandler = true
+	}
+}
+
+// RegisterShutdownHook registers fn to be called by Shutdown before the
+// sessions are closed, e.g. to save their state. Hooks run in the reverse
+// order of their registration. The returned function unregisters fn.
+func RegisterShutdownHook(fn func()) (unregister func()) {
+	h := &shutdownHook{fn}
+	shutdownMu.Lock()
+	shutdownHooks = append(shutdownHooks, h)
+	shutdownMu.Unlock()
+
+	return func() {
+		shutdownMu.Lock()
+		defer shutdownMu.Unlock()
+		for i, o := range shutdownHooks {
+			if o == h {
+				shutdownHooks = append(shutdownHooks[:i], shutdownHooks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// runShutdownHooks runs the registered hooks, outside of any lock so that
+// they can use the sessions.
+func runShutdownHooks() {
+	shutdownMu.Lock()
+	hooks := append([]*shutdownHook(nil), shutdownHooks...)
+	shutdownMu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i].fn()
+	}
+}
@@ -0,0 +1,26 @@
+package webdriver
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestShutdownHooks(t *testing.T) {
+	var order []int
+	var unregister []func()
+	for i := 1; i <= 3; i++ {
+		i := i
+		unregister = append(unregister, RegisterShutdownHook(func() { order = append(order, i) }))
+	}
+	defer func() {
+		for _, f := range unregister {
+			f()
+		}
+	}()
+	unregister[1]()
+
+	runShutdownHooks()
+	if got, want := fmt.Sprint(order), "[3 1]"; got != want {
+		t.Errorf("hooks ran in order %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,287 @@
+package webdriver
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SnapshotSink stores the snapshots and failure artifacts of a session, see
+// WithSnapshotSink.
+type SnapshotSink interface {
+	// Put stores data under name, a slash-separated relative path, and
+	// returns its location.
+	Put(name string, data []byte) (string, error)
+}
+
+// WithSnapshotSink makes Snap store the screenshots in sink instead of
+// serving them, and the failure artifacts of WithFailureArtifacts be stored
+// in sink too. Failure artifacts are captured with a sink alone, without
+// WithFailureArtifacts.
+func WithSnapshotSink(sink SnapshotSink) Option {
+	return func(c *sessionConfig) {
+		c.sink = sink
+	}
+}
+
+func contentType(name string) string {
+	if t := mime.TypeByExtension(path.Ext(name)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// DirSink is a SnapshotSink writing to a local directory, e.g. a volume
+// mounted in the container.
+type DirSink string
+
+// Put writes data to the file name below the directory.
+func (d DirSink) Put(name string, data []byte) (string, error) {
+	file := filepath.Join(string(d), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return "", err
+	}
+	return file, ioutil.WriteFile(file, data, 0644)
+}
+
+// S3Sink is a SnapshotSink uploading to an Amazon S3 bucket, or to an S3
+// compatible storage through Endpoint.
+type S3Sink struct {
+	Bucket string
+	// Region is the region of the bucket. It defaults to the AWS_REGION
+	// environment variable.
+	Region string
+	// Prefix is prepended to the object names.
+	Prefix string
+	// AccessKeyID, SecretAccessKey and SessionToken authenticate the
+	// uploads. They default to the AWS_ACCESS_KEY_ID,
+	// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment variables.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Endpoint is the base URL of an S3 compatible storage, e.g.
+	// "http://minio:9000", addressed in path style.
+	Endpoint string
+	// Client is the HTTP client of the uploads. It defaults to
+	// http.DefaultClient.
+	Client *http.Client
+}
+
+// Put uploads data to the object Prefix+name and returns its s3:// URL.
+func (s *S3Sink) Put(name string, data []byte) (string, error) {
+	region := s.Region
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+	akid, secret, token := s.AccessKeyID, s.SecretAccessKey, s.SessionToken
+	if akid == "" {
+		akid, secret, token = os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"), os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if akid == "" || secret == "" {
+		return "", fmt.Errorf("missing S3 credentials")
+	}
+
+	key := s.Prefix + name
+	u := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.Bucket, region, awsURIEncode(key))
+	if s.Endpoint != "" {
+		u = fmt.Sprintf("%s/%s/%s", strings.TrimRight(s.Endpoint, "/"), s.Bucket, awsURIEncode(key))
+	}
+	req, err := http.NewRequest(http.MethodPut, u, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType(name))
+	if token != "" {
+		req.Header.Set("X-Amz-Security-Token", token)
+	}
+	sum := sha256.Sum256(data)
+	signV4(req, hex.EncodeToString(sum[:]), region, "s3", akid, secret, time.Now())
+
+	if err := doUpload(s.Client, req); err != nil {
+		return "", err
+	}
+	return "s3://" + s.Bucket + "/" + key, nil
+}
+
+// awsURIEncode escapes the segments of a path as required by AWS signatures.
+func awsURIEncode(p string) string {
+	segs := strings.Split(p, "/")
+	for i, seg := range segs {
+		var b strings.Builder
+		for _, c := range []byte(seg) {
+			if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~' {
+				b.WriteByte(c)
+			} else {
+				fmt.Fprintf(&b, "%%%02X", c)
+			}
+		}
+		segs[i] = b.String()
+	}
+	return strings.Join(segs, "/")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// signV4 signs req, which has no query string, with AWS Signature Version 4,
+// covering the host and all the headers set on req.
+func signV4(req *http.Request, payloadHash, region, service, akid, secret string, t time.Time) {
+	t = t.UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonHeaders strings.Builder
+	for _, k := range names {
+		canonHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signed := strings.Join(names, ";")
+
+	canonURI := req.URL.EscapedPath()
+	if canonURI == "" {
+		canonURI = "/"
+	}
+	canonReq := strings.Join([]string{req.Method, canonURI, "", canonHeaders.String(), signed, payloadHash}, "\n")
+	reqHash := sha256.Sum256([]byte(canonReq))
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(reqHash[:])
+	key := hmacSHA256([]byte("AWS4"+secret), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	sig := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", akid, scope, signed, sig))
+}
+
+// GCSSink is a SnapshotSink uploading to a Google Cloud Storage bucket.
+type GCSSink struct {
+	Bucket string
+	// Prefix is prepended to the object names.
+	Prefix string
+	// Token is an OAuth2 access token with write access to the bucket. It
+	// defaults to the GOOGLE_OAUTH_ACCESS_TOKEN environment variable, then
+	// to the token of the default service account, from the metadata
+	// server of GCE, GKE or Cloud Run.
+	Token string
+	// Client is the HTTP client of the uploads. It defaults to
+	// http.DefaultClient.
+	Client *http.Client
+
+	mu      sync.Mutex
+	meta    string
+	expires time.Time
+}
+
+// token returns the access token of the uploads.
+func (s *GCSSink) token() (string, error) {
+	if s.Token != "" {
+		return s.Token, nil
+	}
+	if t := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); t != "" {
+		return t, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.meta != "" && time.Now().Before(s.expires) {
+		return s.meta, nil
+	}
+	req, err := http.NewRequest(http.MethodGet, "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("no GCS token: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("no GCS token: metadata server returned %v", resp.Status)
+	}
+	tok := struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", err
+	}
+	s.meta = tok.AccessToken
+	// Refresh a minute ahead of the expiry.
+	s.expires = time.Now().Add(time.Duration(tok.ExpiresIn)*time.Second - time.Minute)
+	return s.meta, nil
+}
+
+// Put uploads data to the object Prefix+name and returns its gs:// URL.
+func (s *GCSSink) Put(name string, data []byte) (string, error) {
+	token, err := s.token()
+	if err != nil {
+		return "", err
+	}
+
+	object := s.Prefix + name
+	u := fmt.Sprintf("https://storage.googleapis.com/upload/storage/v1/b/%s/o?uploadType=media&name=%s", url.PathEscape(s.Bucket), url.QueryEscape(object))
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType(name))
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	if err := doUpload(s.Client, req); err != nil {
+		return "", err
+	}
+	return "gs://" + s.Bucket + "/" + object, nil
+}
+
+func doUpload(client *http.Client, req *http.Request) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("upload to %v failed: %v: %s", req.URL.Host, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
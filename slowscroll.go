@@ -0,0 +1,107 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// slowScrollSettle bounds the wait for the content revealed by a scroll step
+// to settle.
+const slowScrollSettle = 5 * time.Second
+
+// scrollStateScript returns the height of the document, the number of images
+// in the viewport still loading, the scroll position and whether the viewport
+// is at the bottom.
+const scrollStateScript = `var h = document.documentElement.scrollHeight, pending = 0;
+for (var i = 0; i < document.images.length; i++) {
+	var img = document.images[i];
+	if (!img.complete) {
+		var r = img.getBoundingClientRect();
+		if (r.bottom > 0 && r.top < window.innerHeight) {
+			pending++;
+		}
+	}
+}
+return {height: h, pending: pending, y: window.scrollY, bottom: window.scrollY + window.innerHeight >= h - 1};`
+
+type scrollState struct {
+	Height  int     `json:"height"`
+	Pending int     `json:"pending"`
+	Y       float64 `json:"y"`
+	Bottom  bool    `json:"bottom"`
+}
+
+func (s *Session) scrollState() (*scrollState, error) {
+	data, err := s.ExecuteScriptRaw(scrollStateScript, nil)
+	if err != nil {
+		return nil, err
+	}
+	reply := struct{ Value scrollState }{}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return nil, err
+	}
+	return &reply.Value, nil
+}
+
+// SlowScroll scrolls the page down step pixels at a time, as a reader would,
+// so that content loaded lazily when it enters the viewport, e.g. by
+// IntersectionObserver or loading="lazy", loads before full-page screenshots
+// or extraction. After each step it waits delay, then until the images in the
+// viewport have loaded and the document stopped growing, for up to 5s. It
+// stops once until, which may be nil, holds, or at the bottom of the page once
+// nothing loads anymore; pages growing endlessly need an until. It also stops
+// when a step does not move the page, e.g. while a dialog locks scrolling or
+// the content scrolls in an inner container, and fails with a *TimeoutError
+// after the timeout of the session.
+func (s *Session) SlowScroll(step int, delay time.Duration, until WaitCond) error {
+	if step <= 0 {
+		step = 400
+	}
+	start := time.Now()
+	lastY := -1.0
+	for steps := 0; ; steps++ {
+		if s.timeout > 0 && time.Since(start) > s.timeout {
+			return &TimeoutError{Op: "SlowScroll", Elapsed: time.Since(start), Polls: steps}
+		}
+		if _, err := s.ExecuteScript("window.scrollBy(0, arguments[0]);", []interface{}{step}); err != nil {
+			return err
+		}
+		time.Sleep(delay)
+
+		state, err := s.settleScroll()
+		if err != nil {
+			return err
+		}
+		if until != nil {
+			if done, err := until.eval(s); err != nil || done {
+				return err
+			}
+		}
+		if state.Bottom || state.Y == lastY {
+			return nil
+		}
+		lastY = state.Y
+	}
+}
+
+// settleScroll waits until the images in the viewport loaded and the height
+// of the document is stable, and returns the state then.
+func (s *Session) settleScroll() (*scrollState, error) {
+	deadline := time.Now().Add(slowScrollSettle)
+	prev, err := s.scrollState()
+	if err != nil {
+		return nil, err
+	}
+	for time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+		state, err := s.scrollState()
+		if err != nil {
+			return nil, err
+		}
+		if state.Pending == 0 && state.Height == prev.Height {
+			return state, nil
+		}
+		prev = state
+	}
+	return prev, nil
+}
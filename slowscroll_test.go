@@ -0,0 +1,37 @@
+package webdriver
+
+import (
+	"testing"
+	"time"
+)
+
+// lockedScrollWD is a page whose scrolling is locked.
+type lockedScrollWD struct {
+	WebDriver
+	steps int
+}
+
+func (wd *lockedScrollWD) ExecuteScript(script string, args []interface{}) (interface{}, error) {
+	wd.steps++
+	return nil, nil
+}
+
+func (wd *lockedScrollWD) ExecuteScriptRaw(script string, args []interface{}) ([]byte, error) {
+	return []byte(`{"value": {"height": 5000, "pending": 0, "y": 120, "bottom": false}}`), nil
+}
+
+func TestSlowScrollStops(t *testing.T) {
+	wd := &lockedScrollWD{}
+	s := &Session{WebDriver: wd, timeout: 10 * time.Second}
+	if err := s.SlowScroll(400, 0, nil); err != nil {
+		t.Fatal(err)
+	}
+	if wd.steps != 2 {
+		t.Errorf("scrolled %d steps, want 2", wd.steps)
+	}
+
+	s.timeout = time.Nanosecond
+	if _, ok := s.SlowScroll(400, 0, nil).(*TimeoutError); !ok {
+		t.Errorf("SlowScroll past the session timeout did not time out")
+	}
+}
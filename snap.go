@@ -0,0 +1,395 @@
+package webdriver
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iamjinlei/memfs"
+)
+
+// SnapOptions configures the debug server of Session.SnapWith.
+type SnapOptions struct {
+	// Addr is the address the server listens on. It defaults to
+	// "127.0.0.1:0", a free port of the loopback interface. Use
+	// "0.0.0.0:0" to reach the server from other hosts, e.g. out of a
+	// container.
+	Addr string
+	// CertFile and KeyFile serve the snapshot over TLS.
+	CertFile, KeyFile string
+	// Expiry is how long the snapshots are served after the last one. It
+	// defaults to 10 minutes.
+	Expiry time.Duration
+	// Background makes SnapWith return once the server is up, rather than
+	// once the snapshot has been viewed or has expired.
+	Background bool
+}
+
+// DefaultSnapExpiry is the time a snapshot is served when
+// SnapOptions.Expiry is left unset.
+const DefaultSnapExpiry = 10 * time.Minute
+
+// Snap serves a screenshot of the browser on localhost, as SnapWith with the
+// default options.
+func (s *Session) Snap() error {
+	return s.SnapWith(SnapOptions{})
+}
+
+// SnapWith takes a screenshot of the browser and adds it to the snapshot
+// gallery of the session, a debug HTTP server listing the snapshots taken so
+// far with their time, URL and caller, so that the sequence of states leading
+// to a failure can be reviewed. The gallery is served under a random token,
+// so that only the holder of the printed URL can view it. It starts with the
+// first snapshot, using its options, and stops with the session or once
+// opts.Expiry has elapsed since the last snapshot.
+//
+// SnapWith blocks until the screenshot has been viewed or the gallery
+// expires, unless opts.Background is set.
+//
+// If the session has a SnapshotSink, the screenshot is stored there instead
+// and opts is ignored.
+func (s *Session) SnapWith(opts SnapOptions) error {
+	img, err := s.Screenshot()
+	if err != nil {
+		return err
+	}
+	return s.snap(img, opts)
+}
+
+// SnapAnnotated is Snap with labeled boxes drawn around the given elements,
+// keyed by label, to check what selectors matched. The boxes are overlaid on
+// the page for the duration of the screenshot.
+func (s *Session) SnapAnnotated(elements map[string]*Element) error {
+	img, err := s.annotatedScreenshot(elements)
+	if err != nil {
+		return err
+	}
+	return s.snap(img, SnapOptions{})
+}
+
+// annotationColors are the colors of the boxes drawn by SnapAnnotated.
+var annotationColors = []string{"#e6194b", "#3cb44b", "#4363d8", "#f58231", "#911eb4", "#008080", "#f032e6", "#9a6324"}
+
+func (s *Session) annotatedScreenshot(elements map[string]*Element) ([]byte, error) {
+	labels := make([]string, 0, len(elements))
+	for label := range elements {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	var args []interface{}
+	for i, label := range labels {
+		args = append(args, map[string]interface{}{
+			"label": label,
+			"color": annotationColors[i%len(annotationColors)],
+			"elem":  elements[label].WebElement,
+		})
+	}
+	if _, err := s.ExecuteScript(`var root = document.createElement("div");
+root.id = "__webdriverAnnotations";
+root.style.cssText = "position:fixed;left:0;top:0;width:0;height:0;z-index:2147483647;pointer-events:none";
+arguments[0].forEach(function(a) {
+	var r = a.elem.getBoundingClientRect();
+	var box = document.createElement("div");
+	box.style.cssText = "position:fixed;box-sizing:border-box;border:2px solid " + a.color + ";left:" + r.left + "px;top:" + r.top + "px;width:" + r.width + "px;height:" + r.height + "px";
+	var label = document.createElement("span");
+	label.textContent = a.label;
+	label.style.cssText = "position:absolute;left:-2px;" + (r.top < 16 ? "top:100%" : "bottom:100%") + ";background:" + a.color + ";color:#fff;font:12px/14px monospace;padding:1px 3px;white-space:nowrap";
+	box.appendChild(label);
+	root.appendChild(box);
+});
+document.documentElement.appendChild(root);`, []interface{}{args}); err != nil {
+		return nil, err
+	}
+
+	img, err := s.Screenshot()
+	if _, rmErr := s.ExecuteScript(`var root = document.getElementById("__webdriverAnnotations");
+if (root) {
+	root.remove();
+}`, nil); err == nil {
+		err = rmErr
+	}
+	return img, err
+}
+
+// snap stores the screenshot in the sink of the session, or adds it to the
+// snapshot gallery.
+func (s *Session) snap(img []byte, opts SnapOptions) error {
+	if s.sink != nil {
+		loc, err := s.sink.Put("snapshots/"+time.Now().Format("20060102-150405.000")+".png", img)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("snapshot %s\n", loc)
+		return nil
+	}
+	u, _ := s.CurrentURL()
+
+	s.mu.Lock()
+	g := s.gallery
+	if g == nil || g.isDone() {
+		var err error
+		if g, err = startSnapGallery(opts); err != nil {
+			s.mu.Unlock()
+			return err
+		}
+		s.gallery = g
+	}
+	s.mu.Unlock()
+
+	snap, err := g.add(img, u, snapCaller())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("serving %s#snap-%d\n", g.url, snap.n)
+	if opts.Background {
+		return nil
+	}
+
+	select {
+	case <-snap.viewed:
+	case <-g.done:
+	}
+	return nil
+}
+
+// closeGallery stops the snapshot gallery of the session, if any.
+func (s *Session) closeGallery() {
+	s.mu.Lock()
+	g := s.gallery
+	s.gallery = nil
+	s.mu.Unlock()
+	if g != nil {
+		g.close()
+	}
+}
+
+// snapToken returns a random URL path element.
+func snapToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+var pkgPath = reflect.TypeOf(Session{}).PkgPath()
+
+// snapCaller returns the location of the first caller outside of the
+// package.
+func snapCaller() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, pkgPath+".") {
+			return fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+type snapshot struct {
+	n      int
+	time   time.Time
+	url    string
+	caller string
+	file   string
+	viewed chan struct{}
+	once   sync.Once
+}
+
+// snapGallery is the debug server of the snapshots of a session.
+type snapGallery struct {
+	url    string
+	token  string
+	srv    *http.Server
+	expiry time.Duration
+	timer  *time.Timer
+	done   chan struct{}
+	once   sync.Once
+
+	mu    sync.Mutex
+	snaps []*snapshot
+	files map[string][]byte
+	fs    http.FileSystem
+}
+
+var galleryTmpl = template.Must(template.New("index").Parse(`<!doctype html>
+<html>
+	<head>
+		<title>Selenium debug snapshots</title>
+		<link rel="icon" href="data:;base64,iVBORw0KGgo=">
+	</head>
+	<body>
+		{{range .}}
+		<div id="snap-{{.N}}">
+			<h3>#{{.N}} {{.Time.Format "15:04:05.000"}}</h3>
+			<p><a href="{{.URL}}">{{.URL}}</a><br><code>{{.Caller}}</code></p>
+			<a href="{{.File}}"><img src="{{.File}}" style="width:800px" alt="{{.File}}"></a>
+		</div>
+		{{end}}
+	</body>
+</html>
+`))
+
+func startSnapGallery(opts SnapOptions) (*snapGallery, error) {
+	if opts.Addr == "" {
+		opts.Addr = "127.0.0.1:0"
+	}
+	if opts.Expiry <= 0 {
+		opts.Expiry = DefaultSnapExpiry
+	}
+	if (opts.CertFile == "") != (opts.KeyFile == "") {
+		return nil, fmt.Errorf("both CertFile and KeyFile are required for TLS")
+	}
+
+	token, err := snapToken()
+	if err != nil {
+		return nil, err
+	}
+
+	g := &snapGallery{
+		token:  token,
+		expiry: opts.Expiry,
+		done:   make(chan struct{}),
+		files:  map[string][]byte{},
+	}
+	if err := g.render(); err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/"+token+"/", http.StripPrefix("/"+token, http.HandlerFunc(g.serveHTTP)))
+	g.srv = &http.Server{Handler: mux}
+
+	scheme := "http"
+	if opts.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		g.srv.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+		scheme = "https"
+	}
+
+	ln, err := net.Listen("tcp", opts.Addr)
+	if err != nil {
+		return nil, err
+	}
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	if ip := net.ParseIP(host); ip == nil || ip.IsUnspecified() || ip.IsLoopback() {
+		host = "localhost"
+	}
+	g.url = fmt.Sprintf("%s://%s/%s/", scheme, net.JoinHostPort(host, port), token)
+
+	go func() {
+		var err error
+		if opts.CertFile != "" {
+			err = g.srv.ServeTLS(ln, "", "")
+		} else {
+			err = g.srv.Serve(ln)
+		}
+		if err != nil && err != http.ErrServerClosed {
+			debugLog("snapshot server failed: %v", err)
+		}
+	}()
+	g.timer = time.AfterFunc(g.expiry, g.close)
+	return g, nil
+}
+
+// render rebuilds the files of the gallery. It is called with g.mu held.
+func (g *snapGallery) render() error {
+	type row struct {
+		N      int
+		Time   time.Time
+		URL    string
+		Caller string
+		File   string
+	}
+	rows := make([]row, 0, len(g.snaps))
+	for _, s := range g.snaps {
+		rows = append(rows, row{s.n, s.time, s.url, s.caller, s.file})
+	}
+	var index strings.Builder
+	if err := galleryTmpl.Execute(&index, rows); err != nil {
+		return err
+	}
+	g.files["/index.html"] = []byte(index.String())
+
+	fs, err := memfs.New(g.files, nil)
+	if err != nil {
+		return err
+	}
+	g.fs = fs
+	return nil
+}
+
+func (g *snapGallery) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	fs := g.fs
+	var viewed *snapshot
+	for _, s := range g.snaps {
+		if r.URL.Path == "/"+s.file {
+			viewed = s
+		}
+	}
+	g.mu.Unlock()
+
+	http.FileServer(fs).ServeHTTP(w, r)
+	if viewed != nil {
+		viewed.once.Do(func() { close(viewed.viewed) })
+	}
+}
+
+func (g *snapGallery) add(img []byte, u, caller string) (*snapshot, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	n := len(g.snaps) + 1
+	s := &snapshot{
+		n:      n,
+		time:   time.Now(),
+		url:    u,
+		caller: caller,
+		file:   fmt.Sprintf("snap-%d.png", n),
+		viewed: make(chan struct{}),
+	}
+	g.snaps = append(g.snaps, s)
+	g.files["/"+s.file] = img
+	if err := g.render(); err != nil {
+		return nil, err
+	}
+	g.timer.Reset(g.expiry)
+	return s, nil
+}
+
+func (g *snapGallery) isDone() bool {
+	select {
+	case <-g.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (g *snapGallery) close() {
+	g.once.Do(func() {
+		g.timer.Stop()
+		close(g.done)
+		g.srv.Shutdown(context.TODO())
+	})
+}
@@ -0,0 +1,56 @@
+package webdriver
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapGallery(t *testing.T) {
+	g, err := startSnapGallery(SnapOptions{Expiry: time.Minute})
+	if err != nil {
+		t.Fatalf("startSnapGallery() returned error: %v", err)
+	}
+	defer g.close()
+
+	for _, u := range []string{"https://example.com/a", "https://example.com/b"} {
+		if _, err := g.add([]byte("png "+u), u, "main.go:1"); err != nil {
+			t.Fatalf("add() returned error: %v", err)
+		}
+	}
+
+	get := func(u string) (int, string) {
+		resp, err := http.Get(u)
+		if err != nil {
+			t.Fatalf("GET %v failed: %v", u, err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	code, index := get(g.url)
+	if code != http.StatusOK {
+		t.Fatalf("GET index returned %v", code)
+	}
+	for _, want := range []string{"https://example.com/a", "https://example.com/b", "snap-2.png", "main.go:1"} {
+		if !strings.Contains(index, want) {
+			t.Errorf("index does not contain %q", want)
+		}
+	}
+
+	if _, body := get(g.url + "snap-2.png"); body != "png https://example.com/b" {
+		t.Errorf("snap-2.png = %q", body)
+	}
+	select {
+	case <-g.snaps[1].viewed:
+	default:
+		t.Error("snapshot not marked as viewed")
+	}
+
+	if code, _ := get(strings.Replace(g.url, g.token, "guess", 1)); code != http.StatusNotFound {
+		t.Errorf("GET without token returned %v, want 404", code)
+	}
+}
@@ -0,0 +1,77 @@
+package webdriver
+
+import "fmt"
+
+// Relocate finds the element again using the locator it was found with,
+// replacing its stale reference. Ancestors with stale references are
+// relocated as well. Elements located by xpath remember it; others, e.g. from
+// FindElement, cannot relocate.
+func (e *Element) Relocate() error {
+	if e.xpath == "" {
+		return fmt.Errorf("element has no locator to relocate with")
+	}
+	var elem WebElement
+	find := func() (err error) {
+		elem, err = e.relocate()
+		return err
+	}
+
+	err := e.inFrame(find)
+	if e.parent != nil && StaleElement(err) {
+		if err := e.parent.Relocate(); err != nil {
+			return err
+		}
+		err = e.inFrame(find)
+	}
+	if notFound(err) {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	} else if elem == nil {
+		return ErrNotFound
+	}
+
+	e.WebElement = elem
+	return nil
+}
+
+// relocate finds the element again by its xpath, in the current frame.
+func (e *Element) relocate() (WebElement, error) {
+	if IsDeepSelector(e.xpath) {
+		var root WebElement
+		if e.parent != nil {
+			root = e.parent.WebElement
+		}
+		elems, err := e.s.deepQuery(root, e.xpath)
+		if err != nil || len(elems) == 0 {
+			return nil, err
+		}
+		return elems[0], nil
+	}
+	if e.parent == nil {
+		return e.s.FindElement(ByXPATH, e.xpath)
+	}
+	return e.parent.WebElement.FindElement(ByXPATH, e.xpath)
+}
+
+// NoStale works like Session.NoStale, retrying fn while it fails with a stale
+// element reference or ErrNeedRetry. Before each retry after a stale reference,
+// the element relocates itself, so fn, operating on e, sees the re-rendered
+// node. This makes iterating over elements of frequently re-rendered lists
+// robust without manual retry loops. Sessions created WithSelfHealing relocate
+// stale elements transparently in each Element method instead.
+func (e *Element) NoStale(fn func() error) error {
+	return e.s.waitOn(func() (bool, error) {
+		err := fn()
+		if err == ErrNeedRetry {
+			return false, nil
+		}
+		if StaleElement(err) {
+			if err := e.Relocate(); err != nil && err != ErrNotFound && !StaleElement(err) {
+				return true, err
+			}
+			return false, nil
+		}
+		return true, err
+	}, e.s.timeout)
+}
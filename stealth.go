@@ -0,0 +1,268 @@
+package webdriver
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// StealthOptions configures WithStealth.
+type StealthOptions struct {
+	// CanvasNoise perturbs the pixels read back from canvas and WebGL
+	// contexts (toDataURL, toBlob, getImageData and readPixels), so that
+	// the rendering fingerprint differs from stock headless Chrome. The
+	// noise is deterministic for a seed: repeated reads return the same
+	// pixels, as on a real device.
+	CanvasNoise bool
+	// Seed selects the noise. It defaults to a hash of the profile
+	// directory, so that a profile keeps its fingerprint across sessions,
+	// or to a random value without a profile.
+	Seed uint32
+	// Fingerprint names the entry of Fingerprints the session presents,
+	// e.g. "windows". New fails if it is unknown.
+	Fingerprint string
+}
+
+// Fingerprint is the platform a session presents to the scripts of the page.
+// Zero fields are left alone.
+type Fingerprint struct {
+	// Platform is navigator.platform, e.g. "Win32".
+	Platform string
+	// HardwareConcurrency is navigator.hardwareConcurrency.
+	HardwareConcurrency int
+	// DeviceMemory is navigator.deviceMemory, in GiB.
+	DeviceMemory float64
+	// Fonts are the font families reported as installed by
+	// document.fonts.check and queryLocalFonts. Probes measuring the
+	// rendered text width are not affected.
+	Fonts []string
+}
+
+// Fingerprints are the named fingerprints selectable with
+// StealthOptions.Fingerprint. Entries may be added before creating sessions.
+var Fingerprints = map[string]Fingerprint{
+	"windows": {
+		Platform:            "Win32",
+		HardwareConcurrency: 8,
+		DeviceMemory:        8,
+		Fonts: []string{"Arial", "Calibri", "Cambria", "Comic Sans MS", "Consolas", "Courier New", "Georgia",
+			"Segoe UI", "Tahoma", "Times New Roman", "Trebuchet MS", "Verdana"},
+	},
+	"macos": {
+		Platform:            "MacIntel",
+		HardwareConcurrency: 8,
+		DeviceMemory:        8,
+		Fonts: []string{"American Typewriter", "Arial", "Avenir", "Courier", "Courier New", "Geneva", "Georgia",
+			"Helvetica", "Helvetica Neue", "Lucida Grande", "Menlo", "Monaco", "Times", "Times New Roman"},
+	},
+	"linux": {
+		Platform:            "Linux x86_64",
+		HardwareConcurrency: 4,
+		DeviceMemory:        8,
+		Fonts: []string{"DejaVu Sans", "DejaVu Sans Mono", "DejaVu Serif", "Liberation Mono", "Liberation Sans",
+			"Liberation Serif", "Noto Sans", "Ubuntu"},
+	},
+}
+
+// WithStealth makes the session patch the fingerprinting surfaces selected by
+// opts, through scripts evaluated before those of every document. It
+// requires a Chromium-based browser.
+func WithStealth(opts StealthOptions) Option {
+	return func(c *sessionConfig) {
+		c.stealth = &opts
+	}
+}
+
+// stealthNative makes patched functions look native to toString.
+const stealthNative = `var natives = new WeakMap();
+var toString = Function.prototype.toString;
+var patchedToString = function toString() {
+	return natives.has(this) ? natives.get(this) : toString.call(this);
+};
+natives.set(patchedToString, toString.call(toString));
+Function.prototype.toString = patchedToString;
+var patch = function(proto, name, make) {
+	if (!proto || !proto[name]) {
+		return;
+	}
+	var orig = proto[name];
+	var fn = make(orig);
+	natives.set(fn, toString.call(orig));
+	Object.defineProperty(proto, name, {value: fn, writable: true, configurable: true});
+};
+`
+
+// stealthCanvas flips the low bit of a channel of about one pixel in 32,
+// depending on the seed and the pixel position only.
+const stealthCanvas = `var seed = __SEED__;
+var noise = function(data) {
+	for (var i = 0; i < data.length; i += 4) {
+		var h = Math.imul(seed ^ i, 0x9e3779b1);
+		h ^= h >>> 15;
+		h = Math.imul(h, 0x85ebca77);
+		h ^= h >>> 13;
+		if ((h & 31) === 0) {
+			data[i + ((h >>> 5) % 3)] ^= 1;
+		}
+	}
+};
+var getImageData = CanvasRenderingContext2D.prototype.getImageData;
+var putImageData = CanvasRenderingContext2D.prototype.putImageData;
+patch(CanvasRenderingContext2D.prototype, "getImageData", function(orig) {
+	return function getImageData() {
+		var img = orig.apply(this, arguments);
+		noise(img.data);
+		return img;
+	};
+});
+var noisyCopy = function(canvas) {
+	if (!canvas.width || !canvas.height) {
+		return canvas;
+	}
+	var copy = document.createElement("canvas");
+	copy.width = canvas.width;
+	copy.height = canvas.height;
+	var ctx = copy.getContext("2d");
+	ctx.drawImage(canvas, 0, 0);
+	var img = getImageData.call(ctx, 0, 0, copy.width, copy.height);
+	noise(img.data);
+	putImageData.call(ctx, img, 0, 0);
+	return copy;
+};
+["toDataURL", "toBlob"].forEach(function(name) {
+	patch(HTMLCanvasElement.prototype, name, function(orig) {
+		var fn = {};
+		fn[name] = function() {
+			return orig.apply(noisyCopy(this), arguments);
+		};
+		return fn[name];
+	});
+});
+[window.WebGLRenderingContext, window.WebGL2RenderingContext].forEach(function(ctx) {
+	patch(ctx && ctx.prototype, "readPixels", function(orig) {
+		return function readPixels() {
+			var ret = orig.apply(this, arguments);
+			var pixels = arguments[6];
+			if (pixels && pixels.length) {
+				noise(pixels);
+			}
+			return ret;
+		};
+	});
+});
+`
+
+// stealthFingerprint overrides the navigator properties and font checks with
+// the values of fp.
+const stealthFingerprint = `var fp = __FINGERPRINT__;
+var getter = function(proto, name, value) {
+	var desc = proto && Object.getOwnPropertyDescriptor(proto, name);
+	if (!desc || !desc.get) {
+		return;
+	}
+	var fn = {};
+	fn["get " + name] = function() {
+		return value;
+	};
+	natives.set(fn["get " + name], toString.call(desc.get));
+	Object.defineProperty(proto, name, {get: fn["get " + name], enumerable: desc.enumerable, configurable: true});
+};
+if (fp.platform) {
+	getter(Navigator.prototype, "platform", fp.platform);
+}
+if (fp.hardwareConcurrency) {
+	getter(Navigator.prototype, "hardwareConcurrency", fp.hardwareConcurrency);
+}
+if (fp.deviceMemory) {
+	getter(Navigator.prototype, "deviceMemory", fp.deviceMemory);
+}
+if (fp.fonts) {
+	var installed = {};
+	fp.fonts.forEach(function(f) {
+		installed[f.toLowerCase()] = true;
+	});
+	["serif", "sans-serif", "monospace", "cursive", "fantasy", "system-ui"].forEach(function(f) {
+		installed[f] = true;
+	});
+	var families = function(font) {
+		var m = /[\d.]+(?:px|pt|em|rem|%|pc|ex|ch|vw|vh)(?:\s*\/\s*\S+)?\s+(.+)$/.exec(font);
+		return m ? m[1].split(",").map(function(f) {
+			return f.trim().replace(/^["']|["']$/g, "").toLowerCase();
+		}) : [];
+	};
+	patch(window.FontFaceSet && FontFaceSet.prototype, "check", function(orig) {
+		return function check(font) {
+			var fams = families(String(font));
+			for (var i = 0; i < fams.length; i++) {
+				if (!installed[fams[i]] && !Array.prototype.some.call(this, function(ff) { return ff.family.replace(/^["']|["']$/g, "").toLowerCase() === fams[i]; })) {
+					return false;
+				}
+			}
+			return orig.apply(this, arguments);
+		};
+	});
+	patch(window, "queryLocalFonts", function(orig) {
+		return function queryLocalFonts() {
+			return orig.apply(this, arguments).then(function(fonts) {
+				return fonts.filter(function(f) {
+					return installed[f.family.toLowerCase()];
+				});
+			});
+		};
+	});
+}
+`
+
+// seed returns the noise seed of a session using the given profile.
+func (o *StealthOptions) seed(profile string) uint32 {
+	if o.Seed != 0 {
+		return o.Seed
+	}
+	if profile != "" {
+		h := fnv.New32a()
+		h.Write([]byte(profile))
+		return h.Sum32()
+	}
+	var b [4]byte
+	rand.Read(b[:])
+	return binary.LittleEndian.Uint32(b[:])
+}
+
+// script returns the init script implementing the options, or "" if none
+// is selected.
+func (o *StealthOptions) script(profile string) (string, error) {
+	var parts []string
+	if o.CanvasNoise {
+		parts = append(parts, strings.Replace(stealthCanvas, "__SEED__", strconv.FormatUint(uint64(o.seed(profile)), 10), 1))
+	}
+	if o.Fingerprint != "" {
+		fp, ok := Fingerprints[o.Fingerprint]
+		if !ok {
+			return "", fmt.Errorf("unknown fingerprint %q", o.Fingerprint)
+		}
+		data, err := json.Marshal(map[string]interface{}{
+			"platform":            fp.Platform,
+			"hardwareConcurrency": fp.HardwareConcurrency,
+			"deviceMemory":        fp.DeviceMemory,
+			"fonts":               fp.Fonts,
+		})
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, strings.Replace(stealthFingerprint, "__FINGERPRINT__", string(data), 1))
+	}
+	if len(parts) == 0 {
+		return "", nil
+	}
+	return "(function() {\n" + stealthNative + strings.Join(parts, "") + "})();\n", nil
+}
+
+// addInitScript evaluates script before the scripts of every document.
+func addInitScript(wd WebDriver, script string) error {
+	_, err := wd.ExecuteCDPRaw("Page.addScriptToEvaluateOnNewDocument", map[string]interface{}{"source": script})
+	return err
+}
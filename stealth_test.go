@@ -0,0 +1,33 @@
+package webdriver
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStealthScript(t *testing.T) {
+	if script, err := (&StealthOptions{}).script(""); err != nil || script != "" {
+		t.Errorf("script() without options = %q, %v, want empty", script, err)
+	}
+
+	a, _ := (&StealthOptions{CanvasNoise: true}).script("/profiles/a")
+	b, _ := (&StealthOptions{CanvasNoise: true}).script("/profiles/a")
+	c, _ := (&StealthOptions{CanvasNoise: true}).script("/profiles/c")
+	if a != b {
+		t.Error("the noise of a profile is not deterministic")
+	}
+	if a == c {
+		t.Error("profiles share the same noise")
+	}
+
+	script, err := (&StealthOptions{Fingerprint: "windows"}).script("")
+	if err != nil {
+		t.Fatalf("script() returned error: %v", err)
+	}
+	if !strings.Contains(script, `"platform":"Win32"`) {
+		t.Errorf("script() does not set the platform: %v", script)
+	}
+	if _, err := (&StealthOptions{Fingerprint: "amiga"}).script(""); err == nil {
+		t.Error("script() with an unknown fingerprint returned no error")
+	}
+}
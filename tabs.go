@@ -0,0 +1,94 @@
+package webdriver
+
+// newWindow waits for a window missing from before to open and returns its
+// handle.
+func (s *Session) newWindow(before []string) (string, error) {
+	known := map[string]bool{}
+	for _, h := range before {
+		known[h] = true
+	}
+
+	var handle string
+	err := s.waitOn(func() (bool, error) {
+		handles, err := s.WindowHandles()
+		if IsRetryable(err) {
+			return false, retryWith(err)
+		} else if err != nil {
+			return true, err
+		}
+		for _, h := range handles {
+			if !known[h] {
+				handle = h
+				return true, nil
+			}
+		}
+		return false, nil
+	}, s.timeout)
+	return handle, err
+}
+
+// newTabScript opens the link in arguments[0] in a new tab and returns
+// "opened", or returns the new-tab modifier of the platform for other
+// elements.
+const newTabScript = `var el = arguments[0];
+if ((el.tagName === "A" || el.tagName === "AREA") && el.href && !/^javascript:/i.test(el.href)) {
+	window.open(el.href, "_blank");
+	return "opened";
+}
+return /Mac|iP(hone|ad|od)/.test(navigator.platform) ? "meta" : "control";`
+
+// ClickNewTab opens the target of the element in a new tab and switches to
+// it. Links are opened with window.open, other elements are clicked with the
+// platform's new-tab modifier held. It returns a function closing the new
+// tab and switching back to the original one.
+func (e *Element) ClickNewTab() (func() error, error) {
+	s := e.s
+	orig, err := s.CurrentWindowHandle()
+	if err != nil {
+		return nil, err
+	}
+	before, err := s.WindowHandles()
+	if err != nil {
+		return nil, err
+	}
+
+	var ret interface{}
+	err = e.inFrame(func() (err error) {
+		ret, err = s.ExecuteScript(newTabScript, []interface{}{e.WebElement})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if mode, _ := ret.(string); mode != "opened" {
+		mod := ControlKey
+		if mode == "meta" {
+			mod = MetaKey
+		}
+		if err := s.KeyDown(mod); err != nil {
+			return nil, err
+		}
+		err := e.Click()
+		if upErr := s.KeyUp(mod); err == nil {
+			err = upErr
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	handle, err := s.newWindow(before)
+	if err != nil {
+		return nil, s.fail("ClickNewTab", e.xpath, err)
+	}
+	if err := s.SwitchWindow(handle); err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		if err := s.CloseWindow(""); err != nil {
+			return err
+		}
+		return s.SwitchWindow(orig)
+	}, nil
+}
@@ -0,0 +1,148 @@
+package webdriver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// TimelapseFrame is a screenshot written by a Timelapse.
+type TimelapseFrame struct {
+	// File is the name of the frame in the timelapse directory.
+	File string    `json:"file"`
+	Time time.Time `json:"time"`
+	URL  string    `json:"url"`
+	// Repeats counts the following captures identical to the frame, which
+	// are not written.
+	Repeats int `json:"repeats,omitempty"`
+}
+
+// TimelapseManifest describes the frames of a Timelapse. It is written to
+// manifest.json in the timelapse directory when the timelapse stops.
+type TimelapseManifest struct {
+	Interval time.Duration    `json:"interval"`
+	Start    time.Time        `json:"start"`
+	End      time.Time        `json:"end"`
+	Frames   []TimelapseFrame `json:"frames"`
+	// Errors counts the failed captures, e.g. while a dialog was open.
+	Errors int `json:"errors"`
+}
+
+// Timelapse captures a screenshot of a session at a fixed interval, see
+// Session.Timelapse.
+type Timelapse struct {
+	s    *Session
+	dir  string
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+
+	mu       sync.Mutex
+	manifest TimelapseManifest
+	last     []byte
+	err      error
+}
+
+// Timelapse writes a screenshot of the session every interval to numbered
+// PNG files in dir, until the returned Timelapse is stopped. Consecutive
+// identical screenshots are written once, so that an idle page costs little
+// disk. Unlike a video, the capture adds a single screenshot command per
+// interval to the session.
+func (s *Session) Timelapse(interval time.Duration, dir string) (*Timelapse, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid timelapse interval %v", interval)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	t := &Timelapse{
+		s:    s,
+		dir:  dir,
+		done: make(chan struct{}),
+		manifest: TimelapseManifest{
+			Interval: interval,
+			Start:    time.Now(),
+		},
+	}
+	t.wg.Add(1)
+	go t.run(interval)
+	return t, nil
+}
+
+func (t *Timelapse) run(interval time.Duration) {
+	defer t.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		t.capture()
+		select {
+		case <-ticker.C:
+		case <-t.done:
+			return
+		}
+	}
+}
+
+func (t *Timelapse) capture() {
+	now := time.Now()
+	img, err := t.s.Screenshot()
+	if err != nil {
+		t.mu.Lock()
+		t.manifest.Errors++
+		t.mu.Unlock()
+		debugLog("timelapse capture failed: %v", err)
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if n := len(t.manifest.Frames); n > 0 && bytes.Equal(img, t.last) {
+		t.manifest.Frames[n-1].Repeats++
+		return
+	}
+
+	u, _ := t.s.CurrentURL()
+	frame := TimelapseFrame{
+		File: fmt.Sprintf("frame-%05d.png", len(t.manifest.Frames)+1),
+		Time: now,
+		URL:  u,
+	}
+	if err := ioutil.WriteFile(filepath.Join(t.dir, frame.File), img, 0644); err != nil {
+		// The directory is unusable, stop capturing.
+		t.err = err
+		t.once.Do(func() { close(t.done) })
+		return
+	}
+	t.manifest.Frames = append(t.manifest.Frames, frame)
+	t.last = img
+}
+
+// Stop stops the capture, writes the manifest to manifest.json in the
+// timelapse directory and returns it.
+func (t *Timelapse) Stop() (*TimelapseManifest, error) {
+	t.once.Do(func() { close(t.done) })
+	t.wg.Wait()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.manifest.End.IsZero() {
+		t.manifest.End = time.Now()
+	}
+	manifest := t.manifest
+	if t.err != nil {
+		return &manifest, t.err
+	}
+
+	data, err := json.MarshalIndent(&manifest, "", "  ")
+	if err != nil {
+		return &manifest, err
+	}
+	return &manifest, ioutil.WriteFile(filepath.Join(t.dir, "manifest.json"), data, 0644)
+}
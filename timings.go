@@ -0,0 +1,103 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ResourceTiming is a resource loaded by the current page, from the
+// Performance API.
+type ResourceTiming struct {
+	URL string
+	// Type is the initiator of the load, e.g. "script", "img", "css",
+	// "fetch", or "navigation" for the document itself.
+	Type string
+	// TransferSize is the size fetched over the network, headers included.
+	// It is zero for cached resources and for cross-origin resources
+	// without a Timing-Allow-Origin header.
+	TransferSize int64
+	// EncodedSize and DecodedSize are the size of the body before and after
+	// content decoding.
+	EncodedSize, DecodedSize int64
+	// Start is the time the load started, relative to the navigation.
+	Start time.Duration
+	// Duration is the time the load took.
+	Duration time.Duration
+}
+
+// TypeWeight is the share of a resource type in a PageWeight.
+type TypeWeight struct {
+	Requests     int
+	TransferSize int64
+	DecodedSize  int64
+}
+
+// PageWeight summarizes the resources of a page.
+type PageWeight struct {
+	Requests     int
+	TransferSize int64
+	DecodedSize  int64
+	// ByType breaks the totals down by ResourceTiming.Type.
+	ByType map[string]TypeWeight
+	// Duration is the time from the navigation to the end of the last load.
+	Duration time.Duration
+}
+
+// ResourceTimings returns the document and the resources loaded by the
+// current page, with their aggregated weight.
+func (s *Session) ResourceTimings() ([]ResourceTiming, *PageWeight, error) {
+	data, err := s.ExecuteScriptRaw(`return performance.getEntriesByType("navigation").concat(performance.getEntriesByType("resource")).map(function(e) {
+	return {url: e.name, type: e.initiatorType, transferSize: e.transferSize || 0, encodedSize: e.encodedBodySize || 0, decodedSize: e.decodedBodySize || 0, start: e.startTime, duration: e.duration};
+});`, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reply := struct {
+		Value []struct {
+			URL          string  `json:"url"`
+			Type         string  `json:"type"`
+			TransferSize int64   `json:"transferSize"`
+			EncodedSize  int64   `json:"encodedSize"`
+			DecodedSize  int64   `json:"decodedSize"`
+			Start        float64 `json:"start"`
+			Duration     float64 `json:"duration"`
+		}
+	}{}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return nil, nil, err
+	}
+
+	timings := make([]ResourceTiming, 0, len(reply.Value))
+	for _, r := range reply.Value {
+		timings = append(timings, ResourceTiming{
+			URL:          r.URL,
+			Type:         r.Type,
+			TransferSize: r.TransferSize,
+			EncodedSize:  r.EncodedSize,
+			DecodedSize:  r.DecodedSize,
+			Start:        msDuration(r.Start),
+			Duration:     msDuration(r.Duration),
+		})
+	}
+	return timings, pageWeight(timings), nil
+}
+
+func pageWeight(timings []ResourceTiming) *PageWeight {
+	w := &PageWeight{ByType: map[string]TypeWeight{}}
+	for _, t := range timings {
+		w.Requests++
+		w.TransferSize += t.TransferSize
+		w.DecodedSize += t.DecodedSize
+		if end := t.Start + t.Duration; end > w.Duration {
+			w.Duration = end
+		}
+
+		tw := w.ByType[t.Type]
+		tw.Requests++
+		tw.TransferSize += t.TransferSize
+		tw.DecodedSize += t.DecodedSize
+		w.ByType[t.Type] = tw
+	}
+	return w
+}
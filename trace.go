@@ -0,0 +1,178 @@
+package webdriver
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultTraceCategories are the categories recorded by the DevTools
+// performance panel.
+var defaultTraceCategories = []string{
+	"-*",
+	"devtools.timeline",
+	"v8.execute",
+	"disabled-by-default-devtools.timeline",
+	"disabled-by-default-devtools.timeline.frame",
+	"toplevel",
+	"blink.console",
+	"blink.user_timing",
+	"latencyInfo",
+	"disabled-by-default-devtools.timeline.stack",
+	"disabled-by-default-v8.cpu_profiler",
+}
+
+// tracer is a trace in progress, recorded through a DevTools connection to
+// the browser target, since the trace is only handed over in an event.
+type tracer struct {
+	conn     *cdpConn
+	complete chan string
+}
+
+// browserConn connects to the browser target of the session's browser.
+func (s *Session) browserConn() (*cdpConn, error) {
+	addr, err := s.debuggerAddress()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.Get("http://" + addr + "/json/version")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	version := struct {
+		WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&version); err != nil {
+		return nil, err
+	}
+	ws, err := dialWebSocket(version.WebSocketDebuggerURL, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return newCDPConn(ws), nil
+}
+
+// StartTrace starts recording a Chrome trace of the given categories, or of
+// the categories of the DevTools performance panel if none is given, until
+// StopTrace. Categories prefixed with "-" are excluded.
+func (s *Session) StartTrace(categories ...string) error {
+	s.mu.Lock()
+	busy := s.trace != nil
+	s.mu.Unlock()
+	if busy {
+		return fmt.Errorf("a trace is already in progress")
+	}
+	if len(categories) == 0 {
+		categories = defaultTraceCategories
+	}
+	var included, excluded []string
+	for _, c := range categories {
+		if strings.HasPrefix(c, "-") {
+			excluded = append(excluded, c[1:])
+		} else {
+			included = append(included, c)
+		}
+	}
+
+	conn, err := s.browserConn()
+	if err != nil {
+		return err
+	}
+	t := &tracer{conn: conn, complete: make(chan string, 1)}
+	conn.on("Tracing.tracingComplete", func(params json.RawMessage) {
+		ev := struct {
+			Stream string `json:"stream"`
+		}{}
+		json.Unmarshal(params, &ev)
+		t.complete <- ev.Stream
+	})
+	if _, err := conn.call("Tracing.start", map[string]interface{}{
+		"transferMode": "ReturnAsStream",
+		"traceConfig": map[string]interface{}{
+			"includedCategories": included,
+			"excludedCategories": excluded,
+		},
+	}); err != nil {
+		conn.Close()
+		return err
+	}
+
+	// The connection is dialed without s.mu, so a concurrent StartTrace may
+	// have won meanwhile.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.trace != nil {
+		conn.Close()
+		return fmt.Errorf("a trace is already in progress")
+	}
+	s.trace = t
+	return nil
+}
+
+// StopTrace stops the trace started by StartTrace and writes it to file, in
+// the JSON format loaded by chrome://tracing and the DevTools performance
+// panel.
+func (s *Session) StopTrace(file string) error {
+	s.mu.Lock()
+	t := s.trace
+	s.trace = nil
+	s.mu.Unlock()
+	if t == nil {
+		return fmt.Errorf("no trace in progress")
+	}
+	defer t.conn.Close()
+
+	if _, err := t.conn.call("Tracing.end", nil); err != nil {
+		return err
+	}
+	var stream string
+	select {
+	case stream = <-t.complete:
+	case <-t.conn.closed:
+		return fmt.Errorf("devtools connection closed")
+	case <-time.After(s.timeout):
+		return fmt.Errorf("timeout waiting for the trace")
+	}
+	if stream == "" {
+		return fmt.Errorf("the browser returned no trace stream")
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	for {
+		data, err := t.conn.call("IO.read", map[string]interface{}{"handle": stream})
+		if err != nil {
+			return err
+		}
+		chunk := struct {
+			Data          string `json:"data"`
+			Base64Encoded bool   `json:"base64Encoded"`
+			EOF           bool   `json:"eof"`
+		}{}
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return err
+		}
+		b := []byte(chunk.Data)
+		if chunk.Base64Encoded {
+			if b, err = base64.StdEncoding.DecodeString(chunk.Data); err != nil {
+				return err
+			}
+		}
+		if _, err := f.Write(b); err != nil {
+			return err
+		}
+		if chunk.EOF {
+			break
+		}
+	}
+	t.conn.call("IO.close", map[string]interface{}{"handle": stream})
+	return f.Close()
+}
@@ -0,0 +1,35 @@
+package webdriver
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStartTraceUnlocked(t *testing.T) {
+	s := &Session{}
+	// The browser answers only once the session lock is free.
+	srv, _ := fakeBrowser(t, func() {
+		locked := make(chan struct{})
+		go func() {
+			s.mu.Lock()
+			s.mu.Unlock()
+			close(locked)
+		}()
+		select {
+		case <-locked:
+		case <-time.After(5 * time.Second):
+			t.Error("StartTrace holds the session lock while connecting")
+		}
+	})
+	defer srv.Close()
+	s.WebDriver = &cdpWD{debuggerAddress: strings.TrimPrefix(srv.URL, "http://")}
+
+	if err := s.StartTrace(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.trace.conn.Close()
+	if err := s.StartTrace(); err == nil {
+		t.Errorf("second StartTrace returned nil error")
+	}
+}
@@ -0,0 +1,117 @@
+package webdriver
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// uploadFile stores content as the file name on the machine of the browser,
+// through the file endpoint of Selenium Grid, and returns its remote path.
+func (wd *remoteWD) uploadFile(name string, content []byte) (string, error) {
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	f, err := w.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(content); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(map[string]string{
+		"file": base64.StdEncoding.EncodeToString(buf.Bytes()),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	response, err := wd.execute("POST", wd.requestURL("/session/%s/se/file", wd.id), data)
+	if e, ok := err.(*Error); ok && e.Err == "unknown command" {
+		// Selenium 3 only has the legacy endpoint.
+		response, err = wd.execute("POST", wd.requestURL("/session/%s/file", wd.id), data)
+	}
+	if err != nil {
+		return "", err
+	}
+	reply := new(struct{ Value string })
+	if err := json.Unmarshal(response, reply); err != nil {
+		return "", err
+	}
+	return reply.Value, nil
+}
+
+// remoteFilesystem reports whether the browsers run on another machine, so
+// that local paths are meaningless to them.
+func (s *server) remoteFilesystem() bool {
+	return s.d.container != nil || s.cloud != nil || s.shards != nil
+}
+
+// UploadBytes selects content as the file name in a file input, so that
+// generated files can be submitted without staging them first. The content is
+// written to a temporary file, which is removed when the session is closed, or
+// uploaded to the browser machine when the driver is remote.
+func (e *Element) UploadBytes(name string, content []byte) error {
+	name = filepath.Base(name)
+	if name == "." || name == string(filepath.Separator) {
+		return fmt.Errorf("invalid file name %q", name)
+	}
+
+	var path string
+	if wd, ok := e.s.WebDriver.(*remoteWD); ok && inst != nil && inst.remoteFilesystem() {
+		p, err := wd.uploadFile(name, content)
+		if err != nil {
+			return e.s.fail("UploadBytes", name, err)
+		}
+		path = p
+	} else {
+		dir, err := e.s.uploadDir()
+		if err != nil {
+			return err
+		}
+		// Each upload gets its own directory to keep the name as given.
+		sub, err := ioutil.TempDir(dir, "")
+		if err != nil {
+			return err
+		}
+		path = filepath.Join(sub, name)
+		if err := ioutil.WriteFile(path, content, 0644); err != nil {
+			return err
+		}
+	}
+
+	if err := e.SendKeys(path); err != nil {
+		return e.s.fail("UploadBytes", name, err)
+	}
+	return nil
+}
+
+// uploadDir returns the directory of the temporary files of UploadBytes.
+func (s *Session) uploadDir() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.uploads == "" {
+		dir, err := ioutil.TempDir("", "webdriver-upload-")
+		if err != nil {
+			return "", err
+		}
+		s.uploads = dir
+	}
+	return s.uploads, nil
+}
+
+func (s *Session) removeUploads() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.uploads != "" {
+		os.RemoveAll(s.uploads)
+		s.uploads = ""
+	}
+}
@@ -0,0 +1,54 @@
+package webdriver
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUploadFile(t *testing.T) {
+	var paths []string
+	var name, content string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", jsonContentType)
+		if r.URL.Path == "/session/1/se/file" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"value": {"error": "unknown command", "message": "no such endpoint"}}`))
+			return
+		}
+		req := struct{ File string }{}
+		json.NewDecoder(r.Body).Decode(&req)
+		b, _ := base64.StdEncoding.DecodeString(req.File)
+		zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+		if err != nil || len(zr.File) != 1 {
+			t.Errorf("bad zip: %v", err)
+			return
+		}
+		f, _ := zr.File[0].Open()
+		data, _ := ioutil.ReadAll(f)
+		name, content = zr.File[0].Name, string(data)
+		w.Write([]byte(`{"value": "/tmp/upload1/report.csv"}`))
+	}))
+	defer srv.Close()
+
+	wd := &remoteWD{urlPrefix: srv.URL, id: "1"}
+	path, err := wd.uploadFile("report.csv", []byte("a,b\n1,2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/tmp/upload1/report.csv" {
+		t.Errorf("path = %q", path)
+	}
+	if name != "report.csv" || content != "a,b\n1,2\n" {
+		t.Errorf("uploaded %q = %q", name, content)
+	}
+	if len(paths) != 2 || paths[1] != "/session/1/file" {
+		t.Errorf("requests = %v, want fallback to the legacy endpoint", paths)
+	}
+}
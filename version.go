@@ -0,0 +1,115 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrVersionMismatch is returned by Init when the driver does not support
+// the installed browser.
+var ErrVersionMismatch = errors.New("driver and browser versions mismatch")
+
+// VersionMismatchError describes incompatible driver and browser versions.
+// It matches ErrVersionMismatch via its Cause method.
+type VersionMismatchError struct {
+	Driver  string
+	Browser string
+}
+
+func (e *VersionMismatchError) Error() string {
+	return fmt.Sprintf("%v: driver %s, browser %s", ErrVersionMismatch, e.Driver, e.Browser)
+}
+
+// Cause returns ErrVersionMismatch, for errors.Cause.
+func (e *VersionMismatchError) Cause() error {
+	return ErrVersionMismatch
+}
+
+// WithVersionCheck makes Init compare the major version of ChromeDriver or
+// EdgeDriver with the one of the local browser binary, failing with a
+// VersionMismatchError instead of letting every New fail later with an opaque
+// "session not created". An empty binary is looked up as for CDPDriver, or
+// among the usual locations of Edge for EdgeDriver.
+func WithVersionCheck(binary string) InitOption {
+	return func(c *initConfig) {
+		c.versionCheck = true
+		c.browserBinary = binary
+	}
+}
+
+var versionRe = regexp.MustCompile(`\d+(\.\d+){1,3}`)
+
+// checkVersions compares the versions of the driver of kind at addr and of
+// binary, or of the browser of kind if binary is empty.
+func checkVersions(addr, binary string, kind DriverKind) error {
+	if binary == "" {
+		var err error
+		if kind == EdgeDriver {
+			binary, err = edgeBinary()
+		} else {
+			binary, err = chromeBinary(&sessionConfig{})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	out, err := exec.Command(binary, "--version").Output()
+	if err != nil {
+		return fmt.Errorf("error querying the version of %v: %v", binary, err)
+	}
+	browser := versionRe.FindString(string(out))
+	if browser == "" {
+		return fmt.Errorf("no version in the output of %v --version: %q", binary, out)
+	}
+
+	resp, err := HTTPClient.Get(addr + "/status")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	status := struct{ Value Status }{}
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return fmt.Errorf("error decoding driver status: %v", err)
+	}
+	driver := versionRe.FindString(status.Value.Build.Version)
+	if driver == "" {
+		return fmt.Errorf("driver reports no build version")
+	}
+
+	if major(driver) != major(browser) {
+		return &VersionMismatchError{Driver: driver, Browser: browser}
+	}
+	return nil
+}
+
+// edgeBinary locates the Edge binary, as chromeBinary does Chrome.
+func edgeBinary() (string, error) {
+	var candidates []string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = []string{"/Applications/Microsoft Edge.app/Contents/MacOS/Microsoft Edge"}
+	case "windows":
+		for _, env := range []string{"ProgramFiles(x86)", "ProgramFiles"} {
+			candidates = append(candidates, filepath.Join(os.Getenv(env), `Microsoft\Edge\Application\msedge.exe`))
+		}
+	}
+	candidates = append(candidates, "microsoft-edge", "microsoft-edge-stable", "msedge")
+	for _, c := range candidates {
+		if path, err := exec.LookPath(c); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("edge not found, pass its binary to WithVersionCheck")
+}
+
+func major(version string) string {
+	return strings.SplitN(version, ".", 2)[0]
+}
@@ -0,0 +1,79 @@
+package webdriver
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestCheckVersions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake browser is a shell script")
+	}
+	dir, err := ioutil.TempDir("", "webdriver-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	browser := filepath.Join(dir, "chrome")
+	if err := ioutil.WriteFile(browser, []byte("#!/bin/sh\necho Google Chrome 115.0.5790.102\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	driverVersion := "114.0.5735.90 (386bc09e8f4f2e025eddae123f36f6263096ae49-refs/branch-heads/5735@{#1052})"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"value": {"build": {"version": "` + driverVersion + `"}, "ready": true}}`))
+	}))
+	defer srv.Close()
+
+	err = checkVersions(srv.URL, browser, ChromeDriver)
+	if errors.Cause(err) != ErrVersionMismatch {
+		t.Fatalf("checkVersions = %v, want %v", err, ErrVersionMismatch)
+	}
+	if e := err.(*VersionMismatchError); e.Driver != "114.0.5735.90" || e.Browser != "115.0.5790.102" {
+		t.Errorf("versions = %+v", e)
+	}
+
+	driverVersion = "115.0.5790.170"
+	if err := checkVersions(srv.URL, browser, ChromeDriver); err != nil {
+		t.Errorf("checkVersions with matching versions = %v", err)
+	}
+}
+
+func TestCheckVersionsEdge(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Edge is looked up in PATH on Linux only")
+	}
+	dir, err := ioutil.TempDir("", "webdriver-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, version := range map[string]string{"google-chrome": "Google Chrome 114.0.5735.90", "microsoft-edge": "Microsoft Edge 115.0.1901.183"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\necho "+version+"\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", dir)
+	defer os.Setenv("CHROME_BIN", os.Getenv("CHROME_BIN"))
+	os.Unsetenv("CHROME_BIN")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"value": {"build": {"version": "115.0.1901.183"}, "ready": true}}`))
+	}))
+	defer srv.Close()
+
+	if err := checkVersions(srv.URL, "", EdgeDriver); err != nil {
+		t.Errorf("checkVersions of msedgedriver = %v, want the Edge version to match", err)
+	}
+	if err := checkVersions(srv.URL, "", ChromeDriver); errors.Cause(err) != ErrVersionMismatch {
+		t.Errorf("checkVersions of chromedriver = %v, want %v", err, ErrVersionMismatch)
+	}
+}
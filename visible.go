@@ -0,0 +1,76 @@
+package webdriver
+
+// visibleOnly returns the displayed elements of elems. Elements going stale
+// while checked are dropped.
+func visibleOnly(elems []*Element) ([]*Element, error) {
+	var ret []*Element
+	for _, e := range elems {
+		displayed, err := e.IsDisplayed()
+		if StaleElement(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if displayed {
+			ret = append(ret, e)
+		}
+	}
+	return ret, nil
+}
+
+// GetVisibleDOM waits for an element matching xpath to be displayed and
+// returns the first such element, skipping hidden matches such as the
+// variants of a responsive layout.
+func (s *Session) GetVisibleDOM(xpath string) (*Element, error) {
+	elems, err := s.getVisibleDOMs("GetVisibleDOM", func() ([]*Element, error) { return s.findN(xpath) }, xpath)
+	if err != nil {
+		return nil, err
+	}
+	return elems[0], nil
+}
+
+// GetVisibleDOMs waits for elements matching xpath to be displayed and
+// returns the displayed ones.
+func (s *Session) GetVisibleDOMs(xpath string) ([]*Element, error) {
+	return s.getVisibleDOMs("GetVisibleDOMs", func() ([]*Element, error) { return s.findN(xpath) }, xpath)
+}
+
+// GetVisibleDOM is Session.GetVisibleDOM relative to the element.
+func (e *Element) GetVisibleDOM(xpath string) (*Element, error) {
+	elems, err := e.s.getVisibleDOMs("GetVisibleDOM", func() ([]*Element, error) { return e.findN(xpath) }, xpath)
+	if err != nil {
+		return nil, err
+	}
+	return elems[0], nil
+}
+
+// GetVisibleDOMs is Session.GetVisibleDOMs relative to the element.
+func (e *Element) GetVisibleDOMs(xpath string) ([]*Element, error) {
+	return e.s.getVisibleDOMs("GetVisibleDOMs", func() ([]*Element, error) { return e.findN(xpath) }, xpath)
+}
+
+func (s *Session) getVisibleDOMs(op string, findN func() ([]*Element, error), xpath string) ([]*Element, error) {
+	var ret []*Element
+	err := s.waitOn(func() (bool, error) {
+		elems, err := findN()
+		if err == ErrNotFound || IsRetryable(err) {
+			return false, retryWith(err)
+		} else if err != nil {
+			return true, err
+		}
+
+		visible, err := visibleOnly(elems)
+		if IsRetryable(err) {
+			return false, retryWith(err)
+		} else if err != nil {
+			return true, err
+		} else if len(visible) == 0 {
+			return false, nil
+		}
+
+		ret = visible
+		return true, nil
+	}, s.timeout)
+
+	return ret, s.fail(op, xpath, err)
+}
@@ -0,0 +1,127 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebVitals are the Core Web Vitals of the current page. Metrics that have
+// not been observed yet are zero, FID and INP in particular require a user
+// interaction.
+type WebVitals struct {
+	// LCP is the Largest Contentful Paint.
+	LCP time.Duration
+	// CLS is the Cumulative Layout Shift, as the largest session window of
+	// layout shifts.
+	CLS float64
+	// FID is the First Input Delay.
+	FID time.Duration
+	// INP is the Interaction to Next Paint, approximated as the slowest
+	// interaction.
+	INP time.Duration
+	// TTFB is the Time To First Byte of the navigation.
+	TTFB time.Duration
+}
+
+// vitalsScript installs the PerformanceObservers once per document and
+// returns the values collected so far. Buffered entries are delivered
+// asynchronously, so the first call may not report them yet.
+const vitalsScript = `var v = window.__webdriverVitals;
+if (!v) {
+	v = window.__webdriverVitals = {lcp: 0, cls: 0, fid: 0, inp: 0, ttfb: 0};
+	var observe = function(type, fn, opts) {
+		try {
+			var o = {type: type, buffered: true};
+			for (var k in opts || {}) {
+				o[k] = opts[k];
+			}
+			new PerformanceObserver(function(list) { list.getEntries().forEach(fn); }).observe(o);
+		} catch (e) {}
+	};
+	observe("largest-contentful-paint", function(e) {
+		v.lcp = e.renderTime || e.loadTime || e.startTime;
+	});
+	var win = 0, winStart = 0, winLast = 0;
+	observe("layout-shift", function(e) {
+		if (e.hadRecentInput) {
+			return;
+		}
+		if (win > 0 && e.startTime - winLast < 1000 && e.startTime - winStart < 5000) {
+			win += e.value;
+		} else {
+			win = e.value;
+			winStart = e.startTime;
+		}
+		winLast = e.startTime;
+		v.cls = Math.max(v.cls, win);
+	});
+	observe("first-input", function(e) {
+		v.fid = e.processingStart - e.startTime;
+	});
+	observe("event", function(e) {
+		if (e.interactionId) {
+			v.inp = Math.max(v.inp, e.duration);
+		}
+	}, {durationThreshold: 16});
+}
+var nav = performance.getEntriesByType("navigation")[0];
+if (nav) {
+	v.ttfb = nav.responseStart;
+} else if (performance.timing) {
+	v.ttfb = performance.timing.responseStart - performance.timing.navigationStart;
+}
+return v;`
+
+func msDuration(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// WebVitals returns the Core Web Vitals of the current page, as collected by
+// PerformanceObservers injected on the first call. Since the observers
+// receive the buffered entries asynchronously, a first call right after a
+// navigation may miss some metrics, see WaitForVitals.
+func (s *Session) WebVitals() (*WebVitals, error) {
+	data, err := s.ExecuteScriptRaw(vitalsScript, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := struct {
+		Value struct {
+			LCP  float64 `json:"lcp"`
+			CLS  float64 `json:"cls"`
+			FID  float64 `json:"fid"`
+			INP  float64 `json:"inp"`
+			TTFB float64 `json:"ttfb"`
+		}
+	}{}
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return nil, err
+	}
+	return &WebVitals{
+		LCP:  msDuration(reply.Value.LCP),
+		CLS:  reply.Value.CLS,
+		FID:  msDuration(reply.Value.FID),
+		INP:  msDuration(reply.Value.INP),
+		TTFB: msDuration(reply.Value.TTFB),
+	}, nil
+}
+
+// WaitForVitals waits up to timeout for the LCP and TTFB of the current page
+// to be reported, and returns the vitals. On timeout the vitals collected so
+// far are returned with the error.
+func (s *Session) WaitForVitals(timeout time.Duration) (*WebVitals, error) {
+	var ret *WebVitals
+	err := s.waitOn(func() (bool, error) {
+		v, err := s.WebVitals()
+		if IsRetryable(err) {
+			return false, retryWith(err)
+		} else if err != nil {
+			return true, err
+		}
+		ret = v
+		return v.LCP > 0 && v.TTFB > 0, nil
+	}, timeout)
+
+	return ret, s.fail("WaitForVitals", "", err)
+}
@@ -94,6 +94,12 @@ func (c Capabilities) AddChrome(f chromeCapabilities) {
 	c[DeprecatedCapabilitiesKey] = f
 }
 
+// AddEdge adds Edge-specific capabilities. Edge is Chromium-based and accepts
+// the same options as Chrome.
+func (c Capabilities) AddEdge(f chromeCapabilities) {
+	c[EdgeCapabilitiesKey] = f
+}
+
 // AddProxy adds proxy configuration to the capabilities.
 func (c Capabilities) AddProxy(p Proxy) {
 	c["proxy"] = p
@@ -349,6 +355,13 @@ type WebDriver interface {
 	// perform JSON decoding.
 	ExecuteScriptAsyncRaw(script string, args []interface{}) ([]byte, error)
 
+	// ExecuteCDP executes a Chrome DevTools Protocol command through the
+	// driver. It is only supported by Chromium-based browsers.
+	ExecuteCDP(cmd string, params map[string]interface{}) (interface{}, error)
+	// ExecuteCDPRaw executes a Chrome DevTools Protocol command but does not
+	// perform JSON decoding.
+	ExecuteCDPRaw(cmd string, params map[string]interface{}) ([]byte, error)
+
 	// WaitWithTimeoutAndInterval waits for the condition to evaluate to true.
 	WaitWithTimeoutAndInterval(condition Condition, timeout, interval time.Duration) error
 
@@ -0,0 +1,192 @@
+package webdriver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+)
+
+// Logger receives the debug output of the package.
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+type stdoutLogger struct{}
+
+func (stdoutLogger) Printf(format string, args ...interface{}) {
+	fmt.Printf(format+"\n", args...)
+}
+
+// logger holds the Logger of the package in a loggerBox, since an
+// atomic.Value takes a single concrete type.
+var logger atomic.Value
+
+type loggerBox struct{ Logger }
+
+// SetLogger replaces the logger used for the debug output, which is printed
+// to stdout by default.
+func SetLogger(l Logger) {
+	if l == nil {
+		l = stdoutLogger{}
+	}
+	logger.Store(loggerBox{l})
+}
+
+// currentLogger returns the logger set by SetLogger.
+func currentLogger() Logger {
+	if box, ok := logger.Load().(loggerBox); ok {
+		return box.Logger
+	}
+	return stdoutLogger{}
+}
+
+var wireDebug int32
+
+// SetWireDebug toggles logging of every command sent to the driver: method,
+// path, payload, reply status and latency. Secrets in the payload, such as
+// passwords and typed keys, are redacted.
+func SetWireDebug(debug bool) {
+	var v int32
+	if debug {
+		v = 1
+	}
+	atomic.StoreInt32(&wireDebug, v)
+}
+
+// maxWirePayload is the payload length after which the wire log truncates,
+// e.g. for uploads and scripts.
+const maxWirePayload = 1024
+
+// secretWord matches the words of keys that may hold secrets, e.g. of
+// "password", "api_token" or "X-Auth-Token", but not of "author".
+var secretWord = regexp.MustCompile(`^(pass(word|wd|phrase)?|secrets?|tokens?|auth(orization|entication)?|cookies?|credentials?)$`)
+
+// secretKey reports whether a key looks sensitive by its words in camel,
+// snake or kebab case.
+func secretKey(key string) bool {
+	for _, w := range keyWords(key) {
+		if secretWord.MatchString(strings.ToLower(w)) {
+			return true
+		}
+	}
+	return false
+}
+
+// keyWords splits a key into words, e.g. "XAuthToken" into X, Auth and Token.
+func keyWords(key string) []string {
+	var words []string
+	r := []rune(key)
+	start := 0
+	for i := 0; i <= len(r); i++ {
+		if i == len(r) || !unicode.IsLetter(r[i]) && !unicode.IsDigit(r[i]) {
+			if i > start {
+				words = append(words, string(r[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		// A word starts at an upper case letter following a lower case
+		// one, or preceding one after an upper case one, as in XAuth.
+		if i > start && unicode.IsUpper(r[i]) && (!unicode.IsUpper(r[i-1]) || i+1 < len(r) && unicode.IsLower(r[i+1])) {
+			words = append(words, string(r[start:i]))
+			start = i
+		}
+	}
+	return words
+}
+
+func logWire(method, rawURL string, data []byte, status string, start time.Time) {
+	if atomic.LoadInt32(&wireDebug) == 0 {
+		return
+	}
+
+	p := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		p = u.Path
+	}
+	currentLogger().Printf("[webdriver] %s %s %s -> %s (%v)", method, p, redactPayload(p, data), status, time.Since(start).Round(time.Millisecond))
+}
+
+// redactPayload returns the printable form of the payload of a command to
+// path.
+func redactPayload(path string, data []byte) string {
+	if len(data) == 0 {
+		return "-"
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Sprintf("<%d bytes>", len(data))
+	}
+	// Typed text may be anything the user enters, including passwords.
+	typing := strings.HasSuffix(path, "/value") || strings.HasSuffix(path, "/keys") || strings.HasSuffix(path, "/actions") || cdpInput(path, v)
+	v = redactValue("", v, typing)
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("<%d bytes>", len(data))
+	}
+	if len(out) > maxWirePayload {
+		return fmt.Sprintf("%s...<%d bytes>", out[:maxWirePayload], len(out))
+	}
+	return string(out)
+}
+
+// cdpInput reports whether v is the payload of a DevTools Input command sent
+// through the driver, such as Input.insertText, which carries typed text.
+func cdpInput(path string, v interface{}) bool {
+	if !strings.HasSuffix(path, "/cdp/execute") {
+		return false
+	}
+	m, _ := v.(map[string]interface{})
+	cmd, _ := m["cmd"].(string)
+	return strings.HasPrefix(cmd, "Input.")
+}
+
+// typedKeys are the keys holding typed text in the payloads of typing
+// commands, e.g. the text of element/value and the keys of Input events.
+var typedKeys = map[string]bool{"text": true, "value": true, "unmodifiedText": true, "key": true, "code": true}
+
+// redactValue replaces the strings of v that may hold secrets: everything
+// below a key that looks sensitive and, if typing, the typed text.
+func redactValue(key string, v interface{}, typing bool) interface{} {
+	if secretKey(key) {
+		return redactAll(v)
+	}
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = redactValue(k, e, typing)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = redactValue(key, e, typing)
+		}
+	case string:
+		if typing && typedKeys[key] {
+			return "__redacted__"
+		}
+	}
+	return v
+}
+
+func redactAll(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = redactAll(e)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = redactAll(e)
+		}
+	case string:
+		return "__redacted__"
+	}
+	return v
+}
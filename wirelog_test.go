@@ -0,0 +1,87 @@
+package webdriver
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestRedactPayload(t *testing.T) {
+	for _, tc := range []struct {
+		path, data, want string
+	}{
+		{"/session/1/url", `{"url":"https://example.com"}`, `{"url":"https://example.com"}`},
+		{"/session/1/element/2/value", `{"text":"hunter2","value":["h"]}`, `{"text":"__redacted__","value":["__redacted__"]}`},
+		{"/session/1/cookie", `{"cookie":{"name":"sid","value":"x"}}`, `{"cookie":{"name":"__redacted__","value":"__redacted__"}}`},
+		{"/session/1/execute/sync", `{"args":[{"password":"x"}],"script":"return 1"}`, `{"args":[{"password":"__redacted__"}],"script":"return 1"}`},
+		{"/session/1/goog/cdp/execute", `{"cmd":"Input.insertText","params":{"text":"hunter2"}}`, `{"cmd":"Input.insertText","params":{"text":"__redacted__"}}`},
+		{"/session/1/ms/cdp/execute", `{"cmd":"Input.dispatchKeyEvent","params":{"code":"KeyH","key":"h","text":"h","type":"keyDown"}}`, `{"cmd":"Input.dispatchKeyEvent","params":{"code":"__redacted__","key":"__redacted__","text":"__redacted__","type":"keyDown"}}`},
+		{"/session/1/goog/cdp/execute", `{"cmd":"Runtime.evaluate","params":{"expression":"1"}}`, `{"cmd":"Runtime.evaluate","params":{"expression":"1"}}`},
+		{"/session/1/back", ``, `-`},
+	} {
+		if got := redactPayload(tc.path, []byte(tc.data)); got != tc.want {
+			t.Errorf("redactPayload(%q, %s) = %s, want %s", tc.path, tc.data, got, tc.want)
+		}
+	}
+}
+
+func TestSecretKey(t *testing.T) {
+	for key, want := range map[string]bool{
+		"password":      true,
+		"Password":      true,
+		"api_token":     true,
+		"accessToken":   true,
+		"X-Auth-Token":  true,
+		"XAuthToken":    true,
+		"Authorization": true,
+		"cookies":       true,
+		"client_secret": true,
+		"author":        false,
+		"authority":     false,
+		"passenger":     false,
+		"tokenizer":     false,
+		"url":           false,
+	} {
+		if got := secretKey(key); got != want {
+			t.Errorf("secretKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+// countLogger counts the lines logged.
+type countLogger struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (l *countLogger) Printf(format string, args ...interface{}) {
+	l.mu.Lock()
+	l.n++
+	l.mu.Unlock()
+}
+
+func TestSetLoggerConcurrent(t *testing.T) {
+	defer SetLogger(nil)
+	l := &countLogger{}
+	SetLogger(l)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			currentLogger().Printf("line %d", i)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		SetLogger(l)
+	}
+	wg.Wait()
+
+	if l.n != 100 {
+		t.Errorf("logged %d lines, want 100", l.n)
+	}
+	SetLogger(nil)
+	if _, ok := currentLogger().(stdoutLogger); !ok {
+		t.Errorf("SetLogger(nil) did not restore the stdout logger")
+	}
+}